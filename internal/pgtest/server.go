@@ -0,0 +1,227 @@
+package pgtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// connString is the connection string of the pools, whose connections are dialed to the fake server
+	connString = "postgres://test@localhost:5432/test?sslmode=disable&pool_max_conns=4"
+)
+
+type (
+	// Column is a column of a result
+	Column struct {
+		Name        string
+		DataTypeOID uint32
+	}
+
+	// Result is the result of a statement run through the fake server, with the rows in text format
+	Result struct {
+		Columns    []Column
+		Rows       [][]string
+		CommandTag string
+	}
+
+	// HandlerFn is the function type that answers the statements run through the fake server
+	//
+	// The queries are received with their arguments already interpolated, since the pools use the simple
+	// protocol. A *pgconn.PgError is sent with its code, any other error as an internal error.
+	HandlerFn func(query string) (*Result, error)
+
+	// Server is a fake PostgreSQL server that answers the statements through a handler function
+	Server struct {
+		handlerFn   HandlerFn
+		mutex       sync.Mutex
+		statements  []string
+		connections atomic.Int64
+	}
+)
+
+// BoolResult returns a result with a single boolean row
+//
+// Parameters:
+//
+//   - name: the column name
+//   - value: the boolean value
+//
+// Returns:
+//
+//   - *Result: the result
+func BoolResult(name string, value bool) *Result {
+	text := "f"
+	if value {
+		text = "t"
+	}
+	return &Result{
+		Columns: []Column{{Name: name, DataTypeOID: pgtype.BoolOID}},
+		Rows:    [][]string{{text}},
+	}
+}
+
+// NewPool creates a new pool whose connections are served by a fake server
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - handlerFn: the function that answers the statements, nil to answer every statement with an empty result
+//
+// Returns:
+//
+//   - *pgxpool.Pool: the pool
+//   - *Server: the server, to inspect the run statements
+//   - error: if the pool could not be created
+func NewPool(ctx context.Context, handlerFn HandlerFn) (*pgxpool.Pool, *Server, error) {
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Dial the connections to the fake server, running the queries with the simple protocol
+	server := &Server{handlerFn: handlerFn}
+	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	config.ConnConfig.LookupFunc = func(_ context.Context, host string) ([]string, error) {
+		return []string{host}, nil
+	}
+	config.ConnConfig.DialFunc = func(context.Context, string, string) (net.Conn, error) {
+		client, serverConn := net.Pipe()
+		server.connections.Add(1)
+		go server.serve(serverConn)
+		return client, nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pool, server, nil
+}
+
+// Statements returns the statements run through the server
+//
+// Returns:
+//
+//   - []string: the run statements
+func (s *Server) Statements() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.statements...)
+}
+
+// OpenConnections returns the number of connections not closed yet
+//
+// Returns:
+//
+//   - int64: the number of open connections
+func (s *Server) OpenConnections() int64 {
+	return s.connections.Load()
+}
+
+// serve answers the messages of a connection until it is closed
+//
+// Parameters:
+//
+//   - conn: the server side of the connection
+func (s *Server) serve(conn net.Conn) {
+	defer s.connections.Add(-1)
+	defer conn.Close()
+
+	// Accept the connection
+	backend := pgproto3.NewBackend(conn, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+	backend.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 1})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+
+	for {
+		message, err := backend.Receive()
+		if err != nil {
+			return
+		}
+
+		// Answer the simple protocol queries, closing the connection on any other message
+		query, ok := message.(*pgproto3.Query)
+		if !ok {
+			return
+		}
+		s.answer(backend, query.String)
+		if err = backend.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// answer sends the result of a query
+//
+// Parameters:
+//
+//   - backend: the backend of the connection
+//   - query: the query to answer
+func (s *Server) answer(backend *pgproto3.Backend, query string) {
+	s.mutex.Lock()
+	s.statements = append(s.statements, query)
+	s.mutex.Unlock()
+	defer backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+
+	// Run the handler
+	result := &Result{}
+	if s.handlerFn != nil {
+		var err error
+		if result, err = s.handlerFn(query); err != nil {
+			errorResponse := &pgproto3.ErrorResponse{Severity: "ERROR", Code: "XX000", Message: err.Error()}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				errorResponse.Code = pgErr.Code
+				errorResponse.Message = pgErr.Message
+			}
+			backend.Send(errorResponse)
+			return
+		}
+	}
+
+	// Send the rows
+	if len(result.Columns) > 0 {
+		fields := make([]pgproto3.FieldDescription, len(result.Columns))
+		for i, column := range result.Columns {
+			fields[i] = pgproto3.FieldDescription{
+				Name:         []byte(column.Name),
+				DataTypeOID:  column.DataTypeOID,
+				DataTypeSize: -1,
+				TypeModifier: -1,
+			}
+		}
+		backend.Send(&pgproto3.RowDescription{Fields: fields})
+		for _, row := range result.Rows {
+			values := make([][]byte, len(row))
+			for i, value := range row {
+				values[i] = []byte(value)
+			}
+			backend.Send(&pgproto3.DataRow{Values: values})
+		}
+	}
+
+	// Complete the command
+	commandTag := result.CommandTag
+	if commandTag == "" {
+		commandTag = fmt.Sprintf("SELECT %d", len(result.Rows))
+	}
+	backend.Send(&pgproto3.CommandComplete{CommandTag: []byte(commandTag)})
+}
@@ -0,0 +1,343 @@
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// BeginStatement is the statement passed to the handler when a transaction begins
+	BeginStatement = "BEGIN"
+
+	// CommitStatement is the statement passed to the handler when a transaction is committed
+	CommitStatement = "COMMIT"
+
+	// RollbackStatement is the statement passed to the handler when a transaction is rolled back
+	RollbackStatement = "ROLLBACK"
+
+	// PingStatement is the statement passed to the handler when a connection is pinged
+	PingStatement = "PING"
+)
+
+type (
+	// Result is the result of a statement run by the fake driver
+	//
+	// Err is returned by the rows once every row was read.
+	Result struct {
+		Columns      []string
+		Rows         [][]driver.Value
+		RowsAffected int64
+		Err          error
+	}
+
+	// HandlerFn is the function type that answers the statements run through the fake driver
+	//
+	// The transaction statements are passed as BeginStatement, CommitStatement and RollbackStatement, and the
+	// pings as PingStatement.
+	HandlerFn func(ctx context.Context, query string, args []driver.NamedValue) (
+		*Result,
+		error,
+	)
+
+	// Connector is a driver.Connector whose statements are answered by a handler function
+	Connector struct {
+		handlerFn   HandlerFn
+		mutex       sync.Mutex
+		statements  []string
+		connections atomic.Int64
+		opened      atomic.Int64
+	}
+
+	// fakeDriver is the driver returned by the connector
+	fakeDriver struct {
+		connector *Connector
+	}
+
+	// conn is a fake driver connection
+	conn struct {
+		connector *Connector
+	}
+
+	// tx is a fake driver transaction
+	tx struct {
+		conn *conn
+	}
+
+	// stmt is a fake driver prepared statement
+	stmt struct {
+		conn  *conn
+		query string
+	}
+
+	// rows is a fake driver rows
+	rows struct {
+		result *Result
+		index  int
+	}
+
+	// result is a fake driver result
+	result struct {
+		rowsAffected int64
+	}
+)
+
+// NewConnector creates a new fake connector
+//
+// Parameters:
+//
+//   - handlerFn: the function that answers the statements, nil to answer every statement with an empty result
+//
+// Returns:
+//
+//   - *Connector: the connector
+func NewConnector(handlerFn HandlerFn) *Connector {
+	return &Connector{handlerFn: handlerFn}
+}
+
+// Open opens a new database backed by a fake connector
+//
+// Parameters:
+//
+//   - handlerFn: the function that answers the statements
+//
+// Returns:
+//
+//   - *sql.DB: the database
+//   - *Connector: the connector, to inspect the run statements
+func Open(handlerFn HandlerFn) (*sql.DB, *Connector) {
+	connector := NewConnector(handlerFn)
+	return sql.OpenDB(connector), connector
+}
+
+// Connect opens a new fake connection
+func (c *Connector) Connect(context.Context) (driver.Conn, error) {
+	c.connections.Add(1)
+	c.opened.Add(1)
+	return &conn{connector: c}, nil
+}
+
+// Driver returns the fake driver
+func (c *Connector) Driver() driver.Driver {
+	return fakeDriver{connector: c}
+}
+
+// Statements returns the statements run so far, in order
+//
+// Returns:
+//
+//   - []string: the statements
+func (c *Connector) Statements() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]string(nil), c.statements...)
+}
+
+// OpenConnections returns the number of connections currently open
+//
+// Returns:
+//
+//   - int64: the number of open connections
+func (c *Connector) OpenConnections() int64 {
+	return c.connections.Load()
+}
+
+// OpenedConnections returns the number of connections opened so far
+//
+// Returns:
+//
+//   - int64: the number of opened connections
+func (c *Connector) OpenedConnections() int64 {
+	return c.opened.Load()
+}
+
+// handle records a statement and answers it through the handler function
+//
+// Parameters:
+//
+//   - ctx: the context of the statement
+//   - query: the statement
+//   - args: the statement arguments
+//
+// Returns:
+//
+//   - *Result: the statement result
+//   - error: the statement error
+func (c *Connector) handle(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (*Result, error) {
+	c.mutex.Lock()
+	c.statements = append(c.statements, query)
+	c.mutex.Unlock()
+
+	if c.handlerFn == nil {
+		return &Result{}, nil
+	}
+	res, err := c.handlerFn(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		res = &Result{}
+	}
+	return res, nil
+}
+
+// Open opens a new fake connection
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return d.connector.Connect(context.Background())
+}
+
+// Prepare prepares a statement
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+// Close closes the connection
+func (c *conn) Close() error {
+	c.connector.connections.Add(-1)
+	return nil
+}
+
+// Begin begins a transaction
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx begins a transaction
+func (c *conn) BeginTx(ctx context.Context, _ driver.TxOptions) (
+	driver.Tx,
+	error,
+) {
+	if _, err := c.connector.handle(ctx, BeginStatement, nil); err != nil {
+		return nil, err
+	}
+	return &tx{conn: c}, nil
+}
+
+// Ping pings the connection
+func (c *conn) Ping(ctx context.Context) error {
+	_, err := c.connector.handle(ctx, PingStatement, nil)
+	return err
+}
+
+// ExecContext executes a statement
+func (c *conn) ExecContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	res, err := c.connector.handle(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return result{rowsAffected: res.RowsAffected}, nil
+}
+
+// QueryContext runs a query
+func (c *conn) QueryContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	res, err := c.connector.handle(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{result: res}, nil
+}
+
+// Commit commits the transaction
+func (t *tx) Commit() error {
+	_, err := t.conn.connector.handle(context.Background(), CommitStatement, nil)
+	return err
+}
+
+// Rollback rolls back the transaction
+func (t *tx) Rollback() error {
+	_, err := t.conn.connector.handle(
+		context.Background(),
+		RollbackStatement,
+		nil,
+	)
+	return err
+}
+
+// Close closes the statement
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput returns -1, so the arguments are not checked
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+// Exec executes the statement
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+// Query runs the statement
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(
+		context.Background(),
+		s.query,
+		namedValues(args),
+	)
+}
+
+// Columns returns the column names
+func (r *rows) Columns() []string {
+	return r.result.Columns
+}
+
+// Close closes the rows
+func (r *rows) Close() error {
+	return nil
+}
+
+// Next moves to the next row
+func (r *rows) Next(dest []driver.Value) error {
+	if r.index >= len(r.result.Rows) {
+		if r.result.Err != nil {
+			return r.result.Err
+		}
+		return io.EOF
+	}
+	copy(dest, r.result.Rows[r.index])
+	r.index++
+	return nil
+}
+
+// LastInsertId is not supported
+func (r result) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+// RowsAffected returns the number of affected rows
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// namedValues converts the positional values into named values
+//
+// Parameters:
+//
+//   - args: the positional values
+//
+// Returns:
+//
+//   - []driver.NamedValue: the named values
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
@@ -0,0 +1,6 @@
+package structs
+
+const (
+	ErrNotStruct        = "type '%v' is not a struct"
+	ErrDuplicatedColumn = "column '%s' is mapped more than once in '%v'"
+)
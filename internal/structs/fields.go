@@ -0,0 +1,215 @@
+package structs
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+const (
+	// TagName is the struct tag used to map a field to a column
+	TagName = "db"
+
+	// IgnoreTag is the tag value used to ignore a field
+	IgnoreTag = "-"
+)
+
+type (
+	// Mapping is the column to field mapping of a struct type
+	Mapping struct {
+		columns []string
+		indexes map[string][]int
+	}
+)
+
+var (
+	// mappings is the cache of the struct mappings by type
+	mappings sync.Map
+)
+
+// GetMapping returns the column to field mapping of the given struct type
+//
+// Fields are mapped through their 'db' tag, fields tagged with '-' or without tag are ignored,
+// and untagged embedded structs are flattened into the parent mapping, except the pointers to unexported
+// struct types.
+//
+// Parameters:
+//
+//   - structType: the struct type to map
+//
+// Returns:
+//
+//   - *Mapping: the struct mapping
+//   - error: if the type is not a struct or has duplicated columns
+func GetMapping(structType reflect.Type) (*Mapping, error) {
+	// Check if the type is a struct
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf(ErrNotStruct, structType)
+	}
+
+	// Check if the mapping is already cached
+	if cached, ok := mappings.Load(structType); ok {
+		mapping, _ := cached.(*Mapping)
+		return mapping, nil
+	}
+
+	// Build the mapping
+	mapping := &Mapping{indexes: make(map[string][]int)}
+	if err := mapping.addFields(structType, nil); err != nil {
+		return nil, err
+	}
+
+	// Cache the mapping
+	cached, _ := mappings.LoadOrStore(structType, mapping)
+	mapping, _ = cached.(*Mapping)
+	return mapping, nil
+}
+
+// addFields adds the fields of the given struct type to the mapping
+//
+// Parameters:
+//
+//   - structType: the struct type
+//   - parentIndex: the index of the parent field, nil for the root struct
+//
+// Returns:
+//
+//   - error: if there are duplicated columns
+func (m *Mapping) addFields(structType reflect.Type, parentIndex []int) error {
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+
+		// Build the field index
+		index := make([]int, len(parentIndex)+1)
+		copy(index, parentIndex)
+		index[len(parentIndex)] = i
+
+		// Check the field tag
+		tag, hasTag := field.Tag.Lookup(TagName)
+		if tag == IgnoreTag {
+			continue
+		}
+
+		// Flatten the untagged embedded structs, skipping the unexported pointers since they cannot be allocated
+		if field.Anonymous && !hasTag {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				if !field.IsExported() {
+					continue
+				}
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				if err := m.addFields(fieldType, index); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// Skip the untagged and unexported fields
+		if !hasTag || tag == "" || !field.IsExported() {
+			continue
+		}
+
+		// Check if the column is duplicated
+		if _, ok := m.indexes[tag]; ok {
+			return fmt.Errorf(ErrDuplicatedColumn, tag, structType)
+		}
+		m.indexes[tag] = index
+		m.columns = append(m.columns, tag)
+	}
+	return nil
+}
+
+// Columns returns the mapped columns in field order
+//
+// Returns:
+//
+//   - []string: the mapped columns
+func (m *Mapping) Columns() []string {
+	if m == nil {
+		return nil
+	}
+	return m.columns
+}
+
+// FieldByColumn returns the addressable field mapped to the given column
+//
+// Nil embedded struct pointers found along the way are allocated.
+//
+// Parameters:
+//
+//   - structValue: the addressable struct value
+//   - column: the column name
+//
+// Returns:
+//
+//   - reflect.Value: the field value
+//   - bool: true if the column is mapped, false otherwise
+func (m *Mapping) FieldByColumn(
+	structValue reflect.Value,
+	column string,
+) (reflect.Value, bool) {
+	if m == nil {
+		return reflect.Value{}, false
+	}
+
+	// Get the field index
+	index, ok := m.indexes[column]
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	// Walk through the field index
+	value := structValue
+	for i, fieldIndex := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value, true
+}
+
+// ValueByColumn returns the value of the field mapped to the given column without modifying the struct
+//
+// Parameters:
+//
+//   - structValue: the struct value
+//   - column: the column name
+//
+// Returns:
+//
+//   - any: the field value, nil if it is inside a nil embedded struct pointer
+//   - bool: true if the column is mapped, false otherwise
+func (m *Mapping) ValueByColumn(
+	structValue reflect.Value,
+	column string,
+) (any, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	// Get the field index
+	index, ok := m.indexes[column]
+	if !ok {
+		return nil, false
+	}
+
+	// Walk through the field index
+	value := structValue
+	for i, fieldIndex := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return nil, true
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value.Interface(), true
+}
@@ -0,0 +1,187 @@
+package structs
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type (
+	base struct {
+		ID int64 `db:"id"`
+	}
+
+	Audit struct {
+		CreatedBy string `db:"created_by"`
+	}
+
+	hidden struct {
+		Secret string `db:"secret"`
+	}
+
+	user struct {
+		base
+		*Audit
+		*hidden
+		Name     string `db:"name"`
+		Email    string `db:"email"`
+		Ignored  string `db:"-"`
+		Untagged string
+		internal string `db:"internal"`
+	}
+
+	duplicated struct {
+		base
+		OtherID int64 `db:"id"`
+	}
+)
+
+func TestGetMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		columns []string
+		wantErr bool
+	}{
+		{
+			name:    "flattens embedded structs and skips ignored fields",
+			typ:     reflect.TypeOf(user{}),
+			columns: []string{"id", "created_by", "name", "email"},
+		},
+		{
+			name:    "rejects duplicated columns",
+			typ:     reflect.TypeOf(duplicated{}),
+			wantErr: true,
+		},
+		{
+			name:    "rejects non struct types",
+			typ:     reflect.TypeOf(0),
+			wantErr: true,
+		},
+		{
+			name:    "rejects nil types",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mapping, err := GetMapping(tt.typ)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("GetMapping() error = nil, want error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("GetMapping() error = %v", err)
+				}
+				if got := mapping.Columns(); !slices.Equal(got, tt.columns) {
+					t.Errorf("Columns() = %v, want %v", got, tt.columns)
+				}
+			},
+		)
+	}
+}
+
+func TestGetMappingIsCached(t *testing.T) {
+	first, err := GetMapping(reflect.TypeOf(user{}))
+	if err != nil {
+		t.Fatalf("GetMapping() error = %v", err)
+	}
+	second, err := GetMapping(reflect.TypeOf(user{}))
+	if err != nil {
+		t.Fatalf("GetMapping() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("GetMapping() returned a different mapping for the same type")
+	}
+}
+
+func TestMappingFieldByColumn(t *testing.T) {
+	mapping, err := GetMapping(reflect.TypeOf(user{}))
+	if err != nil {
+		t.Fatalf("GetMapping() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		column string
+		value  any
+		found  bool
+	}{
+		{name: "root field", column: "name", value: "alice", found: true},
+		{name: "embedded field", column: "id", value: int64(7), found: true},
+		{
+			name:   "embedded pointer field",
+			column: "created_by",
+			value:  "admin",
+			found:  true,
+		},
+		{name: "ignored field", column: "-"},
+		{name: "unexported field", column: "internal"},
+		{name: "unexported embedded pointer field", column: "secret"},
+		{name: "unknown column", column: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var instance user
+				field, ok := mapping.FieldByColumn(
+					reflect.ValueOf(&instance).Elem(),
+					tt.column,
+				)
+				if ok != tt.found {
+					t.Fatalf("FieldByColumn() found = %v, want %v", ok, tt.found)
+				}
+				if !ok {
+					return
+				}
+
+				// Set the field and read it back through the mapping
+				field.Set(reflect.ValueOf(tt.value))
+				value, ok := mapping.ValueByColumn(
+					reflect.ValueOf(instance),
+					tt.column,
+				)
+				if !ok || value != tt.value {
+					t.Errorf(
+						"ValueByColumn() = %v, %v, want %v, true",
+						value,
+						ok,
+						tt.value,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestMappingValueByColumnNilEmbeddedPointer(t *testing.T) {
+	mapping, err := GetMapping(reflect.TypeOf(user{}))
+	if err != nil {
+		t.Fatalf("GetMapping() error = %v", err)
+	}
+
+	instance := user{}
+	value, ok := mapping.ValueByColumn(reflect.ValueOf(instance), "created_by")
+	if !ok || value != nil {
+		t.Errorf("ValueByColumn() = %v, %v, want nil, true", value, ok)
+	}
+	if instance.Audit != nil {
+		t.Errorf("ValueByColumn() allocated the embedded pointer")
+	}
+}
+
+func TestNilMapping(t *testing.T) {
+	var mapping *Mapping
+	if columns := mapping.Columns(); columns != nil {
+		t.Errorf("Columns() = %v, want nil", columns)
+	}
+	if _, ok := mapping.FieldByColumn(reflect.Value{}, "id"); ok {
+		t.Errorf("FieldByColumn() found a column on a nil mapping")
+	}
+	if _, ok := mapping.ValueByColumn(reflect.Value{}, "id"); ok {
+		t.Errorf("ValueByColumn() found a column on a nil mapping")
+	}
+}
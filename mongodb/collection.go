@@ -5,6 +5,8 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/net/context"
+
+	"github.com/ralvarezdev/go-databases/telemetry"
 )
 
 type (
@@ -43,11 +45,38 @@ func NewCollection(
 func (c Collection) CreateCollection(database *mongo.Database) (
 	collection *mongo.Collection, err error,
 ) {
+	return c.CreateCollectionWithCtx(context.Background(), database)
+}
+
+// CreateCollectionWithCtx creates the collection with a context
+//
+// A span is recorded for the collection creation if the telemetry providers are set.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - database: the MongoDB database
+func (c Collection) CreateCollectionWithCtx(
+	ctx context.Context,
+	database *mongo.Database,
+) (collection *mongo.Collection, err error) {
+	// Start the collection creation span
+	ctx, span := telemetry.StartSpan(
+		ctx,
+		telemetry.SystemMongoDB,
+		telemetry.OperationCreateCollection,
+		"",
+		telemetry.AttributeDBCollection.String(c.name),
+	)
+	defer func() {
+		telemetry.EndSpan(span, err)
+	}()
+
 	// Get the collection
 	collection = database.Collection(c.name)
 
 	// Create the indexes
-	if createErr := c.createIndexes(collection); createErr != nil {
+	if createErr := c.createIndexes(ctx, collection); createErr != nil {
 		return nil, createErr
 	}
 
@@ -58,12 +87,16 @@ func (c Collection) CreateCollection(database *mongo.Database) (
 //
 // Parameters:
 //
+//   - ctx: the context to use
 //   - collection: the MongoDB collection
 //
 // Returns:
 //
 //   - error: if there was an error creating the indexes
-func (c Collection) createIndexes(collection *mongo.Collection) (err error) {
+func (c Collection) createIndexes(
+	ctx context.Context,
+	collection *mongo.Collection,
+) (err error) {
 	if c.Indexes == nil {
 		return nil
 	}
@@ -75,9 +108,7 @@ func (c Collection) createIndexes(collection *mongo.Collection) (err error) {
 		}
 
 		// Create the index
-		_, err = collection.Indexes().CreateOne(
-			context.Background(), *index,
-		)
+		_, err = collection.Indexes().CreateOne(ctx, *index)
 		if err != nil {
 			return fmt.Errorf(ErrFailedToCreateIndex, *index, err)
 		}
@@ -0,0 +1,10 @@
+package outbox
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilStore      = errors.New("outbox store cannot be nil")
+	ErrNilCollection = errors.New("mongodb collection cannot be nil")
+)
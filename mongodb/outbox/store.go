@@ -0,0 +1,303 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ralvarezdev/go-databases/mongodb"
+	gooutbox "github.com/ralvarezdev/go-databases/outbox"
+)
+
+const (
+	// DefaultCollectionName is the default name of the outbox collection
+	DefaultCollectionName = "outbox_events"
+
+	// DefaultLease is the default time an event stays claimed by a relay before other relays can claim it
+	DefaultLease = 30 * time.Second
+)
+
+type (
+	// document is the outbox event stored in the collection
+	document struct {
+		ID            string            `bson:"_id"`
+		Topic         string            `bson:"topic"`
+		Key           string            `bson:"key"`
+		Payload       []byte            `bson:"payload"`
+		Headers       map[string]string `bson:"headers,omitempty"`
+		Status        gooutbox.Status   `bson:"status"`
+		Attempts      int               `bson:"attempts"`
+		NextAttemptAt time.Time         `bson:"next_attempt_at"`
+		LockedUntil   time.Time         `bson:"locked_until"`
+		LastError     string            `bson:"last_error,omitempty"`
+		CreatedAt     time.Time         `bson:"created_at"`
+		DispatchedAt  *time.Time        `bson:"dispatched_at,omitempty"`
+	}
+
+	// Store is a MongoDB outbox collection
+	//
+	// The events are enqueued within the transactions of the application, and claimed one by one by the relays
+	// with a lease, so several relays can poll the same collection without dispatching an event twice while
+	// the lease lasts. The lease must be longer than the time taken to publish an event.
+	Store struct {
+		collection *mongo.Collection
+		lease      time.Duration
+	}
+)
+
+// NewCollection creates the outbox collection definition with the index of the due events
+//
+// Parameters:
+//
+//   - name: the name of the collection, DefaultCollectionName is used if it is empty
+//
+// Returns:
+//
+//   - *mongodb.Collection: the collection definition
+func NewCollection(name string) *mongodb.Collection {
+	// Set the default collection name
+	if name == "" {
+		name = DefaultCollectionName
+	}
+
+	return mongodb.NewCollection(
+		name,
+		[]*mongo.IndexModel{
+			mongodb.NewCompoundFieldIndex(
+				[]*mongodb.FieldIndex{
+					mongodb.NewFieldIndex("status", mongodb.Ascending),
+					mongodb.NewFieldIndex("next_attempt_at", mongodb.Ascending),
+				},
+				false,
+			),
+		},
+	)
+}
+
+// NewStore creates a new outbox store
+//
+// Parameters:
+//
+//   - collection: the outbox collection
+//   - lease: the time an event stays claimed by a relay, DefaultLease is used if it is not positive
+//
+// Returns:
+//
+//   - *Store: the outbox store
+//   - error: if the collection is nil
+func NewStore(collection *mongo.Collection, lease time.Duration) (*Store, error) {
+	// Check if the collection is nil
+	if collection == nil {
+		return nil, ErrNilCollection
+	}
+
+	// Set the default lease
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+
+	return &Store{
+		collection: collection,
+		lease:      lease,
+	}, nil
+}
+
+// Enqueue stores the events, within the transaction if the context is the session context passed to the
+// queries of mongodb.CreateTransaction, so they are only dispatched if it commits
+//
+// Parameters:
+//
+//   - ctx: the context to use, such as a mongo.SessionContext
+//   - events: the events to enqueue
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (s *Store) Enqueue(ctx context.Context, events ...*gooutbox.Event) error {
+	if s == nil {
+		return ErrNilStore
+	}
+
+	// Check if there are events to enqueue
+	if len(events) == 0 {
+		return nil
+	}
+
+	// Build the documents
+	documents := make([]any, len(events))
+	for i, event := range events {
+		if err := gooutbox.Prepare(event); err != nil {
+			return err
+		}
+		documents[i] = document{
+			ID:            event.ID,
+			Topic:         event.Topic,
+			Key:           event.Key,
+			Payload:       event.Payload,
+			Headers:       event.Headers,
+			Status:        gooutbox.StatusPending,
+			NextAttemptAt: event.CreatedAt,
+			CreatedAt:     event.CreatedAt,
+		}
+	}
+
+	// Insert the documents
+	_, err := s.collection.InsertMany(ctx, documents)
+	return err
+}
+
+// claim claims the next due event that is not claimed by other relays
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - *document: the claimed event
+//   - bool: true if an event was claimed, false if there are no due events
+//   - error: if any error occurs
+func (s *Store) claim(ctx context.Context) (*document, bool, error) {
+	now := time.Now().UTC()
+	result := s.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"status":          gooutbox.StatusPending,
+			"next_attempt_at": bson.M{"$lte": now},
+			"locked_until":    bson.M{"$lte": now},
+		},
+		bson.M{"$set": bson.M{"locked_until": now.Add(s.lease)}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	)
+
+	// Decode the claimed event
+	var claimed document
+	if err := result.Decode(&claimed); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return &claimed, true, nil
+}
+
+// Process claims up to limit due events one by one, calls the function for each one and persists its result
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - limit: the maximum number of events to claim
+//   - fn: the function called for each claimed event
+//
+// Returns:
+//
+//   - int: the number of processed events
+//   - error: if any error occurs, or the events whose claim expired wrapping gooutbox.ErrEventClaimExpired
+func (s *Store) Process(
+	ctx context.Context,
+	limit int,
+	fn gooutbox.ProcessFn,
+) (int, error) {
+	if s == nil {
+		return 0, ErrNilStore
+	}
+
+	// Check if the function is nil
+	if fn == nil {
+		return 0, gooutbox.ErrNilProcessFn
+	}
+
+	processed := 0
+	var expiredErrs []error
+	for processed < limit {
+		// Claim the next due event
+		claimed, ok, err := s.claim(ctx)
+		if err != nil {
+			return processed, err
+		}
+		if !ok {
+			break
+		}
+
+		// Process the event and persist its result
+		result := fn(
+			ctx, &gooutbox.Event{
+				ID:        claimed.ID,
+				Topic:     claimed.Topic,
+				Key:       claimed.Key,
+				Payload:   claimed.Payload,
+				Headers:   claimed.Headers,
+				CreatedAt: claimed.CreatedAt,
+				Attempts:  claimed.Attempts,
+			},
+		)
+		if err = s.update(ctx, claimed, result); err != nil {
+			if !errors.Is(err, gooutbox.ErrEventClaimExpired) {
+				return processed, err
+			}
+			expiredErrs = append(expiredErrs, err)
+		}
+		processed++
+	}
+	return processed, errors.Join(expiredErrs...)
+}
+
+// update persists the dispatch result of an event, releasing its claim
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - claimed: the claimed event
+//   - result: the dispatch result
+//
+// Returns:
+//
+//   - error: if any error occurs, wrapping gooutbox.ErrEventClaimExpired if the claim expired
+func (s *Store) update(
+	ctx context.Context,
+	claimed *document,
+	result gooutbox.Result,
+) error {
+	// Build the update
+	now := time.Now().UTC()
+	set := bson.M{
+		"status":       result.Status,
+		"locked_until": now,
+	}
+	update := bson.M{"$set": set}
+	if result.Status == gooutbox.StatusDispatched {
+		set["dispatched_at"] = now
+		update["$unset"] = bson.M{"last_error": ""}
+	} else {
+		update["$inc"] = bson.M{"attempts": 1}
+		if !result.NextAttemptAt.IsZero() {
+			set["next_attempt_at"] = result.NextAttemptAt
+		}
+		if result.Err != nil {
+			set["last_error"] = result.Err.Error()
+		}
+	}
+
+	// Update the event if it is still claimed by this relay
+	updateResult, err := s.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": claimed.ID, "locked_until": claimed.LockedUntil},
+		update,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Check if the claim expired, so the event was possibly claimed by another relay
+	if updateResult.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", gooutbox.ErrEventClaimExpired, claimed.ID)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	gooutbox "github.com/ralvarezdev/go-databases/outbox"
+)
+
+// claimedResponse is the response to the claim of an event
+func claimedResponse(id string, lockedUntil time.Time) bson.D {
+	return bson.D{
+		{Key: "ok", Value: 1},
+		{
+			Key: "value", Value: bson.D{
+				{Key: "_id", Value: id},
+				{Key: "topic", Value: "users"},
+				{Key: "status", Value: gooutbox.StatusPending},
+				{Key: "locked_until", Value: lockedUntil},
+			},
+		},
+	}
+}
+
+// updatedResponse is the response to the update of a claimed event
+func updatedResponse(matched int) bson.D {
+	return mtest.CreateSuccessResponse(
+		bson.E{Key: "n", Value: matched},
+		bson.E{Key: "nModified", Value: matched},
+	)
+}
+
+func TestStoreProcess(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	lockedUntil := time.Now().Add(DefaultLease).UTC()
+	noDocument := bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}}
+	claimErr := mtest.CreateCommandErrorResponse(
+		mtest.CommandError{Code: 11600, Name: "InterruptedAtShutdown", Message: "interrupted at shutdown"},
+	)
+
+	tests := []struct {
+		name          string
+		responses     []bson.D
+		nilFn         bool
+		wantCalls     int
+		wantProcessed int
+		wantErr       error
+		wantCode      int32
+	}{
+		{
+			name:          "dispatches the event",
+			responses:     []bson.D{claimedResponse("1", lockedUntil), updatedResponse(1), noDocument},
+			wantCalls:     1,
+			wantProcessed: 1,
+		},
+		{
+			name:          "reports the expired claim",
+			responses:     []bson.D{claimedResponse("1", lockedUntil), updatedResponse(0), noDocument},
+			wantCalls:     1,
+			wantProcessed: 1,
+			wantErr:       gooutbox.ErrEventClaimExpired,
+		},
+		{
+			name:      "no due events",
+			responses: []bson.D{noDocument},
+		},
+		{
+			name:          "claim error",
+			responses:     []bson.D{claimedResponse("1", lockedUntil), updatedResponse(1), claimErr},
+			wantCalls:     1,
+			wantProcessed: 1,
+			wantCode:      11600,
+		},
+		{
+			name:    "nil function",
+			nilFn:   true,
+			wantErr: gooutbox.ErrNilProcessFn,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(
+			tt.name, func(mt *mtest.T) {
+				store, err := NewStore(mt.Coll, 0)
+				if err != nil {
+					mt.Fatalf("NewStore() error = %v", err)
+				}
+				mt.AddMockResponses(tt.responses...)
+
+				calls := 0
+				var fn gooutbox.ProcessFn = func(context.Context, *gooutbox.Event) gooutbox.Result {
+					calls++
+					return gooutbox.Result{Status: gooutbox.StatusDispatched}
+				}
+				if tt.nilFn {
+					fn = nil
+				}
+				processed, err := store.Process(context.Background(), 10, fn)
+
+				// Check the error, by its code if it was sent by the server
+				var commandErr mongo.CommandError
+				switch {
+				case tt.wantCode != 0:
+					if !errors.As(err, &commandErr) || commandErr.Code != tt.wantCode {
+						mt.Errorf("Process() error = %v, want code %d", err, tt.wantCode)
+					}
+				case !errors.Is(err, tt.wantErr):
+					mt.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+				if processed != tt.wantProcessed {
+					mt.Errorf("Process() processed = %d, want %d", processed, tt.wantProcessed)
+				}
+				if calls != tt.wantCalls {
+					mt.Errorf("function calls = %d, want %d", calls, tt.wantCalls)
+				}
+			},
+		)
+	}
+
+	// Check that a nil store is reported
+	var store *Store
+	if _, err := store.Process(context.Background(), 10, nil); !errors.Is(err, ErrNilStore) {
+		t.Errorf("Process() error = %v, want %v", err, ErrNilStore)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+
+	"github.com/ralvarezdev/go-databases/telemetry"
 )
 
 // CreateTransactionOptions creates the transaction options
@@ -39,6 +41,8 @@ func CreateSession(client *mongo.Client) (mongo.Session, error) {
 
 // CreateTransaction creates a new transaction
 //
+// A span is recorded for the transaction with its outcome if the telemetry providers are set.
+//
 // Parameters:
 //
 // ctx context.Context: the context
@@ -52,7 +56,7 @@ func CreateTransaction(
 	ctx context.Context,
 	client *mongo.Client,
 	queries func(sc mongo.SessionContext) error,
-) error {
+) (err error) {
 	// Create the session
 	clientSession, err := CreateSession(client)
 	if err != nil {
@@ -60,6 +64,27 @@ func CreateTransaction(
 	}
 	defer clientSession.EndSession(ctx)
 
+	// Start the transaction span
+	ctx, span := telemetry.StartSpan(
+		ctx,
+		telemetry.SystemMongoDB,
+		telemetry.OperationTransaction,
+		"",
+	)
+	defer func() {
+		outcome := telemetry.OutcomeCommit
+		if err != nil {
+			outcome = telemetry.OutcomeRollback
+		}
+		telemetry.EndTransactionSpan(
+			ctx,
+			span,
+			telemetry.SystemMongoDB,
+			outcome,
+			err,
+		)
+	}()
+
 	// Create the transaction options
 	transactionOptions := CreateTransactionOptions()
 
@@ -0,0 +1,17 @@
+package outbox
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilEvent            = errors.New("outbox event cannot be nil")
+	ErrEmptyTopic          = errors.New("outbox event topic cannot be empty")
+	ErrNilStore            = errors.New("outbox store cannot be nil")
+	ErrNilPublisher        = errors.New("outbox publisher cannot be nil")
+	ErrNilRelay            = errors.New("outbox relay cannot be nil")
+	ErrNilProcessFn        = errors.New("outbox process function cannot be nil")
+	ErrRelayAlreadyStarted = errors.New("outbox relay already started")
+	ErrInvalidEventPayload = errors.New("invalid outbox event payload")
+	ErrEventClaimExpired   = errors.New("outbox event claim expired before its result was persisted")
+)
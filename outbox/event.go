@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// StatusPending is the status of the events waiting to be dispatched
+	StatusPending Status = "pending"
+
+	// StatusDispatched is the status of the events handed to the publisher
+	StatusDispatched Status = "dispatched"
+
+	// StatusFailed is the status of the events whose dispatch attempts were exhausted
+	StatusFailed Status = "failed"
+)
+
+type (
+	// Status represents the dispatch status of an event
+	Status string
+
+	// Event is an event stored in the outbox
+	//
+	// ID identifies the event, so the consumers can discard the duplicates delivered when the relay crashes
+	// after publishing an event but before marking it as dispatched. Attempts is the number of failed dispatch
+	// attempts.
+	Event struct {
+		ID        string
+		Topic     string
+		Key       string
+		Payload   []byte
+		Headers   map[string]string
+		CreatedAt time.Time
+		Attempts  int
+	}
+)
+
+// NewEventID creates a new random event ID
+//
+// Returns:
+//
+//   - string: the event ID
+//   - error: if the random bytes could not be read
+func NewEventID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// NewEvent creates a new event with a random ID, encoding the payload as JSON
+//
+// Parameters:
+//
+//   - topic: the topic the event is published to
+//   - key: the key of the event, such as the ID of the aggregate, it can be empty
+//   - payload: the payload, encoded as JSON
+//
+// Returns:
+//
+//   - *Event: the event
+//   - error: if the topic is empty or the payload could not be encoded
+func NewEvent(topic, key string, payload any) (*Event, error) {
+	// Check if the topic is empty
+	if topic == "" {
+		return nil, ErrEmptyTopic
+	}
+
+	// Encode the payload
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEventPayload, err)
+	}
+
+	// Create the event ID
+	id, err := NewEventID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		ID:        id,
+		Topic:     topic,
+		Key:       key,
+		Payload:   encodedPayload,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Prepare validates the event before it is stored, setting its ID and creation time if they are empty
+//
+// Parameters:
+//
+//   - event: the event to prepare
+//
+// Returns:
+//
+//   - error: if the event is nil, its topic is empty or the ID could not be created
+func Prepare(event *Event) error {
+	// Check if the event is nil or its topic is empty
+	if event == nil {
+		return ErrNilEvent
+	}
+	if event.Topic == "" {
+		return ErrEmptyTopic
+	}
+
+	// Set the ID and the creation time
+	if event.ID == "" {
+		id, err := NewEventID()
+		if err != nil {
+			return err
+		}
+		event.ID = id
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// DecodePayload decodes the JSON payload of an event
+//
+// Parameters:
+//
+//   - event: the event
+//
+// Returns:
+//
+//   - *T: the decoded payload
+//   - error: if the event is nil or its payload could not be decoded
+func DecodePayload[T any](event *Event) (*T, error) {
+	// Check if the event is nil
+	if event == nil {
+		return nil, ErrNilEvent
+	}
+
+	// Decode the payload
+	payload := new(T)
+	if err := json.Unmarshal(event.Payload, payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEventPayload, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPrepare(t *testing.T) {
+	createdAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		event         *Event
+		wantErr       error
+		wantID        string
+		wantCreatedAt time.Time
+	}{
+		{
+			name:    "nil event",
+			wantErr: ErrNilEvent,
+		},
+		{
+			name:    "empty topic",
+			event:   &Event{ID: "1"},
+			wantErr: ErrEmptyTopic,
+		},
+		{
+			name:          "keeps the ID and creation time",
+			event:         &Event{ID: "1", Topic: "users", CreatedAt: createdAt},
+			wantID:        "1",
+			wantCreatedAt: createdAt,
+		},
+		{
+			name:  "sets the ID and creation time",
+			event: &Event{Topic: "users"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := Prepare(tt.event)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Prepare() error = %v, want %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+
+				// Check the ID and the creation time
+				if tt.wantID != "" && tt.event.ID != tt.wantID {
+					t.Errorf("ID = %q, want %q", tt.event.ID, tt.wantID)
+				}
+				if tt.event.ID == "" {
+					t.Errorf("ID is empty")
+				}
+				if !tt.wantCreatedAt.IsZero() && !tt.event.CreatedAt.Equal(tt.wantCreatedAt) {
+					t.Errorf("CreatedAt = %v, want %v", tt.event.CreatedAt, tt.wantCreatedAt)
+				}
+				if tt.event.CreatedAt.IsZero() {
+					t.Errorf("CreatedAt is zero")
+				}
+			},
+		)
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	type userCreated struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		event   *Event
+		want    *userCreated
+		wantErr error
+	}{
+		{
+			name:  "valid payload",
+			event: &Event{Payload: []byte(`{"id": 1, "name": "john"}`)},
+			want:  &userCreated{ID: 1, Name: "john"},
+		},
+		{
+			name:    "nil event",
+			wantErr: ErrNilEvent,
+		},
+		{
+			name:    "invalid payload",
+			event:   &Event{Payload: []byte(`{"id": "one"}`)},
+			wantErr: ErrInvalidEventPayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := DecodePayload[userCreated](tt.event)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DecodePayload() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.want != nil && *got != *tt.want {
+					t.Errorf("DecodePayload() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		topic   string
+		payload any
+		wantErr error
+	}{
+		{name: "valid event", topic: "users", payload: map[string]int{"id": 1}},
+		{name: "empty topic", payload: map[string]int{"id": 1}, wantErr: ErrEmptyTopic},
+		{
+			name:    "payload that cannot be encoded",
+			topic:   "users",
+			payload: make(chan int),
+			wantErr: ErrInvalidEventPayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				event, err := NewEvent(tt.topic, "key", tt.payload)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewEvent() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && (event.ID == "" || event.Topic != tt.topic) {
+					t.Errorf("NewEvent() = %+v, want an event with an ID and topic %q", event, tt.topic)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Publisher is the interface for the publishers the relay hands the events to, such as a message broker
+	// producer
+	Publisher interface {
+		Publish(ctx context.Context, event *Event) error
+	}
+
+	// PublisherFn is the function type adapter for the publishers
+	PublisherFn func(ctx context.Context, event *Event) error
+
+	// Result is the outcome of the dispatch of an event, persisted by the store
+	//
+	// NextAttemptAt is only set for the pending events, and Err is the error returned by the publisher.
+	Result struct {
+		Status        Status
+		NextAttemptAt time.Time
+		Err           error
+	}
+
+	// ProcessFn is the function type called by the stores for each claimed event
+	ProcessFn func(ctx context.Context, event *Event) Result
+
+	// Store is the interface for the outbox storages polled by the relay
+	//
+	// Process claims up to limit pending events whose next attempt is due, so that other relays do not claim
+	// them at the same time, calls the function for each one and persists its result. The events whose claim
+	// expired before their result was persisted, and were possibly claimed by another relay, are reported in
+	// the returned error, wrapping ErrEventClaimExpired, without stopping the processing of the other events.
+	Store interface {
+		Process(ctx context.Context, limit int, fn ProcessFn) (int, error)
+	}
+)
+
+// Publish publishes the event by calling the function
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - event: the event to publish
+//
+// Returns:
+//
+//   - error: the error returned by the function
+func (p PublisherFn) Publish(ctx context.Context, event *Event) error {
+	return p(ctx, event)
+}
@@ -0,0 +1,224 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+const (
+	// DefaultPollInterval is the default interval between the polls of the store when it has no due events
+	DefaultPollInterval = time.Second
+
+	// DefaultBatchSize is the default number of events claimed by each poll
+	DefaultBatchSize = 100
+)
+
+type (
+	// RelayErrorFn is the function type called when a poll of the store fails, or when the results of some
+	// events could not be persisted since their claim expired
+	RelayErrorFn func(err error)
+
+	// Relay polls an outbox store and hands the due events to a publisher
+	//
+	// The events whose publication fails are retried waiting the backoff of the retry policy, and marked as
+	// failed once its attempts are exhausted. The events are delivered at least once, so the consumers must be
+	// idempotent.
+	Relay struct {
+		store        Store
+		publisher    Publisher
+		pollInterval time.Duration
+		batchSize    int
+		retryPolicy  *gosql.RetryPolicy
+		onError      RelayErrorFn
+		cancel       context.CancelFunc
+		done         chan struct{}
+		mutex        sync.Mutex
+	}
+)
+
+// NewRelay creates a new outbox relay
+//
+// Parameters:
+//
+//   - store: the outbox store to poll
+//   - publisher: the publisher to hand the events to
+//   - pollInterval: the interval between polls when the store has no due events, DefaultPollInterval is used if
+//     it is not positive
+//   - batchSize: the number of events claimed by each poll, DefaultBatchSize is used if it is not positive
+//   - retryPolicy: the policy of the failed publications, the default retry policy is used if nil. Every
+//     publication error is retried, so its IsRetryable function is ignored
+//   - onError: the function called when a poll fails, it can be nil
+//
+// Returns:
+//
+//   - *Relay: the outbox relay
+//   - error: if the store or the publisher is nil
+func NewRelay(
+	store Store,
+	publisher Publisher,
+	pollInterval time.Duration,
+	batchSize int,
+	retryPolicy *gosql.RetryPolicy,
+	onError RelayErrorFn,
+) (*Relay, error) {
+	// Check if the store or the publisher is nil
+	if store == nil {
+		return nil, ErrNilStore
+	}
+	if publisher == nil {
+		return nil, ErrNilPublisher
+	}
+
+	// Set the defaults
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if retryPolicy == nil {
+		retryPolicy = gosql.NewDefaultRetryPolicy()
+	}
+
+	return &Relay{
+		store:        store,
+		publisher:    publisher,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		retryPolicy:  retryPolicy,
+		onError:      onError,
+	}, nil
+}
+
+// publish publishes an event, returning the result to persist
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - event: the event to publish
+//
+// Returns:
+//
+//   - Result: the dispatch result
+func (r *Relay) publish(ctx context.Context, event *Event) Result {
+	// Publish the event
+	err := r.publisher.Publish(ctx, event)
+	if err == nil {
+		return Result{Status: StatusDispatched}
+	}
+
+	// Check if the attempts were exhausted
+	attempts := event.Attempts + 1
+	if r.retryPolicy.MaxAttempts > 0 && attempts >= r.retryPolicy.MaxAttempts {
+		return Result{Status: StatusFailed, Err: err}
+	}
+
+	// Schedule the next attempt
+	return Result{
+		Status:        StatusPending,
+		NextAttemptAt: time.Now().UTC().Add(r.retryPolicy.Backoff(attempts)),
+		Err:           err,
+	}
+}
+
+// RunOnce polls the store once, publishing the due events
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - int: the number of processed events
+//   - error: if the store could not be polled
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	if r == nil {
+		return 0, ErrNilRelay
+	}
+	return r.store.Process(ctx, r.batchSize, r.publish)
+}
+
+// Start starts polling the store in the background, polling again right away while full batches are claimed
+//
+// Parameters:
+//
+//   - ctx: the context of the relay, which stops it when done
+//
+// Returns:
+//
+//   - error: if the relay is nil or already started
+func (r *Relay) Start(ctx context.Context) error {
+	if r == nil {
+		return ErrNilRelay
+	}
+
+	// Lock the mutex to ensure thread safety
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Check if the relay is already started
+	if r.cancel != nil {
+		return ErrRelayAlreadyStarted
+	}
+
+	// Create the relay context
+	relayCtx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	// Run the relay
+	go func() {
+		defer close(r.done)
+
+		for {
+			// Poll the store
+			processed, err := r.RunOnce(relayCtx)
+			if relayCtx.Err() != nil {
+				return
+			}
+			if err != nil && r.onError != nil {
+				r.onError(err)
+			}
+
+			// Poll again right away if a full batch was claimed
+			if err == nil && processed >= r.batchSize {
+				continue
+			}
+
+			// Wait for the next poll
+			timer := time.NewTimer(r.pollInterval)
+			select {
+			case <-relayCtx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the relay, waiting for the current poll to finish
+func (r *Relay) Stop() {
+	if r == nil {
+		return
+	}
+
+	// Lock the mutex to ensure thread safety
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Check if the relay is started
+	if r.cancel == nil {
+		return
+	}
+
+	// Stop the relay
+	r.cancel()
+	<-r.done
+	r.cancel = nil
+	r.done = nil
+}
@@ -0,0 +1,310 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+)
+
+const (
+	// TenantSeparator is the separator between the name and the tenant in the errors of the tenant connections
+	TenantSeparator = "/"
+)
+
+type (
+	// OpenFn is the function type used to lazily open a connection
+	OpenFn[T any] func(ctx context.Context) (T, error)
+
+	// TenantFactoryFn is the function type used to lazily open the connection of a tenant
+	TenantFactoryFn[T any] func(ctx context.Context, tenant string) (T, error)
+
+	// CloseFn is the function type used to close a connection
+	CloseFn[T any] func(ctx context.Context, connection T) error
+
+	// entry is a lazily opened connection
+	entry[T any] struct {
+		open       OpenFn[T]
+		connection T
+		opened     bool
+		mutex      sync.Mutex
+	}
+
+	// connections holds the named connections of a kind
+	//
+	// The tenant connections are kept apart from the named connections, by name and then by tenant, so a
+	// name can never be mistaken for a tenant connection.
+	connections[T any] struct {
+		entries         map[string]*entry[T]
+		tenants         map[string]map[string]*entry[T]
+		tenantFactories map[string]TenantFactoryFn[T]
+		closeFn         CloseFn[T]
+		mutex           sync.Mutex
+	}
+)
+
+// newConnections creates a new set of named connections
+//
+// Parameters:
+//
+//   - closeFn: the function used to close the connections
+//
+// Returns:
+//
+//   - *connections[T]: the named connections
+func newConnections[T any](closeFn CloseFn[T]) *connections[T] {
+	return &connections[T]{
+		entries:         make(map[string]*entry[T]),
+		tenants:         make(map[string]map[string]*entry[T]),
+		tenantFactories: make(map[string]TenantFactoryFn[T]),
+		closeFn:         closeFn,
+	}
+}
+
+// register registers a named connection
+//
+// Parameters:
+//
+//   - name: the connection name
+//   - open: the function used to lazily open the connection
+//
+// Returns:
+//
+//   - error: if the name is empty or already registered
+func (c *connections[T]) register(name string, open OpenFn[T]) error {
+	// Check if the name is empty
+	if name == "" {
+		return ErrEmptyName
+	}
+
+	// Lock the mutex to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Check if the name is already registered
+	if _, ok := c.entries[name]; ok {
+		return fmt.Errorf(ErrAlreadyRegistered, name)
+	}
+	if _, ok := c.tenantFactories[name]; ok {
+		return fmt.Errorf(ErrAlreadyRegistered, name)
+	}
+
+	c.entries[name] = &entry[T]{open: open}
+	return nil
+}
+
+// registerTenant registers a named tenant factory
+//
+// Parameters:
+//
+//   - name: the connection name
+//   - factory: the function used to lazily open the connection of each tenant
+//
+// Returns:
+//
+//   - error: if the name is empty or already registered
+func (c *connections[T]) registerTenant(
+	name string,
+	factory TenantFactoryFn[T],
+) error {
+	// Check if the name is empty or the factory is nil
+	if name == "" {
+		return ErrEmptyName
+	}
+	if factory == nil {
+		return ErrNilTenantFactory
+	}
+
+	// Lock the mutex to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Check if the name is already registered
+	if _, ok := c.entries[name]; ok {
+		return fmt.Errorf(ErrAlreadyRegistered, name)
+	}
+	if _, ok := c.tenantFactories[name]; ok {
+		return fmt.Errorf(ErrAlreadyRegistered, name)
+	}
+
+	c.tenantFactories[name] = factory
+	return nil
+}
+
+// get gets a named connection, opening it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the connection name
+//
+// Returns:
+//
+//   - T: the connection
+//   - error: if the name is not registered or the connection could not be opened
+func (c *connections[T]) get(ctx context.Context, name string) (T, error) {
+	// Get the entry
+	c.mutex.Lock()
+	e, ok := c.entries[name]
+	c.mutex.Unlock()
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf(ErrNotRegistered, name)
+	}
+
+	return e.get(ctx)
+}
+
+// getTenant gets the connection of a tenant, opening it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the connection name
+//   - tenant: the tenant key
+//
+// Returns:
+//
+//   - T: the connection
+//   - error: if the name is not registered or the connection could not be opened
+func (c *connections[T]) getTenant(
+	ctx context.Context,
+	name,
+	tenant string,
+) (T, error) {
+	// Get or create the tenant entry
+	c.mutex.Lock()
+	e, ok := c.tenants[name][tenant]
+	if !ok {
+		factory, hasFactory := c.tenantFactories[name]
+		if !hasFactory {
+			c.mutex.Unlock()
+			var zero T
+			return zero, fmt.Errorf(ErrNotRegistered, name)
+		}
+
+		e = &entry[T]{
+			open: func(ctx context.Context) (T, error) {
+				return factory(ctx, tenant)
+			},
+		}
+		if c.tenants[name] == nil {
+			c.tenants[name] = make(map[string]*entry[T])
+		}
+		c.tenants[name][tenant] = e
+	}
+	c.mutex.Unlock()
+
+	return e.get(ctx)
+}
+
+// get gets the connection, opening it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - T: the connection
+//   - error: if the connection could not be opened
+func (e *entry[T]) get(ctx context.Context) (T, error) {
+	// Lock the mutex to ensure thread safety
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// Check if the connection is already opened
+	if e.opened {
+		return e.connection, nil
+	}
+
+	// Open the connection
+	connection, err := e.open(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	e.connection = connection
+	e.opened = true
+	return connection, nil
+}
+
+// closeAll closes every opened connection, forgetting the tenant connections
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - error: the joined errors of the connections that could not be closed
+func (c *connections[T]) closeAll(ctx context.Context) error {
+	// Lock the mutex to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Close the named connections
+	var errs []error
+	for name, e := range c.entries {
+		if err := e.close(ctx, c.closeFn); err != nil {
+			errs = append(
+				errs,
+				fmt.Errorf(
+					ErrFailedToDisconnectFrom,
+					godatabases.ErrFailedToDisconnect,
+					name,
+					err,
+				),
+			)
+		}
+	}
+
+	// Close and forget the tenant connections
+	for name, tenants := range c.tenants {
+		for tenant, e := range tenants {
+			if err := e.close(ctx, c.closeFn); err != nil {
+				errs = append(
+					errs,
+					fmt.Errorf(
+						ErrFailedToDisconnectFrom,
+						godatabases.ErrFailedToDisconnect,
+						name+TenantSeparator+tenant,
+						err,
+					),
+				)
+			}
+		}
+		delete(c.tenants, name)
+	}
+	return errors.Join(errs...)
+}
+
+// close closes the connection if it is opened, so it is opened again on next use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - closeFn: the function used to close the connection
+//
+// Returns:
+//
+//   - error: if the connection could not be closed
+func (e *entry[T]) close(ctx context.Context, closeFn CloseFn[T]) error {
+	// Lock the mutex to ensure thread safety
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// Check if the connection is opened
+	if !e.opened {
+		return nil
+	}
+
+	// Close the connection, resetting it even if it fails
+	err := closeFn(ctx, e.connection)
+	var zero T
+	e.connection = zero
+	e.opened = false
+	return err
+}
@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// openString returns an open function returning the given connection
+func openString(connection string) OpenFn[string] {
+	return func(context.Context) (string, error) {
+		return connection, nil
+	}
+}
+
+func TestConnectionsTenantsAreKeptApart(t *testing.T) {
+	c := newConnections[string](
+		func(context.Context, string) error {
+			return nil
+		},
+	)
+
+	// Register a named connection whose name looks like a tenant key, and a tenant factory
+	if err := c.register("main/acme", openString("named")); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+	if err := c.registerTenant(
+		"main",
+		func(_ context.Context, tenant string) (string, error) {
+			return "tenant:" + tenant, nil
+		},
+	); err != nil {
+		t.Fatalf("registerTenant() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		getFn   func() (string, error)
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "tenant connection",
+			getFn: func() (string, error) {
+				return c.getTenant(context.Background(), "main", "acme")
+			},
+			want: "tenant:acme",
+		},
+		{
+			name: "named connection",
+			getFn: func() (string, error) {
+				return c.get(context.Background(), "main/acme")
+			},
+			want: "named",
+		},
+		{
+			name: "tenant factory is not a named connection",
+			getFn: func() (string, error) {
+				return c.get(context.Background(), "main")
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown tenant factory",
+			getFn: func() (string, error) {
+				return c.getTenant(context.Background(), "other", "acme")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := tt.getFn()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("connection = %q, want %q", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestConnectionsCloseAll(t *testing.T) {
+	errClose := errors.New("close failed")
+	var closed []string
+	c := newConnections[string](
+		func(_ context.Context, connection string) error {
+			closed = append(closed, connection)
+			if connection == "broken" {
+				return errClose
+			}
+			return nil
+		},
+	)
+	if err := c.register("broken", openString("broken")); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+	if err := c.register("unused", openString("unused")); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+	if err := c.registerTenant(
+		"main",
+		func(_ context.Context, tenant string) (string, error) {
+			return tenant, nil
+		},
+	); err != nil {
+		t.Fatalf("registerTenant() error = %v", err)
+	}
+	if _, err := c.get(context.Background(), "broken"); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if _, err := c.getTenant(context.Background(), "main", "acme"); err != nil {
+		t.Fatalf("getTenant() error = %v", err)
+	}
+
+	// Close every opened connection
+	if err := c.closeAll(context.Background()); !errors.Is(err, errClose) {
+		t.Errorf("closeAll() error = %v, want %v", err, errClose)
+	}
+	if len(closed) != 2 {
+		t.Errorf("closeAll() closed %v, want the opened connections only", closed)
+	}
+	if len(c.tenants) != 0 {
+		t.Errorf("closeAll() kept %d tenant connections", len(c.tenants))
+	}
+
+	// Check that the named connection is opened again on next use
+	if _, err := c.get(context.Background(), "broken"); err != nil {
+		t.Errorf("get() after closeAll() error = %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package registry
+
+import (
+	"errors"
+)
+
+const (
+	ErrAlreadyRegistered      = "'%s' is already registered"
+	ErrNotRegistered          = "'%s' is not registered"
+	ErrFailedToDisconnectFrom = "%w '%s': %w"
+)
+
+var (
+	ErrNilRegistry      = errors.New("registry cannot be nil")
+	ErrEmptyName        = errors.New("name cannot be empty")
+	ErrNilTenantFactory = errors.New("tenant factory cannot be nil")
+	ErrNilMongoOptions  = errors.New("mongodb client options cannot be nil")
+	ErrNilPgxPoolConfig = errors.New("pgxpool config cannot be nil")
+)
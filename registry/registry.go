@@ -0,0 +1,568 @@
+package registry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+type (
+	// TenantSQLHandlerFn is the function type used to create the SQL handler of a tenant
+	TenantSQLHandlerFn func(ctx context.Context, tenant string) (gosql.Handler, error)
+
+	// TenantMongoOptionsFn is the function type used to create the MongoDB client options of a tenant
+	TenantMongoOptionsFn func(ctx context.Context, tenant string) (*options.ClientOptions, error)
+
+	// TenantPgxPoolConfigFn is the function type used to create the pgxpool config of a tenant
+	TenantPgxPoolConfigFn func(ctx context.Context, tenant string) (*pgxpool.Config, error)
+
+	// Registry holds the named SQL handlers, MongoDB clients and pgx pools, connecting them on first use
+	Registry struct {
+		sqlHandlers  *connections[gosql.Handler]
+		mongoClients *connections[*mongo.Client]
+		pgxPools     *connections[*pgxpool.Pool]
+	}
+)
+
+// NewRegistry creates a new registry
+//
+// Returns:
+//
+//   - *Registry: the registry
+func NewRegistry() *Registry {
+	return &Registry{
+		sqlHandlers: newConnections(
+			func(_ context.Context, handler gosql.Handler) error {
+				return handler.Disconnect()
+			},
+		),
+		mongoClients: newConnections(
+			func(ctx context.Context, client *mongo.Client) error {
+				return client.Disconnect(ctx)
+			},
+		),
+		pgxPools: newConnections(
+			func(_ context.Context, pool *pgxpool.Pool) error {
+				pool.Close()
+				return nil
+			},
+		),
+	}
+}
+
+// connectSQLHandler connects the SQL handler
+//
+// Parameters:
+//
+//   - handler: the SQL handler
+//
+// Returns:
+//
+//   - gosql.Handler: the connected SQL handler
+//   - error: if any error occurs
+func connectSQLHandler(handler gosql.Handler) (gosql.Handler, error) {
+	// Check if the handler is nil
+	if handler == nil {
+		return nil, godatabases.ErrNilHandler
+	}
+
+	// Connect the handler
+	if _, err := handler.Connect(); err != nil {
+		return nil, err
+	}
+	return handler, nil
+}
+
+// connectMongoClient connects a MongoDB client and pings it
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - opts: the MongoDB client options
+//
+// Returns:
+//
+//   - *mongo.Client: the connected MongoDB client
+//   - error: if any error occurs
+func connectMongoClient(
+	ctx context.Context,
+	opts *options.ClientOptions,
+) (*mongo.Client, error) {
+	// Check if the options are nil
+	if opts == nil {
+		return nil, ErrNilMongoOptions
+	}
+
+	// Connect the client
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrConnectionFailed, err)
+	}
+
+	// Ping the client
+	if err = client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrPingFailed, err)
+	}
+	return client, nil
+}
+
+// connectPgxPool creates a pgx pool and pings it
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - config: the pgxpool config
+//
+// Returns:
+//
+//   - *pgxpool.Pool: the connected pgx pool
+//   - error: if any error occurs
+func connectPgxPool(
+	ctx context.Context,
+	config *pgxpool.Config,
+) (*pgxpool.Pool, error) {
+	// Check if the config is nil
+	if config == nil {
+		return nil, ErrNilPgxPoolConfig
+	}
+
+	// Create the pool
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrConnectionFailed, err)
+	}
+
+	// Ping the pool
+	if err = pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrPingFailed, err)
+	}
+	return pool, nil
+}
+
+// RegisterSQLHandler registers a named SQL handler, which is connected on first use
+//
+// Parameters:
+//
+//   - name: the handler name
+//   - handler: the SQL handler
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (r *Registry) RegisterSQLHandler(name string, handler gosql.Handler) error {
+	if r == nil {
+		return ErrNilRegistry
+	}
+
+	// Check if the handler is nil
+	if handler == nil {
+		return godatabases.ErrNilHandler
+	}
+
+	return r.sqlHandlers.register(
+		name,
+		func(context.Context) (gosql.Handler, error) {
+			return connectSQLHandler(handler)
+		},
+	)
+}
+
+// RegisterSQLConfig registers a named SQL handler created from the given configuration, which is connected on
+// first use
+//
+// Parameters:
+//
+//   - name: the handler name
+//   - config: the configuration for the connection
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (r *Registry) RegisterSQLConfig(name string, config *gosql.Config) error {
+	if r == nil {
+		return ErrNilRegistry
+	}
+
+	// Create the handler
+	handler, err := gosql.NewDefaultHandler(config)
+	if err != nil {
+		return err
+	}
+	return r.RegisterSQLHandler(name, handler)
+}
+
+// RegisterTenantSQLHandler registers a named factory of per-tenant SQL handlers, which are created and
+// connected on first use of each tenant
+//
+// Parameters:
+//
+//   - name: the handler name
+//   - factory: the function used to create the SQL handler of each tenant
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (r *Registry) RegisterTenantSQLHandler(
+	name string,
+	factory TenantSQLHandlerFn,
+) error {
+	if r == nil {
+		return ErrNilRegistry
+	}
+
+	// Check if the factory is nil
+	if factory == nil {
+		return ErrNilTenantFactory
+	}
+
+	return r.sqlHandlers.registerTenant(
+		name,
+		func(ctx context.Context, tenant string) (gosql.Handler, error) {
+			handler, err := factory(ctx, tenant)
+			if err != nil {
+				return nil, err
+			}
+			return connectSQLHandler(handler)
+		},
+	)
+}
+
+// SQLHandler gets a named SQL handler, connecting it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the handler name
+//
+// Returns:
+//
+//   - gosql.Handler: the connected SQL handler
+//   - error: if any error occurs
+func (r *Registry) SQLHandler(ctx context.Context, name string) (
+	gosql.Handler,
+	error,
+) {
+	if r == nil {
+		return nil, ErrNilRegistry
+	}
+	return r.sqlHandlers.get(ctx, name)
+}
+
+// SQL gets the SQL connection of a named SQL handler, connecting it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the handler name
+//
+// Returns:
+//
+//   - *sql.DB: the SQL connection
+//   - error: if any error occurs
+func (r *Registry) SQL(ctx context.Context, name string) (*sql.DB, error) {
+	handler, err := r.SQLHandler(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return handler.DB()
+}
+
+// TenantSQLHandler gets the SQL handler of a tenant, creating and connecting it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the handler name
+//   - tenant: the tenant key
+//
+// Returns:
+//
+//   - gosql.Handler: the connected SQL handler
+//   - error: if any error occurs
+func (r *Registry) TenantSQLHandler(
+	ctx context.Context,
+	name,
+	tenant string,
+) (gosql.Handler, error) {
+	if r == nil {
+		return nil, ErrNilRegistry
+	}
+	return r.sqlHandlers.getTenant(ctx, name, tenant)
+}
+
+// TenantSQL gets the SQL connection of a tenant, creating and connecting its handler on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the handler name
+//   - tenant: the tenant key
+//
+// Returns:
+//
+//   - *sql.DB: the SQL connection
+//   - error: if any error occurs
+func (r *Registry) TenantSQL(
+	ctx context.Context,
+	name,
+	tenant string,
+) (*sql.DB, error) {
+	handler, err := r.TenantSQLHandler(ctx, name, tenant)
+	if err != nil {
+		return nil, err
+	}
+	return handler.DB()
+}
+
+// RegisterMongoClient registers a named MongoDB client, which is connected on first use
+//
+// Parameters:
+//
+//   - name: the client name
+//   - opts: the MongoDB client options
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (r *Registry) RegisterMongoClient(
+	name string,
+	opts *options.ClientOptions,
+) error {
+	if r == nil {
+		return ErrNilRegistry
+	}
+
+	// Check if the options are nil
+	if opts == nil {
+		return ErrNilMongoOptions
+	}
+
+	return r.mongoClients.register(
+		name,
+		func(ctx context.Context) (*mongo.Client, error) {
+			return connectMongoClient(ctx, opts)
+		},
+	)
+}
+
+// RegisterTenantMongoClient registers a named factory of per-tenant MongoDB clients, which are created and
+// connected on first use of each tenant
+//
+// Parameters:
+//
+//   - name: the client name
+//   - factory: the function used to create the MongoDB client options of each tenant
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (r *Registry) RegisterTenantMongoClient(
+	name string,
+	factory TenantMongoOptionsFn,
+) error {
+	if r == nil {
+		return ErrNilRegistry
+	}
+
+	// Check if the factory is nil
+	if factory == nil {
+		return ErrNilTenantFactory
+	}
+
+	return r.mongoClients.registerTenant(
+		name,
+		func(ctx context.Context, tenant string) (*mongo.Client, error) {
+			opts, err := factory(ctx, tenant)
+			if err != nil {
+				return nil, err
+			}
+			return connectMongoClient(ctx, opts)
+		},
+	)
+}
+
+// Mongo gets a named MongoDB client, connecting it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the client name
+//
+// Returns:
+//
+//   - *mongo.Client: the connected MongoDB client
+//   - error: if any error occurs
+func (r *Registry) Mongo(ctx context.Context, name string) (
+	*mongo.Client,
+	error,
+) {
+	if r == nil {
+		return nil, ErrNilRegistry
+	}
+	return r.mongoClients.get(ctx, name)
+}
+
+// TenantMongo gets the MongoDB client of a tenant, creating and connecting it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the client name
+//   - tenant: the tenant key
+//
+// Returns:
+//
+//   - *mongo.Client: the connected MongoDB client
+//   - error: if any error occurs
+func (r *Registry) TenantMongo(
+	ctx context.Context,
+	name,
+	tenant string,
+) (*mongo.Client, error) {
+	if r == nil {
+		return nil, ErrNilRegistry
+	}
+	return r.mongoClients.getTenant(ctx, name, tenant)
+}
+
+// RegisterPgxPool registers a named pgx pool, which is created on first use
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - config: the pgxpool config
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (r *Registry) RegisterPgxPool(name string, config *pgxpool.Config) error {
+	if r == nil {
+		return ErrNilRegistry
+	}
+
+	// Check if the config is nil
+	if config == nil {
+		return ErrNilPgxPoolConfig
+	}
+
+	return r.pgxPools.register(
+		name,
+		func(ctx context.Context) (*pgxpool.Pool, error) {
+			return connectPgxPool(ctx, config)
+		},
+	)
+}
+
+// RegisterTenantPgxPool registers a named factory of per-tenant pgx pools, which are created on first use of
+// each tenant
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - factory: the function used to create the pgxpool config of each tenant
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (r *Registry) RegisterTenantPgxPool(
+	name string,
+	factory TenantPgxPoolConfigFn,
+) error {
+	if r == nil {
+		return ErrNilRegistry
+	}
+
+	// Check if the factory is nil
+	if factory == nil {
+		return ErrNilTenantFactory
+	}
+
+	return r.pgxPools.registerTenant(
+		name,
+		func(ctx context.Context, tenant string) (*pgxpool.Pool, error) {
+			config, err := factory(ctx, tenant)
+			if err != nil {
+				return nil, err
+			}
+			return connectPgxPool(ctx, config)
+		},
+	)
+}
+
+// PgxPool gets a named pgx pool, creating it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the pool name
+//
+// Returns:
+//
+//   - *pgxpool.Pool: the pgx pool
+//   - error: if any error occurs
+func (r *Registry) PgxPool(ctx context.Context, name string) (
+	*pgxpool.Pool,
+	error,
+) {
+	if r == nil {
+		return nil, ErrNilRegistry
+	}
+	return r.pgxPools.get(ctx, name)
+}
+
+// TenantPgxPool gets the pgx pool of a tenant, creating it on first use
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - name: the pool name
+//   - tenant: the tenant key
+//
+// Returns:
+//
+//   - *pgxpool.Pool: the pgx pool
+//   - error: if any error occurs
+func (r *Registry) TenantPgxPool(
+	ctx context.Context,
+	name,
+	tenant string,
+) (*pgxpool.Pool, error) {
+	if r == nil {
+		return nil, ErrNilRegistry
+	}
+	return r.pgxPools.getTenant(ctx, name, tenant)
+}
+
+// CloseAll disconnects every connected SQL handler, MongoDB client and pgx pool
+//
+// The registered connections are connected again on next use, while the tenant connections are forgotten.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - error: the joined errors, each wrapping godatabases.ErrFailedToDisconnect, or nil if every connection
+//     was disconnected
+func (r *Registry) CloseAll(ctx context.Context) error {
+	if r == nil {
+		return ErrNilRegistry
+	}
+
+	return errors.Join(
+		r.sqlHandlers.closeAll(ctx),
+		r.mongoClients.closeAll(ctx),
+		r.pgxPools.closeAll(ctx),
+	)
+}
@@ -0,0 +1,212 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+const (
+	// unlockStatement is the statement that releases a session-level advisory lock
+	unlockStatement = "SELECT pg_advisory_unlock($1)"
+)
+
+// advisoryLockHandler answers the advisory lock statements, reporting if the lock could be acquired
+func advisoryLockHandler(acquired bool) sqltest.HandlerFn {
+	return func(context.Context, string, []driver.NamedValue) (
+		*sqltest.Result,
+		error,
+	) {
+		return &sqltest.Result{
+			Columns: []string{"result"},
+			Rows:    [][]driver.Value{{acquired}},
+		}, nil
+	}
+}
+
+// waitForClosedConnections waits until every connection of the connector is closed
+func waitForClosedConnections(t *testing.T, connector *sqltest.Connector) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for connector.OpenConnections() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("open connections = %d, want 0", connector.OpenConnections())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		acquired bool
+		wantErr  error
+	}{
+		{name: "acquired", acquired: true},
+		{name: "held by another session", wantErr: ErrAdvisoryLockNotAcquired},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, connector := sqltest.Open(advisoryLockHandler(tt.acquired))
+				defer db.Close()
+
+				advisoryLock, err := TryLock(context.Background(), db, NewAdvisoryLockKey("job"))
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("TryLock() error = %v, want %v", err, tt.wantErr)
+				}
+				if (advisoryLock != nil) != tt.acquired {
+					t.Fatalf("TryLock() lock = %v, want acquired %v", advisoryLock, tt.acquired)
+				}
+				if advisoryLock == nil {
+					// Check that the dedicated connection was returned to the pool
+					if stats := db.Stats(); stats.InUse != 0 {
+						t.Errorf("in use connections = %d, want 0", stats.InUse)
+					}
+					if open := connector.OpenConnections(); open != 1 {
+						t.Errorf("open connections = %d, want 1", open)
+					}
+					return
+				}
+				if err = advisoryLock.Unlock(context.Background()); err != nil {
+					t.Errorf("Unlock() error = %v", err)
+				}
+				if err = advisoryLock.Unlock(context.Background()); !errors.Is(err, ErrNilAdvisoryLock) {
+					t.Errorf("second Unlock() error = %v, want %v", err, ErrNilAdvisoryLock)
+				}
+			},
+		)
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	errFn := errors.New("function failed")
+
+	tests := []struct {
+		name       string
+		fn         func(cancel context.CancelFunc) error
+		wantErr    []error
+		wantUnlock bool
+	}{
+		{
+			name: "releases the lock",
+			fn: func(context.CancelFunc) error {
+				return nil
+			},
+			wantUnlock: true,
+		},
+		{
+			name: "releases the lock on failure",
+			fn: func(context.CancelFunc) error {
+				return errFn
+			},
+			wantErr:    []error{errFn},
+			wantUnlock: true,
+		},
+		{
+			name: "discards the connection once the context is done",
+			fn: func(cancel context.CancelFunc) error {
+				cancel()
+				return nil
+			},
+			wantErr: []error{ErrAdvisoryLockReleased, context.Canceled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, connector := sqltest.Open(advisoryLockHandler(true))
+				defer db.Close()
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				err := WithLock(
+					ctx,
+					db,
+					NewAdvisoryLockKey("job"),
+					func(context.Context) error {
+						return tt.fn(cancel)
+					},
+				)
+				for _, wantErr := range tt.wantErr {
+					if !errors.Is(err, wantErr) {
+						t.Errorf("WithLock() error = %v, want %v", err, wantErr)
+					}
+				}
+				if len(tt.wantErr) == 0 && err != nil {
+					t.Errorf("WithLock() error = %v", err)
+				}
+
+				// Check how the lock was released
+				gotUnlock := slices.Contains(connector.Statements(), unlockStatement)
+				if gotUnlock != tt.wantUnlock {
+					t.Errorf("unlocked = %v, want %v", gotUnlock, tt.wantUnlock)
+				}
+				if !tt.wantUnlock {
+					waitForClosedConnections(t, connector)
+				}
+			},
+		)
+	}
+}
+
+func TestTryWithLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		acquired bool
+		fn       LockFn
+		wantErr  error
+	}{
+		{
+			name:     "runs the function",
+			acquired: true,
+			fn: func(context.Context) error {
+				return nil
+			},
+		},
+		{
+			name: "skips the function",
+			fn: func(context.Context) error {
+				return errors.New("the function should not run")
+			},
+		},
+		{
+			name:     "nil function",
+			acquired: true,
+			wantErr:  ErrNilLockFn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, connector := sqltest.Open(advisoryLockHandler(tt.acquired))
+				defer db.Close()
+
+				ran, err := TryWithLock(
+					context.Background(),
+					db,
+					NewAdvisoryLockKey("job"),
+					tt.fn,
+				)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("TryWithLock() error = %v, want %v", err, tt.wantErr)
+				}
+				wantRan := tt.acquired && tt.fn != nil
+				if ran != wantRan {
+					t.Errorf("TryWithLock() ran = %v, want %v", ran, wantRan)
+				}
+
+				// Check that only the acquired locks are released
+				unlocked := slices.Contains(connector.Statements(), unlockStatement)
+				if unlocked != wantRan {
+					t.Errorf("unlocked = %v, want %v", unlocked, wantRan)
+				}
+			},
+		)
+	}
+}
@@ -6,18 +6,75 @@ import (
 	godatabases "github.com/ralvarezdev/go-databases"
 )
 
+const (
+	// DefaultPingTimeout is the default timeout for each ping done while connecting
+	DefaultPingTimeout = 5 * time.Second
+)
+
 type (
 	// Config struct
+	//
+	// PingTimeout is the timeout for each ping done while connecting, DefaultPingTimeout is used if it is not
+	// positive. ConnectRetryPolicy is the retry policy for the pings done while connecting, the database is
+	// pinged only once if it is nil, and every ping error is retried if its IsRetryable function is nil.
+	// DataSourceNameProvider, if set, is consulted each time a new physical connection is opened instead of
+	// using DataSourceName, so rotated credentials take effect without reopening the pool.
 	Config struct {
-		DriverName            string
-		DataSourceName        string
-		MaxOpenConnections    int
-		MaxIdleConnections    int
-		ConnectionMaxLifetime time.Duration
-		ConnectionMaxIdleTime time.Duration
+		DriverName             string
+		DataSourceName         string
+		MaxOpenConnections     int
+		MaxIdleConnections     int
+		ConnectionMaxLifetime  time.Duration
+		ConnectionMaxIdleTime  time.Duration
+		PingTimeout            time.Duration
+		ConnectRetryPolicy     *RetryPolicy
+		DataSourceNameProvider DataSourceNameProvider
 	}
 )
 
+// NewConfigWithProvider creates a new configuration for the connection that gets the data source name from
+// the given provider each time a new physical connection is opened
+//
+// Parameters:
+//
+//   - driverName: the name of the driver
+//   - provider: the data source name provider
+//   - maxOpenConnections: the maximum number of open connections
+//   - maxIdleConnections: the maximum number of idle connections
+//   - connectionMaxIdleTime: the maximum idle time for a connection
+//   - connectionMaxLifetime: the maximum lifetime for a connection, which bounds how long the connections
+//     opened with rotated out credentials are kept
+//
+// Returns:
+//
+//   - *Config: the connection configuration
+//   - error: if any error occurs
+func NewConfigWithProvider(
+	driverName string,
+	provider DataSourceNameProvider,
+	maxOpenConnections,
+	maxIdleConnections int,
+	connectionMaxIdleTime,
+	connectionMaxLifetime time.Duration,
+) (*Config, error) {
+	// Check if the driver name is empty or the provider is nil
+	if driverName == "" {
+		return nil, godatabases.ErrEmptyDriverName
+	}
+	if provider == nil {
+		return nil, ErrNilDataSourceNameProvider
+	}
+
+	return &Config{
+		DriverName:             driverName,
+		DataSourceNameProvider: provider,
+		MaxOpenConnections:     maxOpenConnections,
+		MaxIdleConnections:     maxIdleConnections,
+		ConnectionMaxLifetime:  connectionMaxLifetime,
+		ConnectionMaxIdleTime:  connectionMaxIdleTime,
+	}, nil
+}
+
 // NewConfig creates a new configuration for the connection
 //
 // Parameters:
@@ -50,11 +107,11 @@ func NewConfig(
 	}
 
 	return &Config{
-		driverName,
-		dataSourceName,
-		maxOpenConnections,
-		maxIdleConnections,
-		connectionMaxIdleTime,
-		connectionMaxLifetime,
+		DriverName:            driverName,
+		DataSourceName:        dataSourceName,
+		MaxOpenConnections:    maxOpenConnections,
+		MaxIdleConnections:    maxIdleConnections,
+		ConnectionMaxLifetime: connectionMaxLifetime,
+		ConnectionMaxIdleTime: connectionMaxIdleTime,
 	}, nil
 }
@@ -0,0 +1,452 @@
+package sql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+)
+
+const (
+	// EnvDriverName is the environment variable suffix for the driver name
+	EnvDriverName = "DRIVER_NAME"
+
+	// EnvDataSourceName is the environment variable suffix for the data source name
+	EnvDataSourceName = "DATA_SOURCE_NAME"
+
+	// EnvMaxOpenConnections is the environment variable suffix for the maximum number of open connections
+	EnvMaxOpenConnections = "MAX_OPEN_CONNECTIONS"
+
+	// EnvMaxIdleConnections is the environment variable suffix for the maximum number of idle connections
+	EnvMaxIdleConnections = "MAX_IDLE_CONNECTIONS"
+
+	// EnvConnectionMaxLifetime is the environment variable suffix for the maximum lifetime for a connection
+	EnvConnectionMaxLifetime = "CONNECTION_MAX_LIFETIME"
+
+	// EnvConnectionMaxIdleTime is the environment variable suffix for the maximum idle time for a connection
+	EnvConnectionMaxIdleTime = "CONNECTION_MAX_IDLE_TIME"
+
+	// EnvPingTimeout is the environment variable suffix for the ping timeout
+	EnvPingTimeout = "PING_TIMEOUT"
+)
+
+const (
+	// URLParamDriverName is the URL query parameter that overrides the driver name
+	URLParamDriverName = "driver"
+
+	// URLParamMaxOpenConnections is the URL query parameter for the maximum number of open connections
+	URLParamMaxOpenConnections = "max_open_connections"
+
+	// URLParamMaxIdleConnections is the URL query parameter for the maximum number of idle connections
+	URLParamMaxIdleConnections = "max_idle_connections"
+
+	// URLParamConnectionMaxLifetime is the URL query parameter for the maximum lifetime for a connection
+	URLParamConnectionMaxLifetime = "connection_max_lifetime"
+
+	// URLParamConnectionMaxIdleTime is the URL query parameter for the maximum idle time for a connection
+	URLParamConnectionMaxIdleTime = "connection_max_idle_time"
+
+	// URLParamPingTimeout is the URL query parameter for the ping timeout
+	URLParamPingTimeout = "ping_timeout"
+)
+
+const (
+	// PostgresDriverName is the driver name used for the postgres URLs, registered by the pgx stdlib package
+	PostgresDriverName = "pgx"
+
+	// MySQLDriverName is the driver name used for the mysql URLs
+	MySQLDriverName = "mysql"
+)
+
+type (
+	// configFile is the file representation of the configuration, with the durations as strings such as "5m"
+	configFile struct {
+		DriverName            string `json:"driver_name"              yaml:"driver_name"`
+		DataSourceName        string `json:"data_source_name"         yaml:"data_source_name"`
+		MaxOpenConnections    int    `json:"max_open_connections"     yaml:"max_open_connections"`
+		MaxIdleConnections    int    `json:"max_idle_connections"     yaml:"max_idle_connections"`
+		ConnectionMaxLifetime string `json:"connection_max_lifetime"  yaml:"connection_max_lifetime"`
+		ConnectionMaxIdleTime string `json:"connection_max_idle_time" yaml:"connection_max_idle_time"`
+		PingTimeout           string `json:"ping_timeout"             yaml:"ping_timeout"`
+	}
+)
+
+// Validate checks the configuration values
+//
+// Returns:
+//
+//   - error: the joined descriptive errors of the invalid values, or nil if the configuration is valid
+func (c *Config) Validate() error {
+	if c == nil {
+		return godatabases.ErrNilConfig
+	}
+
+	var errs []error
+
+	// Check the driver and data source names
+	if c.DriverName == "" {
+		errs = append(errs, godatabases.ErrEmptyDriverName)
+	}
+	if c.DataSourceName == "" && c.DataSourceNameProvider == nil {
+		errs = append(errs, godatabases.ErrEmptyDataSourceName)
+	}
+
+	// Check the connection limits
+	if c.MaxOpenConnections < 0 {
+		errs = append(
+			errs,
+			fmt.Errorf(ErrNegativeValue, "max open connections", c.MaxOpenConnections),
+		)
+	}
+	if c.MaxIdleConnections < 0 {
+		errs = append(
+			errs,
+			fmt.Errorf(ErrNegativeValue, "max idle connections", c.MaxIdleConnections),
+		)
+	}
+	if c.MaxOpenConnections > 0 && c.MaxIdleConnections > c.MaxOpenConnections {
+		errs = append(
+			errs,
+			fmt.Errorf(
+				ErrIdleExceedsOpenConnections,
+				c.MaxIdleConnections,
+				c.MaxOpenConnections,
+			),
+		)
+	}
+
+	// Check the durations
+	for name, duration := range map[string]time.Duration{
+		"connection max lifetime":  c.ConnectionMaxLifetime,
+		"connection max idle time": c.ConnectionMaxIdleTime,
+		"ping timeout":             c.PingTimeout,
+	} {
+		if duration < 0 {
+			errs = append(errs, fmt.Errorf(ErrNegativeValue, name, duration))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewConfigFromEnv creates a new configuration for the connection from the environment variables
+//
+// The variables are named after the prefix and the Env* suffixes joined by an underscore, such as
+// DB_MAX_OPEN_CONNECTIONS for the "DB" prefix. The durations are parsed with time.ParseDuration.
+//
+// Parameters:
+//
+//   - prefix: the prefix of the environment variables, it can be empty
+//
+// Returns:
+//
+//   - *Config: the connection configuration
+//   - error: if any variable is invalid or the configuration is not valid
+func NewConfigFromEnv(prefix string) (*Config, error) {
+	// Create the lookup function
+	lookup := func(suffix string) (string, string, bool) {
+		key := suffix
+		if prefix != "" {
+			key = strings.TrimSuffix(prefix, "_") + "_" + suffix
+		}
+		value, ok := os.LookupEnv(key)
+		return key, value, ok && value != ""
+	}
+
+	config := &Config{}
+	var errs []error
+
+	// Load the string values
+	_, config.DriverName, _ = lookup(EnvDriverName)
+	_, config.DataSourceName, _ = lookup(EnvDataSourceName)
+
+	// Load the integer values
+	for suffix, target := range map[string]*int{
+		EnvMaxOpenConnections: &config.MaxOpenConnections,
+		EnvMaxIdleConnections: &config.MaxIdleConnections,
+	} {
+		if key, value, ok := lookup(suffix); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf(ErrInvalidEnvValue, key, err))
+				continue
+			}
+			*target = parsed
+		}
+	}
+
+	// Load the duration values
+	for suffix, target := range map[string]*time.Duration{
+		EnvConnectionMaxLifetime: &config.ConnectionMaxLifetime,
+		EnvConnectionMaxIdleTime: &config.ConnectionMaxIdleTime,
+		EnvPingTimeout:           &config.PingTimeout,
+	} {
+		if key, value, ok := lookup(suffix); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf(ErrInvalidEnvValue, key, err))
+				continue
+			}
+			*target = parsed
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Validate the configuration
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// NewConfigFromURL creates a new configuration for the connection from a postgres:// or mysql:// URL
+//
+// The pool settings are read from the URL query parameters named after the URLParam* constants, and removed
+// from the data source name. The driver name is picked from the URL scheme unless the 'driver' query parameter
+// is set. The mysql URLs are converted to the go-sql-driver/mysql data source name format.
+//
+// Parameters:
+//
+//   - rawURL: the database URL
+//
+// Returns:
+//
+//   - *Config: the connection configuration
+//   - error: if the URL or any query parameter is invalid, or the configuration is not valid
+func NewConfigFromURL(rawURL string) (*Config, error) {
+	// Parse the URL
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf(ErrInvalidURL, err)
+	}
+
+	config := &Config{}
+	query := parsedURL.Query()
+	var errs []error
+
+	// Load the integer values
+	for param, target := range map[string]*int{
+		URLParamMaxOpenConnections: &config.MaxOpenConnections,
+		URLParamMaxIdleConnections: &config.MaxIdleConnections,
+	} {
+		if value := query.Get(param); value != "" {
+			parsed, parseErr := strconv.Atoi(value)
+			if parseErr != nil {
+				errs = append(errs, fmt.Errorf(ErrInvalidURLParam, param, parseErr))
+			} else {
+				*target = parsed
+			}
+		}
+		query.Del(param)
+	}
+
+	// Load the duration values
+	for param, target := range map[string]*time.Duration{
+		URLParamConnectionMaxLifetime: &config.ConnectionMaxLifetime,
+		URLParamConnectionMaxIdleTime: &config.ConnectionMaxIdleTime,
+		URLParamPingTimeout:           &config.PingTimeout,
+	} {
+		if value := query.Get(param); value != "" {
+			parsed, parseErr := time.ParseDuration(value)
+			if parseErr != nil {
+				errs = append(errs, fmt.Errorf(ErrInvalidURLParam, param, parseErr))
+			} else {
+				*target = parsed
+			}
+		}
+		query.Del(param)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Load the driver name override
+	config.DriverName = query.Get(URLParamDriverName)
+	query.Del(URLParamDriverName)
+
+	// Build the data source name depending on the scheme
+	parsedURL.RawQuery = query.Encode()
+	switch strings.ToLower(parsedURL.Scheme) {
+	case "postgres", "postgresql":
+		if config.DriverName == "" {
+			config.DriverName = PostgresDriverName
+		}
+		config.DataSourceName = parsedURL.String()
+	case "mysql":
+		if config.DriverName == "" {
+			config.DriverName = MySQLDriverName
+		}
+		config.DataSourceName = mySQLDataSourceName(parsedURL)
+	default:
+		return nil, fmt.Errorf(ErrUnsupportedURLScheme, parsedURL.Scheme)
+	}
+
+	// Validate the configuration
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// mySQLDataSourceName converts a mysql:// URL to the go-sql-driver/mysql data source name format
+//
+// Parameters:
+//
+//   - parsedURL: the mysql URL
+//
+// Returns:
+//
+//   - string: the data source name, such as user:password@tcp(host:port)/database?params
+func mySQLDataSourceName(parsedURL *url.URL) string {
+	var builder strings.Builder
+
+	// Add the user info
+	if parsedURL.User != nil {
+		builder.WriteString(parsedURL.User.Username())
+		if password, ok := parsedURL.User.Password(); ok {
+			builder.WriteString(":")
+			builder.WriteString(password)
+		}
+		builder.WriteString("@")
+	}
+
+	// Add the address, the database and the parameters
+	if parsedURL.Host != "" {
+		builder.WriteString("tcp(")
+		builder.WriteString(parsedURL.Host)
+		builder.WriteString(")")
+	}
+	builder.WriteString("/")
+	builder.WriteString(strings.TrimPrefix(parsedURL.Path, "/"))
+	if parsedURL.RawQuery != "" {
+		builder.WriteString("?")
+		builder.WriteString(parsedURL.RawQuery)
+	}
+	return builder.String()
+}
+
+// toConfig converts the file representation to a configuration
+//
+// Returns:
+//
+//   - *Config: the connection configuration
+//   - error: if any duration is invalid or the configuration is not valid
+func (c *configFile) toConfig() (*Config, error) {
+	config := &Config{
+		DriverName:         c.DriverName,
+		DataSourceName:     c.DataSourceName,
+		MaxOpenConnections: c.MaxOpenConnections,
+		MaxIdleConnections: c.MaxIdleConnections,
+	}
+
+	// Parse the durations
+	var errs []error
+	for field, duration := range map[string]struct {
+		value  string
+		target *time.Duration
+	}{
+		"connection_max_lifetime":  {c.ConnectionMaxLifetime, &config.ConnectionMaxLifetime},
+		"connection_max_idle_time": {c.ConnectionMaxIdleTime, &config.ConnectionMaxIdleTime},
+		"ping_timeout":             {c.PingTimeout, &config.PingTimeout},
+	} {
+		if duration.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(duration.value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(ErrInvalidConfigField, field, err))
+			continue
+		}
+		*duration.target = parsed
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Validate the configuration
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// NewConfigFromJSON creates a new configuration for the connection from JSON
+//
+// The fields are named in snake case, such as max_open_connections, and the durations are strings parsed with
+// time.ParseDuration.
+//
+// Parameters:
+//
+//   - data: the JSON data
+//
+// Returns:
+//
+//   - *Config: the connection configuration
+//   - error: if the data is invalid or the configuration is not valid
+func NewConfigFromJSON(data []byte) (*Config, error) {
+	var file configFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf(ErrInvalidConfigData, "JSON", err)
+	}
+	return file.toConfig()
+}
+
+// NewConfigFromYAML creates a new configuration for the connection from YAML
+//
+// The fields are named in snake case, such as max_open_connections, and the durations are strings parsed with
+// time.ParseDuration.
+//
+// Parameters:
+//
+//   - data: the YAML data
+//
+// Returns:
+//
+//   - *Config: the connection configuration
+//   - error: if the data is invalid or the configuration is not valid
+func NewConfigFromYAML(data []byte) (*Config, error) {
+	var file configFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf(ErrInvalidConfigData, "YAML", err)
+	}
+	return file.toConfig()
+}
+
+// NewConfigFromFile creates a new configuration for the connection from a JSON or YAML file, picked by its
+// extension
+//
+// Parameters:
+//
+//   - path: the file path, with a .json, .yaml or .yml extension
+//
+// Returns:
+//
+//   - *Config: the connection configuration
+//   - error: if the file could not be read, is invalid or the configuration is not valid
+func NewConfigFromFile(path string) (*Config, error) {
+	// Read the file
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the file depending on its extension
+	switch extension := strings.ToLower(filepath.Ext(path)); extension {
+	case ".json":
+		return NewConfigFromJSON(data)
+	case ".yaml", ".yml":
+		return NewConfigFromYAML(data)
+	default:
+		return nil, fmt.Errorf(ErrUnsupportedConfigFile, extension)
+	}
+}
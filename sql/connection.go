@@ -1,8 +1,11 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"sync"
+	"time"
 
 	godatabases "github.com/ralvarezdev/go-databases"
 )
@@ -40,6 +43,9 @@ func NewDefaultHandler(
 
 // Connect returns a new SQL connection
 //
+// The database is pinged before returning the connection, retrying the ping as configured by the connection
+// retry policy, so an unreachable database or a bad data source name is reported here.
+//
 // Returns:
 //
 //   - *sql.DB: the SQL connection
@@ -59,9 +65,9 @@ func (d *DefaultHandler) Connect() (*sql.DB, error) {
 	}
 
 	// Open a new connection
-	db, err := sql.Open(d.config.DriverName, d.config.DataSourceName)
+	db, err := d.open()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrConnectionFailed, err)
 	}
 
 	// Set the maximum open connections
@@ -76,12 +82,107 @@ func (d *DefaultHandler) Connect() (*sql.DB, error) {
 	// Set the connection max idle time
 	db.SetConnMaxIdleTime(d.config.ConnectionMaxIdleTime)
 
+	// Ping the database
+	if err = d.ping(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrPingFailed, err)
+	}
+
 	// Set client
 	d.db = db
 
 	return db, nil
 }
 
+// open opens the SQL connection, through a connector if the configuration has a data source name provider
+//
+// Returns:
+//
+//   - *sql.DB: the SQL connection
+//   - error: if any error occurred
+func (d *DefaultHandler) open() (*sql.DB, error) {
+	// Check if the data source name is provided dynamically
+	if d.config.DataSourceNameProvider == nil {
+		return sql.Open(d.config.DriverName, d.config.DataSourceName)
+	}
+
+	// Create the connector
+	connector, err := NewConnector(
+		d.config.DriverName,
+		d.config.DataSourceNameProvider,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// ping pings the database, retrying the ping as configured by the connection retry policy
+//
+// Parameters:
+//
+//   - db: the SQL connection to ping
+//
+// Returns:
+//
+//   - error: if the database could not be pinged
+func (d *DefaultHandler) ping(db *sql.DB) error {
+	// Set the ping timeout
+	pingTimeout := d.config.PingTimeout
+	if pingTimeout <= 0 {
+		pingTimeout = DefaultPingTimeout
+	}
+
+	// Set the retry policy, retrying every ping error if no classifier is set
+	var policy *RetryPolicy
+	if d.config.ConnectRetryPolicy != nil {
+		connectRetryPolicy := *d.config.ConnectRetryPolicy
+		if connectRetryPolicy.IsRetryable == nil {
+			connectRetryPolicy.IsRetryable = isAnyError
+		}
+		policy = &connectRetryPolicy
+	}
+
+	// Ping the database
+	return policy.Run(
+		context.Background(),
+		func(ctx context.Context) error {
+			pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+			defer cancel()
+			return db.PingContext(pingCtx)
+		},
+	)
+}
+
+// Ping pings the SQL connection
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - time.Duration: the ping latency
+//   - error: if any error occurred
+func (d *DefaultHandler) Ping(ctx context.Context) (time.Duration, error) {
+	if d == nil {
+		return 0, godatabases.ErrNilHandler
+	}
+
+	// Get the database connection
+	db, err := d.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	// Ping the database
+	start := time.Now()
+	if err = db.PingContext(ctx); err != nil {
+		return time.Since(start), fmt.Errorf("%w: %w", godatabases.ErrPingFailed, err)
+	}
+	return time.Since(start), nil
+}
+
 // DB returns the SQL connection
 //
 // Returns:
@@ -104,6 +205,25 @@ func (d *DefaultHandler) DB() (*sql.DB, error) {
 	return d.db, nil
 }
 
+// Stats returns the statistics of the SQL connection pool
+//
+// Returns:
+//
+//   - sql.DBStats: the connection pool statistics
+//   - error: if any error occurred
+func (d *DefaultHandler) Stats() (sql.DBStats, error) {
+	if d == nil {
+		return sql.DBStats{}, godatabases.ErrNilHandler
+	}
+
+	// Get the database connection
+	db, err := d.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return db.Stats(), nil
+}
+
 // IsConnected checks if the SQL connection is established
 //
 // Returns:
@@ -0,0 +1,104 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+var (
+	// pingDriverCount is the number of fake drivers registered for the ping tests, used to name them
+	pingDriverCount atomic.Int64
+)
+
+// registerPingDriver registers a fake driver whose first pings fail
+func registerPingDriver(t *testing.T, failures int64) (string, *atomic.Int64, *sqltest.Connector) {
+	t.Helper()
+	var pings atomic.Int64
+	connector := sqltest.NewConnector(
+		func(_ context.Context, query string, _ []driver.NamedValue) (
+			*sqltest.Result,
+			error,
+		) {
+			if query == sqltest.PingStatement && pings.Add(1) <= failures {
+				return nil, errUnreachable
+			}
+			return &sqltest.Result{}, nil
+		},
+	)
+
+	// Register the driver with a unique name, since the drivers cannot be unregistered
+	driverName := fmt.Sprintf("sqltest-ping-%d", pingDriverCount.Add(1))
+	sql.Register(driverName, connector.Driver())
+	return driverName, &pings, connector
+}
+
+func TestDefaultHandlerConnectPing(t *testing.T) {
+	retryPolicy := &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Multiplier:     1,
+	}
+
+	tests := []struct {
+		name        string
+		failures    int64
+		retryPolicy *RetryPolicy
+		wantPings   int64
+		wantErr     bool
+	}{
+		{name: "reachable database", wantPings: 1},
+		{name: "failing ping without retries", failures: 1, wantPings: 1, wantErr: true},
+		{name: "ping retried until it succeeds", failures: 2, retryPolicy: retryPolicy, wantPings: 3},
+		{name: "retries exhausted", failures: 5, retryPolicy: retryPolicy, wantPings: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				driverName, pings, connector := registerPingDriver(t, tt.failures)
+				handler, err := NewDefaultHandler(
+					&Config{
+						DriverName:         driverName,
+						PingTimeout:        time.Second,
+						ConnectRetryPolicy: tt.retryPolicy,
+					},
+				)
+				if err != nil {
+					t.Fatalf("NewDefaultHandler() error = %v", err)
+				}
+				defer handler.Disconnect()
+
+				_, err = handler.Connect()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Connect() error = %v, want error %v", err, tt.wantErr)
+				}
+				if got := pings.Load(); got != tt.wantPings {
+					t.Errorf("pings = %d, want %d", got, tt.wantPings)
+				}
+				if !tt.wantErr {
+					return
+				}
+
+				// Check that the error is reported and the failed connection is closed
+				if !errors.Is(err, godatabases.ErrPingFailed) || !errors.Is(err, errUnreachable) {
+					t.Errorf("Connect() error = %v, want %v and %v", err, godatabases.ErrPingFailed, errUnreachable)
+				}
+				if handler.IsConnected() {
+					t.Errorf("IsConnected() = true, want false")
+				}
+				if open := connector.OpenConnections(); open != 0 {
+					t.Errorf("open connections = %d, want 0", open)
+				}
+			},
+		)
+	}
+}
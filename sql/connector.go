@@ -0,0 +1,205 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+)
+
+type (
+	// DataSourceNameProviderFn is the function type that implements DataSourceNameProvider
+	DataSourceNameProviderFn func(ctx context.Context) (string, error)
+
+	// FileDataSourceNameProvider provides the data source name stored in a file, reading it again whenever the
+	// file changes
+	FileDataSourceNameProvider struct {
+		path           string
+		dataSourceName string
+		modTime        time.Time
+		size           int64
+		mutex          sync.Mutex
+	}
+
+	// providerConnector is the connector that opens each new physical connection with the data source name
+	// returned by its provider
+	providerConnector struct {
+		driver   driver.Driver
+		provider DataSourceNameProvider
+	}
+)
+
+// DataSourceName returns the data source name returned by the function
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - string: the data source name
+//   - error: if any error occurs
+func (d DataSourceNameProviderFn) DataSourceName(ctx context.Context) (
+	string,
+	error,
+) {
+	return d(ctx)
+}
+
+// NewFileDataSourceNameProvider creates a new file data source name provider
+//
+// Parameters:
+//
+//   - path: the path of the file that contains the data source name
+//
+// Returns:
+//
+//   - *FileDataSourceNameProvider: the file data source name provider
+//   - error: if the path is empty
+func NewFileDataSourceNameProvider(path string) (
+	*FileDataSourceNameProvider,
+	error,
+) {
+	// Check if the path is empty
+	if path == "" {
+		return nil, ErrEmptyDataSourceNamePath
+	}
+
+	return &FileDataSourceNameProvider{
+		path: filepath.Clean(path),
+	}, nil
+}
+
+// DataSourceName returns the data source name stored in the file, reading it again if the file changed since
+// the last call
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - string: the data source name, with the surrounding whitespace trimmed
+//   - error: if the file could not be read or is empty
+func (f *FileDataSourceNameProvider) DataSourceName(context.Context) (
+	string,
+	error,
+) {
+	if f == nil {
+		return "", ErrNilDataSourceNameProvider
+	}
+
+	// Lock the mutex to ensure thread safety
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// Check if the file changed
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+	if f.dataSourceName != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.dataSourceName, nil
+	}
+
+	// Read the file
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+	dataSourceName := string(bytes.TrimSpace(data))
+	if dataSourceName == "" {
+		return "", godatabases.ErrEmptyDataSourceName
+	}
+
+	f.dataSourceName = dataSourceName
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return dataSourceName, nil
+}
+
+// NewConnector creates a new connector that opens each new physical connection with the data source name
+// returned by the provider, so rotated credentials are used without reopening the pool
+//
+// The driver is looked up by opening a handle with an empty data source name, which does not connect to the
+// database.
+//
+// Parameters:
+//
+//   - driverName: the name of the registered driver
+//   - provider: the data source name provider
+//
+// Returns:
+//
+//   - driver.Connector: the connector
+//   - error: if the driver is not registered or the provider is nil
+func NewConnector(
+	driverName string,
+	provider DataSourceNameProvider,
+) (driver.Connector, error) {
+	// Check if the driver name is empty or the provider is nil
+	if driverName == "" {
+		return nil, godatabases.ErrEmptyDriverName
+	}
+	if provider == nil {
+		return nil, ErrNilDataSourceNameProvider
+	}
+
+	// Look up the driver
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	if err = db.Close(); err != nil {
+		return nil, err
+	}
+
+	return &providerConnector{
+		driver:   drv,
+		provider: provider,
+	}, nil
+}
+
+// Connect opens a new physical connection with the data source name returned by the provider
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - driver.Conn: the physical connection
+//   - error: if any error occurs
+func (p *providerConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	// Get the data source name
+	dataSourceName, err := p.provider.DataSourceName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open the connection, using the driver connector if supported
+	if driverContext, ok := p.driver.(driver.DriverContext); ok {
+		connector, connectorErr := driverContext.OpenConnector(dataSourceName)
+		if connectorErr != nil {
+			return nil, connectorErr
+		}
+		return connector.Connect(ctx)
+	}
+	return p.driver.Open(dataSourceName)
+}
+
+// Driver returns the underlying driver
+//
+// Returns:
+//
+//   - driver.Driver: the driver
+func (p *providerConnector) Driver() driver.Driver {
+	return p.driver
+}
@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+)
+
+type (
+	// txContextKey is the context key for the active transaction
+	txContextKey struct{}
+
+	// primaryContextKey is the context key for the flag that forces the reads to the primary
+	primaryContextKey struct{}
+
+	// txContextValue is the context value for the active transaction
+	txContextValue struct {
+		tx    *sql.Tx
+		depth int
+	}
+)
+
+// WithTx returns a copy of the context that carries the given transaction
+//
+// Parameters:
+//
+//   - ctx: the parent context
+//   - tx: the transaction
+//
+// Returns:
+//
+//   - context.Context: the context carrying the transaction
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return withTxContextValue(ctx, &txContextValue{tx: tx})
+}
+
+// WithoutTx returns a copy of the context that carries no transaction, so the transactions created from it
+// are not nested into the active transaction of the parent context
+//
+// Parameters:
+//
+//   - ctx: the parent context
+//
+// Returns:
+//
+//   - context.Context: the context carrying no transaction
+func WithoutTx(ctx context.Context) context.Context {
+	return withTxContextValue(ctx, nil)
+}
+
+// withTxContextValue returns a copy of the context that carries the given transaction context value
+//
+// Parameters:
+//
+//   - ctx: the parent context
+//   - value: the transaction context value
+//
+// Returns:
+//
+//   - context.Context: the context carrying the transaction context value
+func withTxContextValue(
+	ctx context.Context,
+	value *txContextValue,
+) context.Context {
+	return context.WithValue(ctx, txContextKey{}, value)
+}
+
+// getTxContextValue gets the transaction context value from the context
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - *txContextValue: the transaction context value
+//   - bool: true if the context carries an active transaction, false otherwise
+func getTxContextValue(ctx context.Context) (*txContextValue, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	value, ok := ctx.Value(txContextKey{}).(*txContextValue)
+	if !ok || value == nil || value.tx == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// GetTxFromContext gets the active transaction from the context
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - *sql.Tx: the active transaction
+//   - bool: true if the context carries an active transaction, false otherwise
+func GetTxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	value, ok := getTxContextValue(ctx)
+	if !ok {
+		return nil, false
+	}
+	return value.tx, true
+}
+
+// GetExecutorFromContext gets the active transaction from the context, or the given database connection if
+// there is no active transaction
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - db: the database connection
+//
+// Returns:
+//
+//   - Executor: the executor to run the queries with
+func GetExecutorFromContext(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := GetTxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+// WithPrimary returns a copy of the context that forces the reads to be routed to the primary database, such
+// as right after a write that must be read back
+//
+// Parameters:
+//
+//   - ctx: the parent context
+//
+// Returns:
+//
+//   - context.Context: the context forcing the reads to the primary database
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// IsPrimaryForced checks if the context forces the reads to be routed to the primary database
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - bool: true if the reads must be routed to the primary database, false otherwise
+func IsPrimaryForced(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	forced, ok := ctx.Value(primaryContextKey{}).(bool)
+	return ok && forced
+}
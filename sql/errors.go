@@ -0,0 +1,45 @@
+package sql
+
+import (
+	"errors"
+)
+
+const (
+	ErrUnmappedColumn             = "column '%s' is not mapped to any field of '%v'"
+	ErrRetryAttemptsExhausted     = "retry attempts exhausted after %d attempts: %w"
+	ErrQueryFailed                = "query at index %d failed: %w"
+	ErrNegativeValue              = "%s cannot be negative, got %v"
+	ErrIdleExceedsOpenConnections = "max idle connections (%d) cannot exceed max open connections (%d)"
+	ErrInvalidEnvValue            = "invalid value for environment variable '%s': %w"
+	ErrInvalidURL                 = "invalid database url: %w"
+	ErrInvalidURLParam            = "invalid value for url parameter '%s': %w"
+	ErrUnsupportedURLScheme       = "unsupported database url scheme '%s'"
+	ErrInvalidConfigField         = "invalid value for config field '%s': %w"
+	ErrInvalidConfigData          = "invalid %s config: %w"
+	ErrUnsupportedConfigFile      = "unsupported config file extension '%s'"
+)
+
+var (
+	ErrNilOpenRowsFn               = errors.New("open rows function cannot be nil")
+	ErrNilRows                     = errors.New("sql rows cannot be nil")
+	ErrNilRetryFn                  = errors.New("retry function cannot be nil")
+	ErrNilQueryFn                  = errors.New("query function cannot be nil")
+	ErrNilTransactionFn            = errors.New("transaction function cannot be nil")
+	ErrNilDataSourceNameProvider   = errors.New("data source name provider cannot be nil")
+	ErrEmptyDataSourceNamePath     = errors.New("data source name file path cannot be empty")
+	ErrNilHook                     = errors.New("hook cannot be nil")
+	ErrNilLogger                   = errors.New("logger cannot be nil")
+	ErrNilPinger                   = errors.New("pinger cannot be nil")
+	ErrNilHealthChecker            = errors.New("health checker cannot be nil")
+	ErrHealthCheckerAlreadyStarted = errors.New("health checker already started")
+	ErrHealthUnknown               = errors.New("health status is unknown, no health check was run yet")
+	ErrInvalidMaxAttempts          = errors.New("max attempts must be greater than zero")
+	ErrInvalidBackoff              = errors.New("backoff cannot be negative")
+	ErrInvalidBackoffMultiplier    = errors.New("backoff multiplier must be greater than or equal to one")
+	ErrNilTransaction              = errors.New("transaction cannot be nil")
+	ErrNilLockFn                   = errors.New("lock function cannot be nil")
+	ErrNilAdvisoryLock             = errors.New("advisory lock cannot be nil or already released")
+	ErrAdvisoryLockNotHeld         = errors.New("advisory lock was not held by the session")
+	ErrAdvisoryLockNotAcquired     = errors.New("advisory lock is held by another session")
+	ErrAdvisoryLockReleased        = errors.New("advisory lock was released since the context is done")
+)
@@ -0,0 +1,272 @@
+package sql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHealthCheckInterval is the default interval between health checks
+	DefaultHealthCheckInterval = 15 * time.Second
+
+	// DefaultHealthCheckTimeout is the default timeout for each health check
+	DefaultHealthCheckTimeout = 2 * time.Second
+)
+
+const (
+	// HealthStateUnknown is the state before the first health check
+	HealthStateUnknown HealthState = iota
+
+	// HealthStateHealthy is the state when the last health check succeeded
+	HealthStateHealthy
+
+	// HealthStateUnhealthy is the state when the last health check failed
+	HealthStateUnhealthy
+)
+
+type (
+	// HealthState represents the state of a connection health
+	HealthState int
+
+	// HealthStatus represents the result of the last health check
+	HealthStatus struct {
+		State     HealthState
+		Latency   time.Duration
+		CheckedAt time.Time
+		Err       error
+	}
+
+	// HealthStateChangeFn is the function type called when the health state changes
+	HealthStateChangeFn func(previous, current HealthStatus)
+
+	// HealthChecker periodically pings a connection and keeps its last health status
+	HealthChecker struct {
+		pinger        Pinger
+		interval      time.Duration
+		timeout       time.Duration
+		onStateChange HealthStateChangeFn
+		status        HealthStatus
+		statusMutex   sync.RWMutex
+		cancel        context.CancelFunc
+		done          chan struct{}
+		mutex         sync.Mutex
+	}
+)
+
+// String returns the string representation of the health state
+//
+// Returns:
+//
+//   - string: the health state name
+func (h HealthState) String() string {
+	switch h {
+	case HealthStateHealthy:
+		return "healthy"
+	case HealthStateUnhealthy:
+		return "unhealthy"
+	default:
+		return "unknown"
+	}
+}
+
+// NewHealthChecker creates a new health checker
+//
+// Parameters:
+//
+//   - pinger: the connection to ping, such as a *DefaultHandler
+//   - interval: the interval between health checks, DefaultHealthCheckInterval is used if it is not positive
+//   - timeout: the timeout for each health check, DefaultHealthCheckTimeout is used if it is not positive
+//   - onStateChange: the function called when the health state changes, it can be nil
+//
+// Returns:
+//
+//   - *HealthChecker: the health checker
+//   - error: if any error occurs
+func NewHealthChecker(
+	pinger Pinger,
+	interval,
+	timeout time.Duration,
+	onStateChange HealthStateChangeFn,
+) (*HealthChecker, error) {
+	// Check if the pinger is nil
+	if pinger == nil {
+		return nil, ErrNilPinger
+	}
+
+	// Set the default interval and timeout
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+
+	return &HealthChecker{
+		pinger:        pinger,
+		interval:      interval,
+		timeout:       timeout,
+		onStateChange: onStateChange,
+	}, nil
+}
+
+// Start starts the background health checks, running the first one immediately
+//
+// The health checker can be started again once it is stopped, or once the context of the previous start is done.
+//
+// Parameters:
+//
+//   - ctx: the context, the health checks stop once it is done
+//
+// Returns:
+//
+//   - error: if the health checker is already started
+func (h *HealthChecker) Start(ctx context.Context) error {
+	if h == nil {
+		return ErrNilHealthChecker
+	}
+
+	// Lock the mutex to ensure thread safety
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// Check if the health checker is already started, the health checks stop once the parent context is done
+	if h.cancel != nil {
+		select {
+		case <-h.done:
+			h.cancel()
+		default:
+			return ErrHealthCheckerAlreadyStarted
+		}
+	}
+
+	// Create the health checks context
+	checksCtx, cancel := context.WithCancel(ctx)
+	h.cancel = cancel
+	h.done = make(chan struct{})
+
+	// Run the health checks
+	go func() {
+		defer close(h.done)
+
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			h.Check(checksCtx)
+
+			select {
+			case <-checksCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the background health checks and waits for them to finish
+func (h *HealthChecker) Stop() {
+	if h == nil {
+		return
+	}
+
+	// Lock the mutex to ensure thread safety
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// Check if the health checker is started
+	if h.cancel == nil {
+		return
+	}
+
+	// Stop the health checks
+	h.cancel()
+	<-h.done
+	h.cancel = nil
+	h.done = nil
+}
+
+// Check runs a health check, updating the last health status
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - HealthStatus: the health status
+func (h *HealthChecker) Check(ctx context.Context) HealthStatus {
+	if h == nil {
+		return HealthStatus{Err: ErrNilHealthChecker}
+	}
+
+	// Ping the connection
+	pingCtx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	latency, err := h.pinger.Ping(pingCtx)
+
+	// Build the health status
+	current := HealthStatus{
+		State:     HealthStateHealthy,
+		Latency:   latency,
+		CheckedAt: time.Now(),
+		Err:       err,
+	}
+	if err != nil {
+		current.State = HealthStateUnhealthy
+	}
+
+	// Update the health status
+	h.statusMutex.Lock()
+	previous := h.status
+	h.status = current
+	h.statusMutex.Unlock()
+
+	// Notify the state change
+	if h.onStateChange != nil && previous.State != current.State {
+		h.onStateChange(previous, current)
+	}
+	return current
+}
+
+// Status returns the last health status
+//
+// Returns:
+//
+//   - HealthStatus: the last health status
+func (h *HealthChecker) Status() HealthStatus {
+	if h == nil {
+		return HealthStatus{Err: ErrNilHealthChecker}
+	}
+
+	h.statusMutex.RLock()
+	defer h.statusMutex.RUnlock()
+	return h.status
+}
+
+// IsHealthy checks if the last health check succeeded
+//
+// Returns:
+//
+//   - bool: true if the last health check succeeded, false otherwise
+func (h *HealthChecker) IsHealthy() bool {
+	return h.Status().State == HealthStateHealthy
+}
+
+// Ready returns the error of the last health check, suitable for readiness probes
+//
+// Returns:
+//
+//   - error: the last health check error, ErrHealthUnknown if no health check was run yet, or nil if healthy
+func (h *HealthChecker) Ready() error {
+	status := h.Status()
+	switch {
+	case status.Err != nil:
+		return status.Err
+	case status.State == HealthStateUnknown:
+		return ErrHealthUnknown
+	default:
+		return nil
+	}
+}
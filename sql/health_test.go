@@ -0,0 +1,206 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+var (
+	// errUnreachable is the error returned by the failing pings
+	errUnreachable = errors.New("database unreachable")
+)
+
+// pingHandler fails the pings while the given flag is set
+func pingHandler(failing *atomic.Bool) sqltest.HandlerFn {
+	return func(_ context.Context, query string, _ []driver.NamedValue) (
+		*sqltest.Result,
+		error,
+	) {
+		if query == sqltest.PingStatement && failing.Load() {
+			return nil, errUnreachable
+		}
+		return &sqltest.Result{}, nil
+	}
+}
+
+func TestHealthCheckerCheck(t *testing.T) {
+	var failing atomic.Bool
+	db, _ := sqltest.Open(pingHandler(&failing))
+	defer db.Close()
+
+	// Record the state changes
+	var changes [][2]HealthState
+	checker, err := NewHealthChecker(
+		&DefaultHandler{config: &Config{}, db: db},
+		time.Hour,
+		time.Second,
+		func(previous, current HealthStatus) {
+			changes = append(changes, [2]HealthState{previous.State, current.State})
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewHealthChecker() error = %v", err)
+	}
+	if err = checker.Ready(); !errors.Is(err, ErrHealthUnknown) {
+		t.Fatalf("Ready() error = %v, want %v", err, ErrHealthUnknown)
+	}
+
+	tests := []struct {
+		name      string
+		failing   bool
+		wantState HealthState
+		wantErr   error
+	}{
+		{name: "healthy", wantState: HealthStateHealthy},
+		{name: "still healthy", wantState: HealthStateHealthy},
+		{name: "unhealthy", failing: true, wantState: HealthStateUnhealthy, wantErr: errUnreachable},
+		{name: "still unhealthy", failing: true, wantState: HealthStateUnhealthy, wantErr: errUnreachable},
+		{name: "healthy again", wantState: HealthStateHealthy},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				failing.Store(tt.failing)
+
+				status := checker.Check(context.Background())
+				if status.State != tt.wantState {
+					t.Errorf("Check() state = %s, want %s", status.State, tt.wantState)
+				}
+				if !errors.Is(checker.Ready(), tt.wantErr) {
+					t.Errorf("Ready() error = %v, want %v", checker.Ready(), tt.wantErr)
+				}
+				if tt.wantErr != nil && !errors.Is(status.Err, godatabases.ErrPingFailed) {
+					t.Errorf("Check() error = %v, want %v", status.Err, godatabases.ErrPingFailed)
+				}
+				if checker.IsHealthy() != (tt.wantState == HealthStateHealthy) {
+					t.Errorf("IsHealthy() = %v, want %v", checker.IsHealthy(), !checker.IsHealthy())
+				}
+			},
+		)
+	}
+
+	// Check that only the state changes were notified
+	want := [][2]HealthState{
+		{HealthStateUnknown, HealthStateHealthy},
+		{HealthStateHealthy, HealthStateUnhealthy},
+		{HealthStateUnhealthy, HealthStateHealthy},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("state changes = %v, want %v", changes, want)
+	}
+}
+
+func TestHealthCheckerStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		startFn func(t *testing.T, checker *HealthChecker) error
+		wantErr error
+	}{
+		{
+			name: "already started",
+			startFn: func(t *testing.T, checker *HealthChecker) error {
+				if err := checker.Start(context.Background()); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+				return checker.Start(context.Background())
+			},
+			wantErr: ErrHealthCheckerAlreadyStarted,
+		},
+		{
+			name: "started again once stopped",
+			startFn: func(t *testing.T, checker *HealthChecker) error {
+				if err := checker.Start(context.Background()); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+				checker.Stop()
+				return checker.Start(context.Background())
+			},
+		},
+		{
+			name: "started again once the context is done",
+			startFn: func(t *testing.T, checker *HealthChecker) error {
+				ctx, cancel := context.WithCancel(context.Background())
+				if err := checker.Start(ctx); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+				cancel()
+
+				// Wait for the health checks to stop
+				checker.mutex.Lock()
+				done := checker.done
+				checker.mutex.Unlock()
+				<-done
+				return checker.Start(context.Background())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, _ := sqltest.Open(nil)
+				defer db.Close()
+				checker, err := NewHealthChecker(
+					&DefaultHandler{config: &Config{}, db: db},
+					time.Millisecond,
+					time.Second,
+					nil,
+				)
+				if err != nil {
+					t.Fatalf("NewHealthChecker() error = %v", err)
+				}
+				defer checker.Stop()
+
+				if err = tt.startFn(t, checker); !errors.Is(err, tt.wantErr) {
+					t.Errorf("Start() error = %v, want %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestHealthCheckerStartChecks(t *testing.T) {
+	db, connector := sqltest.Open(nil)
+	defer db.Close()
+
+	// Notify the first health check
+	var once sync.Once
+	checked := make(chan struct{})
+	checker, err := NewHealthChecker(
+		&DefaultHandler{config: &Config{}, db: db},
+		time.Hour,
+		time.Second,
+		func(HealthStatus, HealthStatus) {
+			once.Do(
+				func() {
+					close(checked)
+				},
+			)
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewHealthChecker() error = %v", err)
+	}
+	if err = checker.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer checker.Stop()
+
+	// Check that the first health check runs immediately
+	select {
+	case <-checked:
+	case <-time.After(time.Second):
+		t.Fatalf("the first health check did not run")
+	}
+	if !slices.Contains(connector.Statements(), sqltest.PingStatement) {
+		t.Errorf("statements = %v, want a ping", connector.Statements())
+	}
+}
@@ -0,0 +1,254 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+const (
+	// OperationExec is the operation of the queries run through ExecWithCtx
+	OperationExec Operation = "exec"
+
+	// OperationQuery is the operation of the queries run through QueryWithCtx
+	OperationQuery Operation = "query"
+
+	// OperationQueryRow is the operation of the queries run through QueryRowWithCtx
+	OperationQueryRow Operation = "query_row"
+
+	// OperationTransaction is the operation of the transactions run through CreateTransaction
+	OperationTransaction Operation = "transaction"
+)
+
+const (
+	// TransactionCommitted is the outcome of the transactions that were committed
+	TransactionCommitted TransactionOutcome = "commit"
+
+	// TransactionRolledBack is the outcome of the transactions that were rolled back
+	TransactionRolledBack TransactionOutcome = "rollback"
+
+	// TransactionRetried is the outcome of the transaction attempts that were rolled back to be retried
+	TransactionRetried TransactionOutcome = "retry"
+)
+
+const (
+	// packagePrefix is the function name prefix of this package, used to find the caller location
+	packagePrefix = "github.com/ralvarezdev/go-databases/sql."
+)
+
+type (
+	// Operation represents the kind of operation reported to the hooks
+	Operation string
+
+	// TransactionOutcome represents how a transaction finished
+	TransactionOutcome string
+
+	// QueryEvent is the event reported to the hooks for each query or transaction
+	//
+	// Query and Args are empty for the transactions, and Outcome and Attempt are only set for them. Duration and
+	// Err are set once the operation finished. System is the database system, such as postgresql.
+	QueryEvent struct {
+		Operation Operation
+		System    string
+		Query     string
+		Args      []any
+		InTx      bool
+		StartedAt time.Time
+		Duration  time.Duration
+		Err       error
+		Outcome   TransactionOutcome
+		Attempt   int
+		span      trace.Span
+	}
+
+	// Hook is the interface for the interceptors called around each query and transaction of the service
+	//
+	// BeforeQuery is called before running the operation and returns the context used to run it, so it can
+	// carry values such as spans. AfterQuery is called once the operation finished. The hooks are called in
+	// the order they were added before the operation, and in reverse order after it.
+	Hook interface {
+		BeforeQuery(ctx context.Context, event *QueryEvent) context.Context
+		AfterQuery(ctx context.Context, event *QueryEvent)
+	}
+)
+
+// runHooks runs the function between the before and after hooks
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - hooks: the hooks to call
+//   - event: the event reported to the hooks
+//   - fn: the function to run, with the context returned by the hooks
+//
+// Returns:
+//
+//   - error: the error returned by the function
+func runHooks(
+	ctx context.Context,
+	hooks []Hook,
+	event *QueryEvent,
+	fn func(ctx context.Context) error,
+) error {
+	// Call the before hooks
+	ctx = beforeHooks(ctx, hooks, event)
+
+	// Run the function
+	err := fn(ctx)
+
+	// Call the after hooks
+	afterHooks(ctx, hooks, event, err)
+	return err
+}
+
+// createTransactionWithHooks creates a transaction for the database between the hooks
+//
+// Parameters:
+//
+//   - ctx: the context for the transaction
+//   - db: the database connection
+//   - fn: the function to execute within the transaction
+//   - opts: the transaction options
+//   - hooks: the hooks to call, the transaction is created without them if empty
+//   - attempt: the attempt number, starting at 1
+//   - policy: the retry policy used to tell the retried attempts apart, it can be nil
+//
+// Returns:
+//
+//   - error: an error if the transaction fails
+func createTransactionWithHooks(
+	ctx context.Context,
+	db *sql.DB,
+	fn TransactionWithCtxFn,
+	opts *sql.TxOptions,
+	hooks []Hook,
+	attempt int,
+	policy *RetryPolicy,
+) error {
+	// Check if there are hooks to call
+	if len(hooks) == 0 {
+		return createTransactionAttempt(ctx, db, fn, opts, attempt, policy)
+	}
+
+	// Call the before hooks
+	_, inTx := GetTxFromContext(ctx)
+	event := &QueryEvent{
+		Operation: OperationTransaction,
+		System:    telemetry.SQLSystem(db.Driver()),
+		InTx:      inTx,
+		Attempt:   attempt,
+	}
+	ctx = beforeHooks(ctx, hooks, event)
+
+	// Create the transaction
+	err := createTransactionAttempt(ctx, db, fn, opts, attempt, policy)
+
+	// Set the transaction outcome
+	switch {
+	case err == nil:
+		event.Outcome = TransactionCommitted
+	case policy.ShouldRetry(attempt, err):
+		event.Outcome = TransactionRetried
+	default:
+		event.Outcome = TransactionRolledBack
+	}
+
+	// Call the after hooks
+	afterHooks(ctx, hooks, event, err)
+	return err
+}
+
+// beforeHooks calls the before hooks, setting the event start time
+//
+// A span is started for the queries, while the transaction spans are started by CreateTransaction.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - hooks: the hooks to call
+//   - event: the event reported to the hooks
+//
+// Returns:
+//
+//   - context.Context: the context returned by the hooks
+func beforeHooks(
+	ctx context.Context,
+	hooks []Hook,
+	event *QueryEvent,
+) context.Context {
+	for _, hook := range hooks {
+		if hookCtx := hook.BeforeQuery(ctx, event); hookCtx != nil {
+			ctx = hookCtx
+		}
+	}
+
+	// Start the query span
+	if event.Operation != OperationTransaction {
+		ctx, event.span = telemetry.StartSpan(
+			ctx,
+			event.System,
+			telemetry.OperationName(event.Query),
+			event.Query,
+		)
+	}
+
+	event.StartedAt = time.Now()
+	return ctx
+}
+
+// afterHooks calls the after hooks in reverse order, setting the event error and its duration unless it was
+// already measured
+//
+// Parameters:
+//
+//   - ctx: the context returned by the before hooks
+//   - hooks: the hooks to call
+//   - event: the event reported to the hooks
+//   - err: the error returned by the operation
+func afterHooks(
+	ctx context.Context,
+	hooks []Hook,
+	event *QueryEvent,
+	err error,
+) {
+	if event.Duration == 0 {
+		event.Duration = time.Since(event.StartedAt)
+	}
+	event.Err = err
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].AfterQuery(ctx, event)
+	}
+
+	// End the query span
+	if event.span != nil {
+		telemetry.EndSpan(event.span, err)
+	}
+}
+
+// CallerLocation returns the location of the first caller outside this package
+//
+// Returns:
+//
+//   - string: the caller location as file:line, or an empty string if it could not be found
+func CallerLocation() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) &&
+			!strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+	gopgx "github.com/ralvarezdev/go-databases/sql/pgx"
+)
+
+type (
+	// recordingHook records the events reported after each operation
+	recordingHook struct {
+		mutex  sync.Mutex
+		events []QueryEvent
+	}
+)
+
+func (r *recordingHook) BeforeQuery(
+	ctx context.Context,
+	_ *QueryEvent,
+) context.Context {
+	return ctx
+}
+
+func (r *recordingHook) AfterQuery(_ context.Context, event *QueryEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, *event)
+}
+
+// newHookedService creates a service over a fake database with a recording hook
+func newHookedService(
+	t *testing.T,
+	handlerFn sqltest.HandlerFn,
+) (*DefaultService, *recordingHook) {
+	t.Helper()
+	service, _ := newTestService(t, handlerFn)
+	hook := &recordingHook{}
+	if err := service.AddHooks(hook); err != nil {
+		t.Fatalf("AddHooks() error = %v", err)
+	}
+	return service, hook
+}
+
+func TestQueryWithCtxHooksExcludeConsumerTime(t *testing.T) {
+	service, hook := newHookedService(
+		t,
+		rowsResult(
+			&sqltest.Result{
+				Columns: []string{"id"},
+				Rows:    [][]driver.Value{{int64(1)}, {int64(2)}},
+			},
+		),
+	)
+
+	query := "SELECT id FROM users"
+	rows, err := service.QueryWithCtx(context.Background(), &query)
+	if err != nil {
+		t.Fatalf("QueryWithCtx() error = %v", err)
+	}
+
+	// Spend some time in the consumer loop
+	consumerDelay := 50 * time.Millisecond
+	for _, rowErr := range rows {
+		if rowErr != nil {
+			t.Fatalf("row error = %v", rowErr)
+		}
+		time.Sleep(consumerDelay)
+	}
+
+	if len(hook.events) != 1 {
+		t.Fatalf("AfterQuery() called %d times, want 1", len(hook.events))
+	}
+	if duration := hook.events[0].Duration; duration <= 0 || duration >= consumerDelay {
+		t.Errorf("event duration = %v, want less than %v", duration, consumerDelay)
+	}
+}
+
+func TestQueryWithCtxHooksRunOnPanic(t *testing.T) {
+	service, hook := newHookedService(
+		t,
+		rowsResult(
+			&sqltest.Result{
+				Columns: []string{"id"},
+				Rows:    [][]driver.Value{{int64(1)}},
+			},
+		),
+	)
+
+	query := "SELECT id FROM users"
+	rows, err := service.QueryWithCtx(context.Background(), &query)
+	if err != nil {
+		t.Fatalf("QueryWithCtx() error = %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("the consumer panic was not propagated")
+			}
+		}()
+		for range rows {
+			panic("boom")
+		}
+	}()
+
+	if len(hook.events) != 1 {
+		t.Errorf("AfterQuery() called %d times, want 1", len(hook.events))
+	}
+}
+
+func TestCreateTransactionWithRetryHooks(t *testing.T) {
+	retryable := &pgconn.PgError{Code: gopgx.SerializationFailureCode}
+	service, hook := newHookedService(t, nil)
+
+	attempts := 0
+	err := service.CreateTransactionWithRetry(
+		context.Background(),
+		func(context.Context, *sql.Tx) error {
+			attempts++
+			if attempts < 3 {
+				return retryable
+			}
+			return nil
+		},
+		nil,
+		&RetryPolicy{MaxAttempts: 3, Multiplier: 1},
+	)
+	if err != nil {
+		t.Fatalf("CreateTransactionWithRetry() error = %v", err)
+	}
+
+	wantOutcomes := []TransactionOutcome{
+		TransactionRetried,
+		TransactionRetried,
+		TransactionCommitted,
+	}
+	if len(hook.events) != len(wantOutcomes) {
+		t.Fatalf("AfterQuery() called %d times, want %d", len(hook.events), len(wantOutcomes))
+	}
+	for i, event := range hook.events {
+		if event.Attempt != i+1 || event.Outcome != wantOutcomes[i] {
+			t.Errorf(
+				"event %d = attempt %d outcome %s, want attempt %d outcome %s",
+				i,
+				event.Attempt,
+				event.Outcome,
+				i+1,
+				wantOutcomes[i],
+			)
+		}
+	}
+
+	// Check that a permanent error is reported as rolled back without retries
+	errPermanent := errors.New("permanent")
+	hook.events = nil
+	err = service.CreateTransactionWithRetry(
+		context.Background(),
+		func(context.Context, *sql.Tx) error {
+			return errPermanent
+		},
+		nil,
+		nil,
+	)
+	if !errors.Is(err, errPermanent) {
+		t.Fatalf("CreateTransactionWithRetry() error = %v, want %v", err, errPermanent)
+	}
+	if len(hook.events) != 1 || hook.events[0].Outcome != TransactionRolledBack {
+		t.Errorf("events = %+v, want a single rolled back event", hook.events)
+	}
+}
@@ -3,9 +3,41 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"iter"
+	"time"
 )
 
 type (
+	// Executor is the interface implemented by both *sql.DB and *sql.Tx
+	Executor interface {
+		ExecContext(
+			ctx context.Context,
+			query string,
+			args ...any,
+		) (sql.Result, error)
+		QueryContext(
+			ctx context.Context,
+			query string,
+			args ...any,
+		) (*sql.Rows, error)
+		QueryRowContext(
+			ctx context.Context,
+			query string,
+			args ...any,
+		) *sql.Row
+	}
+
+	// DataSourceNameProvider is the interface for the providers of the data source name used to open each new
+	// physical connection, such as the ones backed by a secrets manager that rotates the credentials
+	DataSourceNameProvider interface {
+		DataSourceName(ctx context.Context) (string, error)
+	}
+
+	// Pinger is the interface for the connections that can be pinged
+	Pinger interface {
+		Ping(ctx context.Context) (time.Duration, error)
+	}
+
 	// Handler interface
 	Handler interface {
 		Connect() (*sql.DB, error)
@@ -14,6 +46,21 @@ type (
 		Disconnect() error
 	}
 
+	// ReadHandler is the interface for the handlers that route the reads to a different connection
+	ReadHandler interface {
+		ReadDB(ctx context.Context) (*sql.DB, error)
+	}
+
+	// StatsHandler is the interface for the handlers that report the statistics of their connection pool
+	StatsHandler interface {
+		Stats() (sql.DBStats, error)
+	}
+
+	// ReplicaStatsHandler is the interface for the handlers that report the statistics of their read replicas
+	ReplicaStatsHandler interface {
+		ReplicaStats() map[int]sql.DBStats
+	}
+
 	// Service is the interface for the service
 	Service interface {
 		Handler
@@ -22,6 +69,17 @@ type (
 			fn TransactionFn,
 			opts *sql.TxOptions,
 		) error
+		CreateTransactionWithCtx(
+			ctx context.Context,
+			fn TransactionWithCtxFn,
+			opts *sql.TxOptions,
+		) error
+		CreateTransactionWithRetry(
+			ctx context.Context,
+			fn TransactionWithCtxFn,
+			opts *sql.TxOptions,
+			policy *RetryPolicy,
+		) error
 		Exec(query *string, params ...any) (sql.Result, error)
 		ExecWithCtx(
 			ctx context.Context,
@@ -34,6 +92,15 @@ type (
 			query *string,
 			params ...any,
 		) (*sql.Row, error)
+		Query(query *string, params ...any) (
+			iter.Seq2[*sql.Rows, error],
+			error,
+		)
+		QueryWithCtx(
+			ctx context.Context,
+			query *string,
+			params ...any,
+		) (iter.Seq2[*sql.Rows, error], error)
 		ScanRow(row *sql.Row, destinations ...any) error
 	}
 )
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilMigrator       = errors.New("migrator cannot be nil")
+	ErrNilFileSystem     = errors.New("migrations file system cannot be nil")
+	ErrInvalidDownSteps  = errors.New("down steps must be greater than zero")
+	ErrNilMigration      = errors.New("migration cannot be nil")
+	ErrTooFewConnections = errors.New("migrator needs at least two open connections, one holds the advisory lock")
+)
+
+const (
+	ErrDuplicatedMigration  = "migration %d is defined more than once"
+	ErrMissingUpMigration   = "migration %d has no up migration"
+	ErrMissingDownMigration = "migration %d has no down migration"
+	ErrUnknownMigration     = "migration %d is not defined"
+	ErrChecksumMismatch     = "migration %d checksum mismatch: applied %s, defined %s"
+	ErrMigrationFailed      = "migration %d failed: %w"
+	ErrInvalidMigrationFile = "invalid migration file '%s': %w"
+	ErrDirtyMigration       = "migration %d is dirty, fix the database and force its version"
+)
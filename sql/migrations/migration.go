@@ -0,0 +1,301 @@
+package migrations
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// UpSuffix is the suffix of the up migration files
+	UpSuffix = "up"
+
+	// DownSuffix is the suffix of the down migration files
+	DownSuffix = "down"
+
+	// GoChecksumPrefix is the prefix of the content hashed into the checksum of the Go migrations
+	GoChecksumPrefix = "go:"
+)
+
+var (
+	// fileNameRegexp matches the migration file names, such as '0001_create_users.up.sql'
+	fileNameRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+)
+
+type (
+	// TxMigrationFn is the function type of the Go migrations run within a transaction
+	TxMigrationFn func(ctx context.Context, tx *sql.Tx) error
+
+	// DBMigrationFn is the function type of the Go migrations run outside a transaction, such as the ones
+	// creating indexes concurrently
+	DBMigrationFn func(ctx context.Context, db *sql.DB) error
+
+	// Migration is a versioned schema migration, defined by SQL files or by Go functions
+	//
+	// Checksum is the SHA-256 of the up migration, recorded when it is applied to detect the migrations that
+	// were modified afterwards. The code of the Go migrations cannot be hashed, so their checksum is the SHA-256
+	// of GoChecksumPrefix followed by their name, and only renaming them is detected.
+	Migration struct {
+		Version  int64
+		Name     string
+		UpSQL    string
+		DownSQL  string
+		Checksum string
+		hasUp    bool
+		hasDown  bool
+		upTxFn   TxMigrationFn
+		downTxFn TxMigrationFn
+		upDBFn   DBMigrationFn
+		downDBFn DBMigrationFn
+	}
+)
+
+// NewGoMigration creates a migration run within a transaction by Go functions
+//
+// Its checksum is the SHA-256 of GoChecksumPrefix followed by its name, so changing the functions after the
+// migration was applied is not detected, and a new migration must be added instead.
+//
+// Parameters:
+//
+//   - version: the migration version
+//   - name: the migration name
+//   - up: the function that applies the migration
+//   - down: the function that rolls back the migration, it can be nil
+//
+// Returns:
+//
+//   - *Migration: the migration
+//   - error: if the up function is nil
+func NewGoMigration(
+	version int64,
+	name string,
+	up, down TxMigrationFn,
+) (*Migration, error) {
+	// Check if the up function is nil
+	if up == nil {
+		return nil, fmt.Errorf(ErrMissingUpMigration, version)
+	}
+
+	return &Migration{
+		Version:  version,
+		Name:     name,
+		Checksum: checksum(GoChecksumPrefix + name),
+		hasUp:    true,
+		hasDown:  down != nil,
+		upTxFn:   up,
+		downTxFn: down,
+	}, nil
+}
+
+// NewNonTransactionalGoMigration creates a migration run outside a transaction by Go functions
+//
+// If the function fails, the migration is recorded as dirty, since its changes may be partially applied. Its
+// checksum is computed as done by NewGoMigration.
+//
+// Parameters:
+//
+//   - version: the migration version
+//   - name: the migration name
+//   - up: the function that applies the migration
+//   - down: the function that rolls back the migration, it can be nil
+//
+// Returns:
+//
+//   - *Migration: the migration
+//   - error: if the up function is nil
+func NewNonTransactionalGoMigration(
+	version int64,
+	name string,
+	up, down DBMigrationFn,
+) (*Migration, error) {
+	// Check if the up function is nil
+	if up == nil {
+		return nil, fmt.Errorf(ErrMissingUpMigration, version)
+	}
+
+	return &Migration{
+		Version:  version,
+		Name:     name,
+		Checksum: checksum(GoChecksumPrefix + name),
+		hasUp:    true,
+		hasDown:  down != nil,
+		upDBFn:   up,
+		downDBFn: down,
+	}, nil
+}
+
+// IsTransactional checks if the migration is run within a transaction
+//
+// Returns:
+//
+//   - bool: true if the migration is run within a transaction, false otherwise
+func (m *Migration) IsTransactional() bool {
+	return m != nil && m.upDBFn == nil
+}
+
+// upTx applies the migration within the transaction
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - tx: the transaction
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (m *Migration) upTx(ctx context.Context, tx *sql.Tx) error {
+	if m.upTxFn != nil {
+		return m.upTxFn(ctx, tx)
+	}
+	return exec(ctx, tx, m.UpSQL)
+}
+
+// downTx rolls back the migration within the transaction
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - tx: the transaction
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (m *Migration) downTx(ctx context.Context, tx *sql.Tx) error {
+	if m.downTxFn != nil {
+		return m.downTxFn(ctx, tx)
+	}
+	return exec(ctx, tx, m.DownSQL)
+}
+
+// exec executes the SQL of a migration within the transaction, skipping it if it is empty
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - tx: the transaction
+//   - query: the SQL of the migration
+//
+// Returns:
+//
+//   - error: if any error occurs
+func exec(ctx context.Context, tx *sql.Tx, query string) error {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// HasDown checks if the migration can be rolled back
+//
+// Returns:
+//
+//   - bool: true if the migration has a down migration, false otherwise
+func (m *Migration) HasDown() bool {
+	return m != nil && m.hasDown
+}
+
+// checksum returns the SHA-256 of the given content
+//
+// Parameters:
+//
+//   - content: the content to hash
+//
+// Returns:
+//
+//   - string: the hex encoded hash
+func checksum(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
+
+// LoadMigrations reads the migrations from the root directory of the file system
+//
+// The files are named '<version>_<name>.up.sql' and '<version>_<name>.down.sql', and the other files are
+// ignored. Use fs.Sub to read them from a subdirectory of an embedded file system.
+//
+// Parameters:
+//
+//   - fileSystem: the file system holding the migration files
+//
+// Returns:
+//
+//   - []*Migration: the migrations sorted by version
+//   - error: if the files could not be read, or a version is duplicated or has no up migration
+func LoadMigrations(fileSystem fs.FS) ([]*Migration, error) {
+	// Check if the file system is nil
+	if fileSystem == nil {
+		return nil, ErrNilFileSystem
+	}
+
+	// Read the migration files
+	entries, err := fs.ReadDir(fileSystem, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		// Check if the file is a migration file
+		matches := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		version, parseErr := strconv.ParseInt(matches[1], 10, 64)
+		if parseErr != nil {
+			return nil, fmt.Errorf(ErrInvalidMigrationFile, entry.Name(), parseErr)
+		}
+
+		// Read the file
+		content, readErr := fs.ReadFile(fileSystem, entry.Name())
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		// Get the migration of the version
+		migration, ok := migrationsByVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			migrationsByVersion[version] = migration
+		}
+
+		// Set the up or down migration
+		if matches[3] == UpSuffix {
+			if migration.hasUp {
+				return nil, fmt.Errorf(ErrDuplicatedMigration, version)
+			}
+			migration.UpSQL = string(content)
+			migration.Checksum = checksum(migration.UpSQL)
+			migration.hasUp = true
+		} else {
+			if migration.hasDown {
+				return nil, fmt.Errorf(ErrDuplicatedMigration, version)
+			}
+			migration.DownSQL = string(content)
+			migration.hasDown = true
+		}
+	}
+
+	// Check the migrations and sort them by version
+	migrations := make([]*Migration, 0, len(migrationsByVersion))
+	for _, migration := range migrationsByVersion {
+		if !migration.hasUp {
+			return nil, fmt.Errorf(ErrMissingUpMigration, migration.Version)
+		}
+		migrations = append(migrations, migration)
+	}
+	slices.SortFunc(
+		migrations, func(a, b *Migration) int {
+			return cmp.Compare(a.Version, b.Version)
+		},
+	)
+	return migrations, nil
+}
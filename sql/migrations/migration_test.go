@@ -0,0 +1,168 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileSystem   fstest.MapFS
+		wantVersions []int64
+		wantDown     []bool
+		wantErr      bool
+		errIs        error
+	}{
+		{
+			name: "sorted by version",
+			fileSystem: fstest.MapFS{
+				"0002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD email TEXT")},
+				"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id BIGINT)")},
+				"0001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+				"README.md":                  {Data: []byte("migrations")},
+				"0003_ignored/up.sql":        {Data: []byte("SELECT 1")},
+			},
+			wantVersions: []int64{1, 2},
+			wantDown:     []bool{true, false},
+		},
+		{name: "empty directory", fileSystem: fstest.MapFS{}},
+		{
+			name: "missing up migration",
+			fileSystem: fstest.MapFS{
+				"0001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicated version",
+			fileSystem: fstest.MapFS{
+				"0001_create_users.up.sql":    {Data: []byte("CREATE TABLE users (id BIGINT)")},
+				"0001_create_accounts.up.sql": {Data: []byte("CREATE TABLE accounts (id BIGINT)")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "version out of range",
+			fileSystem: fstest.MapFS{
+				"99999999999999999999_create_users.up.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{name: "nil file system", wantErr: true, errIs: ErrNilFileSystem},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var migrations []*Migration
+				var err error
+				if tt.fileSystem == nil {
+					migrations, err = LoadMigrations(nil)
+				} else {
+					migrations, err = LoadMigrations(tt.fileSystem)
+				}
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("LoadMigrations() error = %v, want error %v", err, tt.wantErr)
+				}
+				if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+					t.Fatalf("LoadMigrations() error = %v, want %v", err, tt.errIs)
+				}
+				if err != nil {
+					return
+				}
+
+				// Check the versions, the down migrations and the checksums
+				if len(migrations) != len(tt.wantVersions) {
+					t.Fatalf("LoadMigrations() = %d migrations, want %d", len(migrations), len(tt.wantVersions))
+				}
+				for i, migration := range migrations {
+					if migration.Version != tt.wantVersions[i] {
+						t.Errorf("migration %d version = %d, want %d", i, migration.Version, tt.wantVersions[i])
+					}
+					if migration.HasDown() != tt.wantDown[i] {
+						t.Errorf("migration %d HasDown() = %v, want %v", i, migration.HasDown(), tt.wantDown[i])
+					}
+					if !migration.IsTransactional() {
+						t.Errorf("migration %d is not transactional", i)
+					}
+					if migration.Checksum != checksum(migration.UpSQL) {
+						t.Errorf("migration %d checksum = %s, want the up migration one", i, migration.Checksum)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestNewGoMigration(t *testing.T) {
+	upTxFn := func(context.Context, *sql.Tx) error {
+		return nil
+	}
+	upDBFn := func(context.Context, *sql.DB) error {
+		return nil
+	}
+
+	tests := []struct {
+		name              string
+		newFn             func() (*Migration, error)
+		wantTransactional bool
+		wantDown          bool
+		wantErr           bool
+	}{
+		{
+			name: "transactional migration",
+			newFn: func() (*Migration, error) {
+				return NewGoMigration(1, "backfill_users", upTxFn, upTxFn)
+			},
+			wantTransactional: true,
+			wantDown:          true,
+		},
+		{
+			name: "non-transactional migration",
+			newFn: func() (*Migration, error) {
+				return NewNonTransactionalGoMigration(1, "backfill_users", upDBFn, nil)
+			},
+		},
+		{
+			name: "transactional migration without up function",
+			newFn: func() (*Migration, error) {
+				return NewGoMigration(1, "backfill_users", nil, upTxFn)
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-transactional migration without up function",
+			newFn: func() (*Migration, error) {
+				return NewNonTransactionalGoMigration(1, "backfill_users", nil, upDBFn)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				migration, err := tt.newFn()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				if migration.IsTransactional() != tt.wantTransactional {
+					t.Errorf("IsTransactional() = %v, want %v", migration.IsTransactional(), tt.wantTransactional)
+				}
+				if migration.HasDown() != tt.wantDown {
+					t.Errorf("HasDown() = %v, want %v", migration.HasDown(), tt.wantDown)
+				}
+
+				// Check that the checksum is the documented one
+				if want := checksum(GoChecksumPrefix + "backfill_users"); migration.Checksum != want {
+					t.Errorf("Checksum = %s, want %s", migration.Checksum, want)
+				}
+			},
+		)
+	}
+}
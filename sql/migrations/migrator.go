@@ -0,0 +1,850 @@
+package migrations
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+const (
+	// DefaultTableName is the default name of the schema migrations table
+	DefaultTableName = "schema_migrations"
+
+	// LockKeyPrefix is the prefix of the name hashed into the advisory lock key of the migrators
+	LockKeyPrefix = "migrations:"
+
+	// MinOpenConnections is the minimum number of open connections of the pool used by the migrators, since
+	// the advisory lock is held on a dedicated connection while the migrations run on the others
+	MinOpenConnections = 2
+)
+
+type (
+	// MigrationStatus is the status of a migration
+	//
+	// Modified is true if the migration was modified after being applied, Missing is true if the migration
+	// was applied but it is no longer defined, and Dirty is true if a non-transactional step of the migration
+	// failed, leaving it partially applied.
+	MigrationStatus struct {
+		Version   int64
+		Name      string
+		Applied   bool
+		AppliedAt time.Time
+		Modified  bool
+		Missing   bool
+		Dirty     bool
+	}
+
+	// appliedMigration is a migration recorded in the schema migrations table
+	appliedMigration struct {
+		version   int64
+		name      string
+		checksum  string
+		appliedAt time.Time
+		dirty     bool
+	}
+
+	// Migrator applies and rolls back the migrations of a PostgreSQL database
+	//
+	// Each transactional migration is run within a transaction together with the update of the schema
+	// migrations table, and the migrators hold an advisory lock while running, so concurrent migrators wait for
+	// each other. The non-transactional migrations are recorded as dirty while they run, so a failure blocks
+	// the migrator until the database is fixed and its version is forced.
+	//
+	// The advisory lock is held on a dedicated connection of the pool while the migrations run on the others, so
+	// the pool must allow at least MinOpenConnections open connections.
+	Migrator struct {
+		db         *sql.DB
+		migrations []*Migration
+		table      string
+		lockKey    gosql.AdvisoryLockKey
+	}
+)
+
+// NewMigrator creates a new migrator with the migrations read from the root directory of the file system
+//
+// Parameters:
+//
+//   - db: the database connection, whose pool must allow at least MinOpenConnections open connections
+//   - fileSystem: the file system holding the migration files, such as an embed.FS, it can be nil if every
+//     migration is a Go migration
+//   - table: the name of the schema migrations table, optionally qualified with its schema, DefaultTableName is
+//     used if it is empty
+//
+// Returns:
+//
+//   - *Migrator: the migrator
+//   - error: if the database connection is nil or the migrations could not be read
+func NewMigrator(
+	db *sql.DB,
+	fileSystem fs.FS,
+	table string,
+) (*Migrator, error) {
+	// Check if the database connection is nil
+	if db == nil {
+		return nil, godatabases.ErrNilConnection
+	}
+
+	// Read the migrations, if there is a file system
+	var migrations []*Migration
+	if fileSystem != nil {
+		var err error
+		if migrations, err = LoadMigrations(fileSystem); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set the default table name
+	if table == "" {
+		table = DefaultTableName
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		table:      pgx.Identifier(strings.Split(table, ".")).Sanitize(),
+		lockKey:    gosql.NewAdvisoryLockKey(LockKeyPrefix + table),
+	}, nil
+}
+
+// Register adds Go migrations, interleaved with the SQL migrations by version
+//
+// The migrations must be registered before the migrator is used.
+//
+// Parameters:
+//
+//   - migrations: the migrations to register, created with NewGoMigration or NewNonTransactionalGoMigration
+//
+// Returns:
+//
+//   - error: if a migration is nil or its version is already defined
+func (m *Migrator) Register(migrations ...*Migration) error {
+	if m == nil {
+		return ErrNilMigrator
+	}
+
+	for _, migration := range migrations {
+		// Check if the migration is nil
+		if migration == nil {
+			return ErrNilMigration
+		}
+
+		// Check if the version is already defined
+		index, found := slices.BinarySearchFunc(
+			m.migrations,
+			migration.Version,
+			compareMigrationVersion,
+		)
+		if found {
+			return fmt.Errorf(ErrDuplicatedMigration, migration.Version)
+		}
+		m.migrations = slices.Insert(m.migrations, index, migration)
+	}
+	return nil
+}
+
+// compareMigrationVersion compares the version of a migration with the given version
+//
+// Parameters:
+//
+//   - migration: the migration
+//   - version: the version to compare with
+//
+// Returns:
+//
+//   - int: the comparison result, as returned by cmp.Compare
+func compareMigrationVersion(migration *Migration, version int64) int {
+	return cmp.Compare(migration.Version, version)
+}
+
+// Migrations returns the defined migrations sorted by version
+//
+// Returns:
+//
+//   - []*Migration: the defined migrations
+func (m *Migrator) Migrations() []*Migration {
+	if m == nil {
+		return nil
+	}
+	return m.migrations
+}
+
+// ensureTable creates the schema migrations table if it does not exist
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	checksum TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	dirty BOOLEAN NOT NULL DEFAULT false
+)`,
+			m.table,
+		),
+	)
+	return err
+}
+
+// applied returns the migrations recorded in the schema migrations table
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - map[int64]*appliedMigration: the applied migrations by version
+//   - error: if any error occurs
+func (m *Migrator) applied(ctx context.Context) (
+	map[int64]*appliedMigration,
+	error,
+) {
+	rows, err := m.db.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			"SELECT version, name, checksum, applied_at, dirty FROM %s",
+			m.table,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Scan the applied migrations
+	applied := make(map[int64]*appliedMigration)
+	for rows.Next() {
+		migration := &appliedMigration{}
+		if err = rows.Scan(
+			&migration.version,
+			&migration.name,
+			&migration.checksum,
+			&migration.appliedAt,
+			&migration.dirty,
+		); err != nil {
+			return nil, err
+		}
+		applied[migration.version] = migration
+	}
+	return applied, rows.Err()
+}
+
+// tableExists checks if the schema migrations table exists
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - bool: true if the table exists, false otherwise
+//   - error: if any error occurs
+func (m *Migrator) tableExists(ctx context.Context) (bool, error) {
+	var exists bool
+	err := m.db.QueryRowContext(
+		ctx,
+		"SELECT to_regclass($1) IS NOT NULL",
+		m.table,
+	).Scan(&exists)
+	return exists, err
+}
+
+// run runs the function while holding the migrations advisory lock, with the applied migrations
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - fn: the function to run
+//
+// Returns:
+//
+//   - error: if the pool allows fewer than MinOpenConnections open connections, or any other error occurs
+func (m *Migrator) run(
+	ctx context.Context,
+	fn func(ctx context.Context, applied map[int64]*appliedMigration) error,
+) error {
+	if m == nil {
+		return ErrNilMigrator
+	}
+
+	// Check the pool size, since waiting for a second connection while holding the lock would never end
+	if maxOpen := m.db.Stats().MaxOpenConnections; maxOpen > 0 && maxOpen < MinOpenConnections {
+		return ErrTooFewConnections
+	}
+
+	return gosql.WithLock(
+		ctx, m.db, m.lockKey, func(ctx context.Context) error {
+			// Create the schema migrations table
+			if err := m.ensureTable(ctx); err != nil {
+				return err
+			}
+
+			// Get the applied migrations
+			applied, err := m.applied(ctx)
+			if err != nil {
+				return err
+			}
+			return fn(ctx, applied)
+		},
+	)
+}
+
+// verify checks that the applied migrations are not dirty and were not modified
+//
+// Parameters:
+//
+//   - applied: the applied migrations by version
+//
+// Returns:
+//
+//   - error: if an applied migration is dirty, or its checksum does not match its definition
+func (m *Migrator) verify(applied map[int64]*appliedMigration) error {
+	// Check if any applied migration is dirty
+	if err := checkDirty(applied); err != nil {
+		return err
+	}
+
+	// Check the checksums
+	for _, migration := range m.migrations {
+		appliedMigration, ok := applied[migration.Version]
+		if ok && appliedMigration.checksum != migration.Checksum {
+			return fmt.Errorf(
+				ErrChecksumMismatch,
+				migration.Version,
+				appliedMigration.checksum,
+				migration.Checksum,
+			)
+		}
+	}
+	return nil
+}
+
+// checkDirty checks that none of the applied migrations is dirty
+//
+// Parameters:
+//
+//   - applied: the applied migrations by version
+//
+// Returns:
+//
+//   - error: if an applied migration is dirty
+func checkDirty(applied map[int64]*appliedMigration) error {
+	for _, version := range appliedVersions(applied) {
+		if applied[version].dirty {
+			return fmt.Errorf(ErrDirtyMigration, version)
+		}
+	}
+	return nil
+}
+
+// find returns the defined migration of the given version
+//
+// Parameters:
+//
+//   - version: the migration version
+//
+// Returns:
+//
+//   - *Migration: the migration, nil if it is not defined
+func (m *Migrator) find(version int64) *Migration {
+	index, ok := slices.BinarySearchFunc(
+		m.migrations,
+		version,
+		compareMigrationVersion,
+	)
+	if !ok {
+		return nil
+	}
+	return m.migrations[index]
+}
+
+// apply applies a migration and records it, within a transaction if the migration is transactional
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - migration: the migration to apply
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (m *Migrator) apply(ctx context.Context, migration *Migration) error {
+	var err error
+	if migration.IsTransactional() {
+		err = gosql.CreateTransactionWithCtx(
+			ctx, m.db, func(ctx context.Context, tx *sql.Tx) error {
+				// Run the up migration
+				if txErr := migration.upTx(ctx, tx); txErr != nil {
+					return txErr
+				}
+
+				// Record the migration
+				_, txErr := tx.ExecContext(
+					ctx,
+					fmt.Sprintf(
+						"INSERT INTO %s (version, name, checksum) VALUES ($1, $2, $3)",
+						m.table,
+					),
+					migration.Version,
+					migration.Name,
+					migration.Checksum,
+				)
+				return txErr
+			}, nil,
+		)
+	} else {
+		err = m.applyNonTransactional(ctx, migration)
+	}
+	if err != nil {
+		return fmt.Errorf(ErrMigrationFailed, migration.Version, err)
+	}
+	return nil
+}
+
+// applyNonTransactional applies a non-transactional migration, recording it as dirty until it succeeds
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - migration: the migration to apply
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (m *Migrator) applyNonTransactional(
+	ctx context.Context,
+	migration *Migration,
+) error {
+	// Record the migration as dirty
+	if _, err := m.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			"INSERT INTO %s (version, name, checksum, dirty) VALUES ($1, $2, $3, true)",
+			m.table,
+		),
+		migration.Version,
+		migration.Name,
+		migration.Checksum,
+	); err != nil {
+		return err
+	}
+
+	// Run the up migration
+	if err := migration.upDBFn(ctx, m.db); err != nil {
+		return err
+	}
+
+	// Record the migration as clean
+	return m.setDirty(ctx, migration.Version, false)
+}
+
+// setDirty sets the dirty flag of an applied migration
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - version: the migration version
+//   - dirty: the dirty flag
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (m *Migrator) setDirty(
+	ctx context.Context,
+	version int64,
+	dirty bool,
+) error {
+	_, err := m.db.ExecContext(
+		ctx,
+		fmt.Sprintf("UPDATE %s SET dirty = $2 WHERE version = $1", m.table),
+		version,
+		dirty,
+	)
+	return err
+}
+
+// rollback rolls back a migration and removes its record, within a transaction if the migration is
+// transactional
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - version: the version of the migration to roll back
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (m *Migrator) rollback(ctx context.Context, version int64) error {
+	// Get the migration
+	migration := m.find(version)
+	if migration == nil {
+		return fmt.Errorf(ErrUnknownMigration, version)
+	}
+	if !migration.HasDown() {
+		return fmt.Errorf(ErrMissingDownMigration, version)
+	}
+
+	var err error
+	if migration.IsTransactional() {
+		err = gosql.CreateTransactionWithCtx(
+			ctx, m.db, func(ctx context.Context, tx *sql.Tx) error {
+				// Run the down migration
+				if txErr := migration.downTx(ctx, tx); txErr != nil {
+					return txErr
+				}
+
+				// Remove the migration record
+				_, txErr := tx.ExecContext(
+					ctx,
+					fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.table),
+					migration.Version,
+				)
+				return txErr
+			}, nil,
+		)
+	} else {
+		err = m.rollbackNonTransactional(ctx, migration)
+	}
+	if err != nil {
+		return fmt.Errorf(ErrMigrationFailed, migration.Version, err)
+	}
+	return nil
+}
+
+// rollbackNonTransactional rolls back a non-transactional migration, recording it as dirty until it succeeds
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - migration: the migration to roll back
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (m *Migrator) rollbackNonTransactional(
+	ctx context.Context,
+	migration *Migration,
+) error {
+	// Record the migration as dirty
+	if err := m.setDirty(ctx, migration.Version, true); err != nil {
+		return err
+	}
+
+	// Run the down migration
+	if err := migration.downDBFn(ctx, m.db); err != nil {
+		return err
+	}
+
+	// Remove the migration record
+	_, err := m.db.ExecContext(
+		ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.table),
+		migration.Version,
+	)
+	return err
+}
+
+// appliedVersions returns the applied versions in descending order
+//
+// Parameters:
+//
+//   - applied: the applied migrations by version
+//
+// Returns:
+//
+//   - []int64: the applied versions in descending order
+func appliedVersions(applied map[int64]*appliedMigration) []int64 {
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	slices.Reverse(versions)
+	return versions
+}
+
+// Up applies every pending migration in version order
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - error: if an applied migration was modified or a migration failed
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.run(
+		ctx, func(ctx context.Context, applied map[int64]*appliedMigration) error {
+			// Check the applied migrations
+			if err := m.verify(applied); err != nil {
+				return err
+			}
+
+			// Apply the pending migrations
+			for _, migration := range m.migrations {
+				if _, ok := applied[migration.Version]; ok {
+					continue
+				}
+				if err := m.apply(ctx, migration); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// Down rolls back the last applied migrations
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - n: the number of migrations to roll back
+//
+// Returns:
+//
+//   - error: if a migration has no down migration or failed
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	// Check the number of steps
+	if n <= 0 {
+		return ErrInvalidDownSteps
+	}
+
+	return m.run(
+		ctx, func(ctx context.Context, applied map[int64]*appliedMigration) error {
+			// Check if any applied migration is dirty
+			if err := checkDirty(applied); err != nil {
+				return err
+			}
+
+			// Roll back the last applied migrations
+			for i, version := range appliedVersions(applied) {
+				if i >= n {
+					break
+				}
+				if err := m.rollback(ctx, version); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// Goto migrates the database to the given version, applying the pending migrations up to it and rolling back
+// the applied migrations after it
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - version: the target version, 0 to roll back every migration
+//
+// Returns:
+//
+//   - error: if the version is not defined, an applied migration was modified or a migration failed
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if m == nil {
+		return ErrNilMigrator
+	}
+
+	// Check if the version is defined
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf(ErrUnknownMigration, version)
+	}
+
+	return m.run(
+		ctx, func(ctx context.Context, applied map[int64]*appliedMigration) error {
+			// Check the applied migrations
+			if err := m.verify(applied); err != nil {
+				return err
+			}
+
+			// Roll back the applied migrations after the version
+			for _, appliedVersion := range appliedVersions(applied) {
+				if appliedVersion <= version {
+					break
+				}
+				if err := m.rollback(ctx, appliedVersion); err != nil {
+					return err
+				}
+			}
+
+			// Apply the pending migrations up to the version
+			for _, migration := range m.migrations {
+				if migration.Version > version {
+					break
+				}
+				if _, ok := applied[migration.Version]; ok {
+					continue
+				}
+				if err := m.apply(ctx, migration); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// Status returns the status of the defined migrations and of the applied migrations no longer defined
+//
+// The status is read without holding the advisory lock nor creating the schema migrations table, so every
+// migration is reported as pending if the table does not exist yet.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - []*MigrationStatus: the migration statuses sorted by version
+//   - error: if any error occurs
+func (m *Migrator) Status(ctx context.Context) ([]*MigrationStatus, error) {
+	if m == nil {
+		return nil, ErrNilMigrator
+	}
+
+	// Get the applied migrations, if the schema migrations table exists
+	exists, err := m.tableExists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]*appliedMigration)
+	if exists {
+		if applied, err = m.applied(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get the status of the defined migrations
+	statuses := make([]*MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if appliedMigration, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = appliedMigration.appliedAt
+			status.Modified = appliedMigration.checksum != migration.Checksum
+			status.Dirty = appliedMigration.dirty
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Get the status of the applied migrations no longer defined
+	for _, appliedMigration := range applied {
+		if m.find(appliedMigration.version) != nil {
+			continue
+		}
+		statuses = append(
+			statuses, &MigrationStatus{
+				Version:   appliedMigration.version,
+				Name:      appliedMigration.name,
+				Applied:   true,
+				AppliedAt: appliedMigration.appliedAt,
+				Missing:   true,
+				Dirty:     appliedMigration.dirty,
+			},
+		)
+	}
+
+	// Sort the statuses by version
+	slices.SortFunc(
+		statuses, func(a, b *MigrationStatus) int {
+			return cmp.Compare(a.Version, b.Version)
+		},
+	)
+	return statuses, nil
+}
+
+// Force records the database as migrated to the given version without running any migration, clearing the
+// dirty flags
+//
+// It is used to recover from a failed non-transactional migration once the database was fixed by hand. The
+// records of the migrations after the version are removed, and the defined migrations up to the version that
+// are not recorded are recorded as applied.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - version: the version to record, 0 to remove every record
+//
+// Returns:
+//
+//   - error: if the version is not defined or the records could not be updated
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if m == nil {
+		return ErrNilMigrator
+	}
+
+	// Check if the version is defined
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf(ErrUnknownMigration, version)
+	}
+
+	return m.run(
+		ctx, func(ctx context.Context, applied map[int64]*appliedMigration) error {
+			return gosql.CreateTransactionWithCtx(
+				ctx, m.db, func(ctx context.Context, tx *sql.Tx) error {
+					// Remove the records after the version and clear the dirty flags
+					if _, err := tx.ExecContext(
+						ctx,
+						fmt.Sprintf("DELETE FROM %s WHERE version > $1", m.table),
+						version,
+					); err != nil {
+						return err
+					}
+					if _, err := tx.ExecContext(
+						ctx,
+						fmt.Sprintf("UPDATE %s SET dirty = false WHERE dirty", m.table),
+					); err != nil {
+						return err
+					}
+
+					// Record the migrations up to the version
+					for _, migration := range m.migrations {
+						if migration.Version > version {
+							break
+						}
+						if _, ok := applied[migration.Version]; ok {
+							continue
+						}
+						if _, err := tx.ExecContext(
+							ctx,
+							fmt.Sprintf(
+								"INSERT INTO %s (version, name, checksum) VALUES ($1, $2, $3)",
+								m.table,
+							),
+							migration.Version,
+							migration.Name,
+							migration.Checksum,
+						); err != nil {
+							return err
+						}
+					}
+					return nil
+				}, nil,
+			)
+		},
+	)
+}
@@ -0,0 +1,672 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+const (
+	// createUsersSQL is the up migration of the first test migration
+	createUsersSQL = "CREATE TABLE users (id BIGINT)"
+
+	// dropUsersSQL is the down migration of the first test migration
+	dropUsersSQL = "DROP TABLE users"
+
+	// addEmailSQL is the up migration of the second test migration
+	addEmailSQL = "ALTER TABLE users ADD email TEXT"
+)
+
+var (
+	// testFileSystem holds the test migrations
+	testFileSystem = fstest.MapFS{
+		"0001_create_users.up.sql":   {Data: []byte(createUsersSQL)},
+		"0001_create_users.down.sql": {Data: []byte(dropUsersSQL)},
+		"0002_add_email.up.sql":      {Data: []byte(addEmailSQL)},
+	}
+)
+
+// migratorHandler answers the statements of the migrator, with the schema migrations table holding the given
+// applied migrations if it exists
+func migratorHandler(
+	tableExists bool,
+	applied []*appliedMigration,
+) sqltest.HandlerFn {
+	return func(_ context.Context, query string, _ []driver.NamedValue) (
+		*sqltest.Result,
+		error,
+	) {
+		switch {
+		case strings.HasPrefix(query, "SELECT pg_advisory_unlock"):
+			return &sqltest.Result{
+				Columns: []string{"released"},
+				Rows:    [][]driver.Value{{true}},
+			}, nil
+		case strings.HasPrefix(query, "SELECT to_regclass"):
+			return &sqltest.Result{
+				Columns: []string{"exists"},
+				Rows:    [][]driver.Value{{tableExists}},
+			}, nil
+		case strings.HasPrefix(query, "SELECT version"):
+			result := &sqltest.Result{
+				Columns: []string{"version", "name", "checksum", "applied_at", "dirty"},
+			}
+			for _, migration := range applied {
+				result.Rows = append(
+					result.Rows, []driver.Value{
+						migration.version,
+						migration.name,
+						migration.checksum,
+						migration.appliedAt,
+						migration.dirty,
+					},
+				)
+			}
+			return result, nil
+		}
+		return &sqltest.Result{RowsAffected: 1}, nil
+	}
+}
+
+// newTestMigrator creates a migrator of the test migrations
+func newTestMigrator(
+	t *testing.T,
+	handlerFn sqltest.HandlerFn,
+	maxOpenConnections int,
+) (*Migrator, *sqltest.Connector) {
+	t.Helper()
+	db, connector := sqltest.Open(handlerFn)
+	t.Cleanup(
+		func() {
+			_ = db.Close()
+		},
+	)
+	db.SetMaxOpenConns(maxOpenConnections)
+
+	migrator, err := NewMigrator(db, testFileSystem, "")
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	return migrator, connector
+}
+
+// runMigrations returns the up and down migrations run through the given statements, and the number of removed
+// migration records
+func runMigrations(statements []string) ([]string, int) {
+	var run []string
+	deletes := 0
+	for _, statement := range statements {
+		switch {
+		case statement == createUsersSQL || statement == addEmailSQL || statement == dropUsersSQL:
+			run = append(run, statement)
+		case strings.HasPrefix(statement, "DELETE"):
+			deletes++
+		}
+	}
+	return run, deletes
+}
+
+func TestMigratorUp(t *testing.T) {
+	appliedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		applied            []*appliedMigration
+		maxOpenConnections int
+		wantRun            []string
+		wantErr            error
+	}{
+		{
+			name:    "applies the pending migrations",
+			wantRun: []string{createUsersSQL, addEmailSQL},
+		},
+		{
+			name: "skips the applied migrations",
+			applied: []*appliedMigration{
+				{version: 1, name: "create_users", checksum: checksum(createUsersSQL), appliedAt: appliedAt},
+			},
+			maxOpenConnections: MinOpenConnections,
+			wantRun:            []string{addEmailSQL},
+		},
+		{
+			name: "modified migration",
+			applied: []*appliedMigration{
+				{version: 1, name: "create_users", checksum: checksum("CREATE TABLE users ()"), appliedAt: appliedAt},
+			},
+		},
+		{
+			name:               "single connection pool",
+			maxOpenConnections: 1,
+			wantErr:            ErrTooFewConnections,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				migrator, connector := newTestMigrator(
+					t,
+					migratorHandler(true, tt.applied),
+					tt.maxOpenConnections,
+				)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+
+				err := migrator.Up(ctx)
+				switch {
+				case tt.wantErr != nil:
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("Up() error = %v, want %v", err, tt.wantErr)
+					}
+				case tt.wantRun == nil:
+					if err == nil {
+						t.Fatalf("Up() error = nil, want error")
+					}
+				case err != nil:
+					t.Fatalf("Up() error = %v", err)
+				}
+
+				// Check the migrations that were run, each one within its own transaction
+				statements := connector.Statements()
+				var gotRun []string
+				for _, statement := range statements {
+					if statement == createUsersSQL || statement == addEmailSQL {
+						gotRun = append(gotRun, statement)
+					}
+				}
+				if !slices.Equal(gotRun, tt.wantRun) {
+					t.Errorf("run migrations = %v, want %v", gotRun, tt.wantRun)
+				}
+				committed := 0
+				for _, statement := range statements {
+					if statement == sqltest.CommitStatement {
+						committed++
+					}
+				}
+				if committed != len(tt.wantRun) {
+					t.Errorf("committed transactions = %d, want %d", committed, len(tt.wantRun))
+				}
+			},
+		)
+	}
+}
+
+func TestMigratorStatus(t *testing.T) {
+	appliedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		tableExists bool
+		applied     []*appliedMigration
+		want        []MigrationStatus
+	}{
+		{
+			name: "missing schema migrations table",
+			want: []MigrationStatus{
+				{Version: 1, Name: "create_users"},
+				{Version: 2, Name: "add_email"},
+			},
+		},
+		{
+			name:        "applied, modified and missing migrations",
+			tableExists: true,
+			applied: []*appliedMigration{
+				{version: 1, name: "create_users", checksum: checksum(createUsersSQL), appliedAt: appliedAt},
+				{version: 2, name: "add_email", checksum: checksum("ALTER TABLE users ADD name TEXT"), appliedAt: appliedAt},
+				{version: 3, name: "drop_email", checksum: checksum("SELECT 1"), appliedAt: appliedAt, dirty: true},
+			},
+			want: []MigrationStatus{
+				{Version: 1, Name: "create_users", Applied: true, AppliedAt: appliedAt},
+				{Version: 2, Name: "add_email", Applied: true, AppliedAt: appliedAt, Modified: true},
+				{Version: 3, Name: "drop_email", Applied: true, AppliedAt: appliedAt, Missing: true, Dirty: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Use a single connection pool, since the status is read without the lock
+				migrator, connector := newTestMigrator(t, migratorHandler(tt.tableExists, tt.applied), 1)
+
+				statuses, err := migrator.Status(context.Background())
+				if err != nil {
+					t.Fatalf("Status() error = %v", err)
+				}
+				if len(statuses) != len(tt.want) {
+					t.Fatalf("Status() = %d statuses, want %d", len(statuses), len(tt.want))
+				}
+				for i, status := range statuses {
+					if *status != tt.want[i] {
+						t.Errorf("Status()[%d] = %+v, want %+v", i, *status, tt.want[i])
+					}
+				}
+
+				// Check that neither the lock was acquired nor the table was created
+				for _, statement := range connector.Statements() {
+					if strings.Contains(statement, "pg_advisory_lock") || strings.HasPrefix(statement, "CREATE") {
+						t.Errorf("Status() ran %q", statement)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestNewMigrator(t *testing.T) {
+	db, _ := sqltest.Open(migratorHandler(false, nil))
+	defer db.Close()
+
+	tests := []struct {
+		name           string
+		db             *sql.DB
+		fileSystem     fs.FS
+		wantMigrations int
+		wantErr        bool
+	}{
+		{name: "SQL migrations", db: db, fileSystem: testFileSystem, wantMigrations: 2},
+		{name: "Go migrations only", db: db},
+		{name: "nil database", fileSystem: testFileSystem, wantErr: true},
+		{
+			name:       "invalid migration files",
+			db:         db,
+			fileSystem: fstest.MapFS{"0001_create_users.down.sql": {Data: []byte("DROP TABLE users")}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				migrator, err := NewMigrator(tt.db, tt.fileSystem, "")
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("NewMigrator() error = %v, want error %v", err, tt.wantErr)
+				}
+				if err == nil && len(migrator.Migrations()) != tt.wantMigrations {
+					t.Errorf("Migrations() = %d, want %d", len(migrator.Migrations()), tt.wantMigrations)
+				}
+			},
+		)
+	}
+}
+
+func TestMigratorRegister(t *testing.T) {
+	upFn := func(context.Context, *sql.Tx) error {
+		return nil
+	}
+	backfill, err := NewGoMigration(3, "backfill_users", upFn, nil)
+	if err != nil {
+		t.Fatalf("NewGoMigration() error = %v", err)
+	}
+	duplicated, err := NewGoMigration(2, "backfill_emails", upFn, nil)
+	if err != nil {
+		t.Fatalf("NewGoMigration() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		migrations   []*Migration
+		wantVersions []int64
+		wantErr      error
+	}{
+		{
+			name:         "interleaved with the SQL migrations",
+			migrations:   []*Migration{backfill},
+			wantVersions: []int64{1, 2, 3},
+		},
+		{
+			name:       "duplicated version",
+			migrations: []*Migration{duplicated},
+		},
+		{
+			name:       "nil migration",
+			migrations: []*Migration{nil},
+			wantErr:    ErrNilMigration,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				migrator, _ := newTestMigrator(t, migratorHandler(false, nil), 0)
+
+				err := migrator.Register(tt.migrations...)
+				if tt.wantVersions == nil {
+					if err == nil {
+						t.Fatalf("Register() error = nil, want error")
+					}
+					if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+						t.Fatalf("Register() error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Register() error = %v", err)
+				}
+
+				// Check the order of the migrations
+				var gotVersions []int64
+				for _, migration := range migrator.Migrations() {
+					gotVersions = append(gotVersions, migration.Version)
+				}
+				if !slices.Equal(gotVersions, tt.wantVersions) {
+					t.Errorf("Migrations() versions = %v, want %v", gotVersions, tt.wantVersions)
+				}
+			},
+		)
+	}
+}
+
+func TestMigratorUpNonTransactional(t *testing.T) {
+	appliedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	errIndex := errors.New("index build failed")
+
+	tests := []struct {
+		name      string
+		upErr     error
+		wantDirty bool
+	}{
+		{name: "clears the dirty flag"},
+		{name: "leaves the migration dirty", upErr: errIndex, wantDirty: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				migrator, connector := newTestMigrator(
+					t,
+					migratorHandler(
+						true, []*appliedMigration{
+							{version: 1, name: "create_users", checksum: checksum(createUsersSQL), appliedAt: appliedAt},
+							{version: 2, name: "add_email", checksum: checksum(addEmailSQL), appliedAt: appliedAt},
+						},
+					),
+					0,
+				)
+				migration, err := NewNonTransactionalGoMigration(
+					3,
+					"index_users_email",
+					func(context.Context, *sql.DB) error {
+						return tt.upErr
+					},
+					nil,
+				)
+				if err != nil {
+					t.Fatalf("NewNonTransactionalGoMigration() error = %v", err)
+				}
+				if err = migrator.Register(migration); err != nil {
+					t.Fatalf("Register() error = %v", err)
+				}
+
+				if err = migrator.Up(context.Background()); !errors.Is(err, tt.upErr) {
+					t.Fatalf("Up() error = %v, want %v", err, tt.upErr)
+				}
+
+				// Check that the migration was recorded as dirty, and that the flag was cleared if it succeeded
+				var recordedDirty, cleared bool
+				for _, statement := range connector.Statements() {
+					switch {
+					case strings.HasPrefix(statement, "INSERT") && strings.Contains(statement, "true"):
+						recordedDirty = true
+					case strings.HasPrefix(statement, "UPDATE") && strings.Contains(statement, "dirty"):
+						cleared = true
+					case strings.HasPrefix(statement, "ALTER"):
+						t.Errorf("Up() ran %q", statement)
+					}
+				}
+				if !recordedDirty {
+					t.Errorf("the migration was not recorded as dirty")
+				}
+				if cleared == tt.wantDirty {
+					t.Errorf("dirty flag cleared = %v, want %v", cleared, !tt.wantDirty)
+				}
+			},
+		)
+	}
+}
+
+func TestMigratorForce(t *testing.T) {
+	appliedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		version     int64
+		wantInserts int
+		wantErr     bool
+	}{
+		{name: "records the migrations up to the version", version: 2, wantInserts: 1},
+		{name: "removes every record", version: 0},
+		{name: "unknown version", version: 9, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				migrator, connector := newTestMigrator(
+					t,
+					migratorHandler(
+						true, []*appliedMigration{
+							{
+								version:   1,
+								name:      "create_users",
+								checksum:  checksum(createUsersSQL),
+								appliedAt: appliedAt,
+								dirty:     true,
+							},
+						},
+					),
+					0,
+				)
+
+				err := migrator.Force(context.Background(), tt.version)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Force() error = %v, want error %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+
+				// Check the records, without running any migration
+				inserts, deletes := 0, 0
+				for _, statement := range connector.Statements() {
+					switch {
+					case statement == createUsersSQL || statement == addEmailSQL:
+						t.Errorf("Force() ran the migration %q", statement)
+					case strings.HasPrefix(statement, "INSERT"):
+						inserts++
+					case strings.HasPrefix(statement, "DELETE"):
+						deletes++
+					}
+				}
+				if inserts != tt.wantInserts || deletes != 1 {
+					t.Errorf("Force() inserts = %d and deletes = %d, want %d and 1", inserts, deletes, tt.wantInserts)
+				}
+			},
+		)
+	}
+}
+
+func TestMigratorDown(t *testing.T) {
+	appliedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	createUsers := &appliedMigration{
+		version:   1,
+		name:      "create_users",
+		checksum:  checksum(createUsersSQL),
+		appliedAt: appliedAt,
+	}
+	addEmail := &appliedMigration{version: 2, name: "add_email", checksum: checksum(addEmailSQL), appliedAt: appliedAt}
+	backfillUsers := &appliedMigration{version: 3, name: "backfill_users", appliedAt: appliedAt}
+
+	// Define a migration rolled back through a function, recording that it was run
+	rolledBack := 0
+	backfill, err := NewGoMigration(
+		3,
+		"backfill_users",
+		func(context.Context, *sql.Tx) error {
+			return nil
+		},
+		func(context.Context, *sql.Tx) error {
+			rolledBack++
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewGoMigration() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		migrations     []*Migration
+		applied        []*appliedMigration
+		n              int
+		wantRun        []string
+		wantRolledBack int
+		wantErr        error
+		wantErrMessage string
+	}{
+		{
+			name:           "rolls back n migrations",
+			migrations:     []*Migration{backfill},
+			applied:        []*appliedMigration{createUsers, addEmail, backfillUsers},
+			n:              1,
+			wantRolledBack: 1,
+		},
+		{
+			name:    "rolls back the last migration",
+			applied: []*appliedMigration{createUsers},
+			n:       1,
+			wantRun: []string{dropUsersSQL},
+		},
+		{
+			name:    "rolls back at most the applied migrations",
+			applied: []*appliedMigration{createUsers},
+			n:       3,
+			wantRun: []string{dropUsersSQL},
+		},
+		{
+			name:           "missing down migration",
+			applied:        []*appliedMigration{createUsers, addEmail},
+			n:              2,
+			wantErrMessage: fmt.Sprintf(ErrMissingDownMigration, 2),
+		},
+		{
+			name:    "no steps",
+			applied: []*appliedMigration{createUsers},
+			wantErr: ErrInvalidDownSteps,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rolledBack = 0
+				migrator, connector := newTestMigrator(t, migratorHandler(true, tt.applied), 0)
+				if err := migrator.Register(tt.migrations...); err != nil {
+					t.Fatalf("Register() error = %v", err)
+				}
+
+				err := migrator.Down(context.Background(), tt.n)
+				switch {
+				case tt.wantErrMessage != "":
+					if err == nil || !strings.Contains(err.Error(), tt.wantErrMessage) {
+						t.Fatalf("Down() error = %v, want %q", err, tt.wantErrMessage)
+					}
+				case !errors.Is(err, tt.wantErr):
+					t.Fatalf("Down() error = %v, want %v", err, tt.wantErr)
+				}
+
+				// Check the rolled back migrations and their removed records
+				gotRun, deletes := runMigrations(connector.Statements())
+				if !slices.Equal(gotRun, tt.wantRun) {
+					t.Errorf("run migrations = %v, want %v", gotRun, tt.wantRun)
+				}
+				if rolledBack != tt.wantRolledBack {
+					t.Errorf("rolled back functions = %d, want %d", rolledBack, tt.wantRolledBack)
+				}
+				if wantDeletes := len(tt.wantRun) + tt.wantRolledBack; deletes != wantDeletes {
+					t.Errorf("removed records = %d, want %d", deletes, wantDeletes)
+				}
+			},
+		)
+	}
+}
+
+func TestMigratorGoto(t *testing.T) {
+	appliedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	createUsers := &appliedMigration{
+		version:   1,
+		name:      "create_users",
+		checksum:  checksum(createUsersSQL),
+		appliedAt: appliedAt,
+	}
+	addEmail := &appliedMigration{version: 2, name: "add_email", checksum: checksum(addEmailSQL), appliedAt: appliedAt}
+
+	tests := []struct {
+		name           string
+		applied        []*appliedMigration
+		version        int64
+		wantRun        []string
+		wantDeletes    int
+		wantErrMessage string
+	}{
+		{
+			name:    "applies the migrations up to the version",
+			version: 1,
+			wantRun: []string{createUsersSQL},
+		},
+		{
+			name:    "applies every migration",
+			applied: []*appliedMigration{createUsers},
+			version: 2,
+			wantRun: []string{addEmailSQL},
+		},
+		{
+			name:        "rolls back every migration",
+			applied:     []*appliedMigration{createUsers},
+			version:     0,
+			wantRun:     []string{dropUsersSQL},
+			wantDeletes: 1,
+		},
+		{
+			name:           "missing down migration",
+			applied:        []*appliedMigration{createUsers, addEmail},
+			version:        1,
+			wantErrMessage: fmt.Sprintf(ErrMissingDownMigration, 2),
+		},
+		{
+			name:           "unknown version",
+			applied:        []*appliedMigration{createUsers},
+			version:        9,
+			wantErrMessage: fmt.Sprintf(ErrUnknownMigration, 9),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				migrator, connector := newTestMigrator(t, migratorHandler(true, tt.applied), 0)
+
+				err := migrator.Goto(context.Background(), tt.version)
+				switch {
+				case tt.wantErrMessage != "":
+					if err == nil || !strings.Contains(err.Error(), tt.wantErrMessage) {
+						t.Fatalf("Goto() error = %v, want %q", err, tt.wantErrMessage)
+					}
+				case err != nil:
+					t.Fatalf("Goto() error = %v", err)
+				}
+
+				// Check the applied and rolled back migrations
+				gotRun, deletes := runMigrations(connector.Statements())
+				if !slices.Equal(gotRun, tt.wantRun) {
+					t.Errorf("run migrations = %v, want %v", gotRun, tt.wantRun)
+				}
+				if deletes != tt.wantDeletes {
+					t.Errorf("removed records = %d, want %d", deletes, tt.wantDeletes)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,9 @@
+package outbox
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilStore = errors.New("outbox store cannot be nil")
+)
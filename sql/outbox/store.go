@@ -0,0 +1,384 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gooutbox "github.com/ralvarezdev/go-databases/outbox"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+const (
+	// DefaultTableName is the default name of the outbox table
+	DefaultTableName = "outbox_events"
+
+	// DefaultLease is the default time an event stays claimed by a relay before other relays can claim it
+	DefaultLease = 30 * time.Second
+)
+
+type (
+	// Store is a PostgreSQL outbox table
+	//
+	// The events are enqueued within the transactions of the application, and claimed by the relays with
+	// FOR UPDATE SKIP LOCKED in a single statement that postpones their next attempt by a lease, so several
+	// relays can poll the same table without dispatching an event twice while the lease lasts. The events are
+	// published outside of any transaction, so the lease must be longer than the time taken to publish a batch.
+	Store struct {
+		db        *sql.DB
+		table     string
+		indexName string
+		lease     time.Duration
+	}
+
+	// claimedEvent is an event claimed by a relay, with the end of its claim
+	claimedEvent struct {
+		*gooutbox.Event
+		claimedUntil time.Time
+	}
+)
+
+// NewStore creates a new outbox store
+//
+// Parameters:
+//
+//   - db: the database connection
+//   - table: the name of the outbox table, optionally qualified with its schema, DefaultTableName is used if
+//     it is empty
+//   - lease: the time an event stays claimed by a relay, DefaultLease is used if it is not positive
+//
+// Returns:
+//
+//   - *Store: the outbox store
+//   - error: if the database connection is nil
+func NewStore(db *sql.DB, table string, lease time.Duration) (*Store, error) {
+	// Check if the database connection is nil
+	if db == nil {
+		return nil, godatabases.ErrNilConnection
+	}
+
+	// Set the default table name and lease
+	if table == "" {
+		table = DefaultTableName
+	}
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+
+	return &Store{
+		db:        db,
+		table:     pgx.Identifier(strings.Split(table, ".")).Sanitize(),
+		indexName: pgx.Identifier{strings.ReplaceAll(table, ".", "_") + "_due_idx"}.Sanitize(),
+		lease:     lease,
+	}, nil
+}
+
+// CreateTable creates the outbox table and its index if they do not exist
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (s *Store) CreateTable(ctx context.Context) error {
+	if s == nil {
+		return ErrNilStore
+	}
+
+	// Create the table
+	if _, err := s.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s (
+	id TEXT PRIMARY KEY,
+	topic TEXT NOT NULL,
+	key TEXT NOT NULL DEFAULT '',
+	payload BYTEA,
+	headers JSONB NOT NULL DEFAULT '{}',
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	last_error TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	dispatched_at TIMESTAMPTZ
+)`,
+			s.table,
+		),
+	); err != nil {
+		return err
+	}
+
+	// Create the index of the due events
+	_, err := s.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s ON %s (next_attempt_at) WHERE status = 'pending'",
+			s.indexName,
+			s.table,
+		),
+	)
+	return err
+}
+
+// Enqueue stores the events within the transaction, so they are only dispatched if it commits
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - tx: the transaction, the active transaction in the context is used if nil
+//   - events: the events to enqueue
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (s *Store) Enqueue(
+	ctx context.Context,
+	tx *sql.Tx,
+	events ...*gooutbox.Event,
+) error {
+	if s == nil {
+		return ErrNilStore
+	}
+
+	// Get the active transaction in the context if the transaction is nil
+	if tx == nil {
+		var ok bool
+		if tx, ok = gosql.GetTxFromContext(ctx); !ok {
+			return gosql.ErrNilTransaction
+		}
+	}
+
+	// Insert the events
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, topic, key, payload, headers, status, created_at, next_attempt_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $7)",
+		s.table,
+	)
+	for _, event := range events {
+		// Prepare the event
+		if err := gooutbox.Prepare(event); err != nil {
+			return err
+		}
+
+		// Encode the headers
+		headers := event.Headers
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		encodedHeaders, err := json.Marshal(headers)
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.ExecContext(
+			ctx,
+			query,
+			event.ID,
+			event.Topic,
+			event.Key,
+			event.Payload,
+			string(encodedHeaders),
+			string(gooutbox.StatusPending),
+			event.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claim claims the due events, postponing their next attempt by the lease and skipping the ones locked by other
+// relays
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - limit: the maximum number of events to claim
+//
+// Returns:
+//
+//   - []*claimedEvent: the claimed events, sorted by creation time
+//   - error: if any error occurs
+func (s *Store) claim(ctx context.Context, limit int) ([]*claimedEvent, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE %[1]s SET next_attempt_at = now() + make_interval(secs => $3)
+WHERE id IN (
+	SELECT id FROM %[1]s WHERE status = $1 AND next_attempt_at <= now()
+	ORDER BY next_attempt_at, created_at LIMIT $2 FOR UPDATE SKIP LOCKED
+)
+RETURNING id, topic, key, payload, headers, attempts, created_at, next_attempt_at`,
+			s.table,
+		),
+		string(gooutbox.StatusPending),
+		limit,
+		s.lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Scan the events
+	var events []*claimedEvent
+	for rows.Next() {
+		event := &claimedEvent{Event: &gooutbox.Event{}}
+		var encodedHeaders []byte
+		if err = rows.Scan(
+			&event.ID,
+			&event.Topic,
+			&event.Key,
+			&event.Payload,
+			&encodedHeaders,
+			&event.Attempts,
+			&event.CreatedAt,
+			&event.claimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(encodedHeaders, &event.Headers); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Sort the events, since the returned rows are not ordered
+	slices.SortFunc(
+		events, func(a, b *claimedEvent) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		},
+	)
+	return events, nil
+}
+
+// Process claims up to limit due events, calls the function for each one and persists its result
+//
+// The events are claimed by a single statement and processed outside of any transaction, even if the
+// context carries one, so a slow publication does not keep a transaction open.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - limit: the maximum number of events to claim
+//   - fn: the function called for each claimed event
+//
+// Returns:
+//
+//   - int: the number of processed events
+//   - error: if any error occurs, or the events whose claim expired wrapping gooutbox.ErrEventClaimExpired
+func (s *Store) Process(
+	ctx context.Context,
+	limit int,
+	fn gooutbox.ProcessFn,
+) (int, error) {
+	if s == nil {
+		return 0, ErrNilStore
+	}
+
+	// Check if the function is nil
+	if fn == nil {
+		return 0, gooutbox.ErrNilProcessFn
+	}
+
+	// Claim the due events, outside of the active transaction in the context if any
+	ctx = gosql.WithoutTx(ctx)
+	events, err := s.claim(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	// Process the events and persist their results
+	processed := 0
+	var expiredErrs []error
+	for _, event := range events {
+		if err = s.update(ctx, event, fn(ctx, event.Event)); err != nil {
+			if !errors.Is(err, gooutbox.ErrEventClaimExpired) {
+				return processed, err
+			}
+			expiredErrs = append(expiredErrs, err)
+		}
+		processed++
+	}
+	return processed, errors.Join(expiredErrs...)
+}
+
+// update persists the dispatch result of an event if it is still claimed by this relay
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - event: the claimed event
+//   - result: the dispatch result
+//
+// Returns:
+//
+//   - error: if any error occurs, wrapping gooutbox.ErrEventClaimExpired if the claim expired
+func (s *Store) update(
+	ctx context.Context,
+	event *claimedEvent,
+	result gooutbox.Result,
+) error {
+	var res sql.Result
+	var err error
+	if result.Status == gooutbox.StatusDispatched {
+		// Mark the event as dispatched
+		res, err = s.db.ExecContext(
+			ctx,
+			fmt.Sprintf(
+				"UPDATE %s SET status = $3, dispatched_at = now(), last_error = NULL WHERE id = $1 AND next_attempt_at = $2",
+				s.table,
+			),
+			event.ID,
+			event.claimedUntil,
+			string(result.Status),
+		)
+	} else {
+		// Record the failed attempt
+		var lastError sql.NullString
+		if result.Err != nil {
+			lastError = sql.NullString{String: result.Err.Error(), Valid: true}
+		}
+		nextAttemptAt := sql.NullTime{
+			Time:  result.NextAttemptAt,
+			Valid: !result.NextAttemptAt.IsZero(),
+		}
+		res, err = s.db.ExecContext(
+			ctx,
+			fmt.Sprintf(
+				"UPDATE %s SET status = $3, attempts = attempts + 1, next_attempt_at = COALESCE($4, now()), last_error = $5 WHERE id = $1 AND next_attempt_at = $2",
+				s.table,
+			),
+			event.ID,
+			event.claimedUntil,
+			string(result.Status),
+			nextAttemptAt,
+			lastError,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Check if the event was still claimed by this relay
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("%w: %s", gooutbox.ErrEventClaimExpired, event.ID)
+	}
+	return nil
+}
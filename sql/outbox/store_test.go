@@ -0,0 +1,260 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+	gooutbox "github.com/ralvarezdev/go-databases/outbox"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+// claimHandler answers the claim statement with the given events, and the updates with the given affected rows
+func claimHandler(
+	claimedUntil time.Time,
+	ids []string,
+	updated map[string]int64,
+) sqltest.HandlerFn {
+	return func(_ context.Context, query string, args []driver.NamedValue) (
+		*sqltest.Result,
+		error,
+	) {
+		// Answer the claim statement
+		if strings.Contains(query, "RETURNING") {
+			result := &sqltest.Result{
+				Columns: []string{
+					"id",
+					"topic",
+					"key",
+					"payload",
+					"headers",
+					"attempts",
+					"created_at",
+					"next_attempt_at",
+				},
+			}
+			createdAt := claimedUntil.Add(-time.Hour)
+			for i, id := range ids {
+				result.Rows = append(
+					result.Rows, []driver.Value{
+						id,
+						"users",
+						"",
+						[]byte(`{}`),
+						[]byte(`{"source": "test"}`),
+						int64(0),
+						createdAt.Add(time.Duration(len(ids)-i) * time.Second),
+						claimedUntil,
+					},
+				)
+			}
+			return result, nil
+		}
+
+		// Answer the updates, which are fenced by the end of the claim
+		if !strings.HasPrefix(query, "UPDATE") {
+			return &sqltest.Result{}, nil
+		}
+		if claimed, ok := args[1].Value.(time.Time); !ok || !claimed.Equal(claimedUntil) {
+			return &sqltest.Result{}, nil
+		}
+		return &sqltest.Result{RowsAffected: updated[args[0].Value.(string)]}, nil
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	db, _ := sqltest.Open(claimHandler(time.Now(), nil, nil))
+	defer db.Close()
+
+	tests := []struct {
+		name      string
+		db        *sql.DB
+		table     string
+		lease     time.Duration
+		wantTable string
+		wantLease time.Duration
+		wantErr   bool
+	}{
+		{
+			name:      "default table and lease",
+			db:        db,
+			wantTable: `"outbox_events"`,
+			wantLease: DefaultLease,
+		},
+		{
+			name:      "qualified table",
+			db:        db,
+			table:     "events.outbox",
+			lease:     time.Minute,
+			wantTable: `"events"."outbox"`,
+			wantLease: time.Minute,
+		},
+		{name: "nil database", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				store, err := NewStore(tt.db, tt.table, tt.lease)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("NewStore() error = %v, want error %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				if store.table != tt.wantTable || store.lease != tt.wantLease {
+					t.Errorf(
+						"NewStore() table = %s and lease = %v, want %s and %v",
+						store.table,
+						store.lease,
+						tt.wantTable,
+						tt.wantLease,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestStoreProcess(t *testing.T) {
+	errPublish := errors.New("broker unavailable")
+	claimedUntil := time.Now().Add(DefaultLease).UTC()
+
+	tests := []struct {
+		name          string
+		ids           []string
+		updated       map[string]int64
+		result        gooutbox.Result
+		wantProcessed int
+		wantIDs       []string
+		wantExpired   []string
+	}{
+		{
+			name:          "dispatches the events in creation order",
+			ids:           []string{"1", "2"},
+			updated:       map[string]int64{"1": 1, "2": 1},
+			result:        gooutbox.Result{Status: gooutbox.StatusDispatched},
+			wantProcessed: 2,
+			wantIDs:       []string{"2", "1"},
+		},
+		{
+			name:    "records the failed attempts",
+			ids:     []string{"1"},
+			updated: map[string]int64{"1": 1},
+			result: gooutbox.Result{
+				Status:        gooutbox.StatusPending,
+				Err:           errPublish,
+				NextAttemptAt: claimedUntil.Add(time.Minute),
+			},
+			wantProcessed: 1,
+			wantIDs:       []string{"1"},
+		},
+		{
+			name:          "reports the expired claims",
+			ids:           []string{"1", "2"},
+			updated:       map[string]int64{"2": 1},
+			result:        gooutbox.Result{Status: gooutbox.StatusDispatched},
+			wantProcessed: 2,
+			wantIDs:       []string{"2", "1"},
+			wantExpired:   []string{"1"},
+		},
+		{name: "no due events"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, connector := sqltest.Open(claimHandler(claimedUntil, tt.ids, tt.updated))
+				defer db.Close()
+				store, err := NewStore(db, "", 0)
+				if err != nil {
+					t.Fatalf("NewStore() error = %v", err)
+				}
+
+				// Process the events from a context that carries a transaction
+				tx, err := db.BeginTx(context.Background(), nil)
+				if err != nil {
+					t.Fatalf("BeginTx() error = %v", err)
+				}
+				defer tx.Rollback()
+				var gotIDs []string
+				processed, err := store.Process(
+					gosql.WithTx(context.Background(), tx),
+					10,
+					func(ctx context.Context, event *gooutbox.Event) gooutbox.Result {
+						if _, ok := gosql.GetTxFromContext(ctx); ok {
+							t.Errorf("event %s processed within a transaction", event.ID)
+						}
+						gotIDs = append(gotIDs, event.ID)
+						return tt.result
+					},
+				)
+				if processed != tt.wantProcessed {
+					t.Errorf("Process() processed = %d, want %d", processed, tt.wantProcessed)
+				}
+				if !slices.Equal(gotIDs, tt.wantIDs) {
+					t.Errorf("processed events = %v, want %v", gotIDs, tt.wantIDs)
+				}
+
+				// Check the expired claims
+				if len(tt.wantExpired) == 0 && err != nil {
+					t.Fatalf("Process() error = %v", err)
+				}
+				if len(tt.wantExpired) != 0 && !errors.Is(err, gooutbox.ErrEventClaimExpired) {
+					t.Fatalf("Process() error = %v, want %v", err, gooutbox.ErrEventClaimExpired)
+				}
+				for _, id := range tt.wantExpired {
+					if !strings.Contains(err.Error(), id) {
+						t.Errorf("Process() error = %v, want the expired event %s", err, id)
+					}
+				}
+
+				// Check that only the transaction of the caller was begun
+				begun := 0
+				for _, statement := range connector.Statements() {
+					if statement == sqltest.BeginStatement {
+						begun++
+					}
+				}
+				if begun != 1 {
+					t.Errorf("begun transactions = %d, want 1", begun)
+				}
+			},
+		)
+	}
+}
+
+func TestStoreProcessErrors(t *testing.T) {
+	db, _ := sqltest.Open(claimHandler(time.Now(), nil, nil))
+	defer db.Close()
+	store, err := NewStore(db, "", 0)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		store   *Store
+		fn      gooutbox.ProcessFn
+		wantErr error
+	}{
+		{name: "nil store", wantErr: ErrNilStore},
+		{name: "nil function", store: store, wantErr: gooutbox.ErrNilProcessFn},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if _, err := tt.store.Process(context.Background(), 10, tt.fn); !errors.Is(
+					err,
+					tt.wantErr,
+				) {
+					t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,269 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// CategoryNone is the category of a nil error
+	CategoryNone Category = iota
+
+	// CategoryUnknown is the category of errors that could not be classified
+	CategoryUnknown
+
+	// CategoryUniqueViolation is the category of unique constraint violations
+	CategoryUniqueViolation
+
+	// CategoryForeignKeyViolation is the category of foreign key constraint violations
+	CategoryForeignKeyViolation
+
+	// CategoryNotNullViolation is the category of not-null constraint violations
+	CategoryNotNullViolation
+
+	// CategoryCheckViolation is the category of check constraint violations
+	CategoryCheckViolation
+
+	// CategoryExclusionViolation is the category of exclusion constraint violations
+	CategoryExclusionViolation
+
+	// CategoryIntegrityConstraintViolation is the category of the remaining integrity constraint violations
+	CategoryIntegrityConstraintViolation
+
+	// CategorySerializationFailure is the category of serialization failures
+	CategorySerializationFailure
+
+	// CategoryDeadlockDetected is the category of detected deadlocks
+	CategoryDeadlockDetected
+
+	// CategoryLockNotAvailable is the category of locks that could not be acquired
+	CategoryLockNotAvailable
+
+	// CategoryQueryCanceled is the category of canceled queries and statement timeouts
+	CategoryQueryCanceled
+
+	// CategoryConnection is the category of failed or lost connections
+	CategoryConnection
+
+	// CategoryDataException is the category of invalid data errors
+	CategoryDataException
+
+	// CategorySyntaxOrAccess is the category of syntax errors and access rule violations
+	CategorySyntaxOrAccess
+
+	// CategoryInsufficientResources is the category of resource exhaustion errors
+	CategoryInsufficientResources
+
+	// CategoryTransactionRollback is the category of the remaining transaction rollback errors, such as an
+	// unknown statement completion
+	CategoryTransactionRollback
+)
+
+type (
+	// Category represents the category of a PostgreSQL error
+	Category int
+)
+
+// String returns the string representation of the category
+//
+// Returns:
+//
+//   - string: the string representation of the category
+func (c Category) String() string {
+	switch c {
+	case CategoryNone:
+		return "none"
+	case CategoryUniqueViolation:
+		return "unique_violation"
+	case CategoryForeignKeyViolation:
+		return "foreign_key_violation"
+	case CategoryNotNullViolation:
+		return "not_null_violation"
+	case CategoryCheckViolation:
+		return "check_violation"
+	case CategoryExclusionViolation:
+		return "exclusion_violation"
+	case CategoryIntegrityConstraintViolation:
+		return "integrity_constraint_violation"
+	case CategorySerializationFailure:
+		return "serialization_failure"
+	case CategoryDeadlockDetected:
+		return "deadlock_detected"
+	case CategoryLockNotAvailable:
+		return "lock_not_available"
+	case CategoryQueryCanceled:
+		return "query_canceled"
+	case CategoryConnection:
+		return "connection"
+	case CategoryDataException:
+		return "data_exception"
+	case CategorySyntaxOrAccess:
+		return "syntax_or_access"
+	case CategoryInsufficientResources:
+		return "insufficient_resources"
+	case CategoryTransactionRollback:
+		return "transaction_rollback"
+	default:
+		return "unknown"
+	}
+}
+
+// IsConstraintViolation checks if the category is an integrity constraint violation
+//
+// Returns:
+//
+//   - bool: true if the category is a constraint violation, false otherwise
+func (c Category) IsConstraintViolation() bool {
+	switch c {
+	case CategoryUniqueViolation,
+		CategoryForeignKeyViolation,
+		CategoryNotNullViolation,
+		CategoryCheckViolation,
+		CategoryExclusionViolation,
+		CategoryIntegrityConstraintViolation:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryable checks if the category is a transient error that can be solved by retrying the transaction
+//
+// Returns:
+//
+//   - bool: true if the category is a serialization failure or a detected deadlock, false otherwise
+func (c Category) IsRetryable() bool {
+	return c == CategorySerializationFailure || c == CategoryDeadlockDetected
+}
+
+// Classify returns the category of an error
+//
+// Parameters:
+//
+//   - err: the error to classify
+//
+// Returns:
+//
+//   - Category: the category of the error, CategoryNone if the error is nil and CategoryUnknown if it is not a known PostgreSQL error
+func Classify(err error) Category {
+	category, _ := ClassifyWithDetails(err)
+	return category
+}
+
+// ClassifyWithDetails returns the category of an error and the details reported by PostgreSQL
+//
+// The errors raised by the client are classified too: the canceled contexts as CategoryQueryCanceled, and the
+// failures to connect, the network errors, the unexpected ends of the connection and the errors that pgconn
+// reports as safe to retry as CategoryConnection.
+//
+// Parameters:
+//
+//   - err: the error to classify
+//
+// Returns:
+//
+//   - Category: the category of the error, CategoryNone if the error is nil and CategoryUnknown if it is not a known error
+//   - *ErrorDetails: the details of the error, nil if it was not reported by PostgreSQL
+func ClassifyWithDetails(err error) (Category, *ErrorDetails) {
+	// Check if the error is nil
+	if err == nil {
+		return CategoryNone, nil
+	}
+
+	// Check if the error was reported by the server
+	details, ok := GetErrorDetails(err)
+	if !ok {
+		return classifyClientError(err), nil
+	}
+	return classifyCode(details.Code), details
+}
+
+// classifyClientError returns the category of an error raised by the client
+//
+// Parameters:
+//
+//   - err: the error to classify
+//
+// Returns:
+//
+//   - Category: the category of the error, CategoryUnknown if it is not a known error
+func classifyClientError(err error) Category {
+	// Check if the context was canceled or its deadline exceeded
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return CategoryQueryCanceled
+	}
+
+	// Check if the connection could not be established or was lost
+	var connectErr *pgconn.ConnectError
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &connectErr),
+		errors.As(err, &opErr),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		pgconn.SafeToRetry(err):
+		return CategoryConnection
+	default:
+		return CategoryUnknown
+	}
+}
+
+// classifyCode returns the category of a SQLSTATE code
+//
+// Parameters:
+//
+//   - code: the SQLSTATE code
+//
+// Returns:
+//
+//   - Category: the category of the code, CategoryUnknown if it is not a known code
+func classifyCode(code string) Category {
+	// Check the specific codes first
+	switch code {
+	case UniqueViolationCode:
+		return CategoryUniqueViolation
+	case ForeignKeyViolationCode, RestrictViolationCode:
+		return CategoryForeignKeyViolation
+	case NotNullViolationCode:
+		return CategoryNotNullViolation
+	case CheckViolationCode:
+		return CategoryCheckViolation
+	case ExclusionViolationCode:
+		return CategoryExclusionViolation
+	case SerializationFailureCode:
+		return CategorySerializationFailure
+	case DeadlockDetectedCode:
+		return CategoryDeadlockDetected
+	case LockNotAvailableCode:
+		return CategoryLockNotAvailable
+	case QueryCanceledCode:
+		return CategoryQueryCanceled
+	case AdminShutdownCode, CrashShutdownCode, CannotConnectNowCode:
+		return CategoryConnection
+	}
+
+	// Fall back to the SQLSTATE class
+	if len(code) < 2 {
+		return CategoryUnknown
+	}
+	switch code[:2] {
+	case ClassIntegrityConstraintViolation:
+		return CategoryIntegrityConstraintViolation
+	case ClassConnectionException, ClassOperatorIntervention:
+		return CategoryConnection
+	case ClassTransactionRollback:
+		return CategoryTransactionRollback
+	case ClassDataException:
+		return CategoryDataException
+	case ClassSyntaxErrorOrAccessRuleViolation:
+		return CategorySyntaxOrAccess
+	case ClassInsufficientResources:
+		return CategoryInsufficientResources
+	default:
+		return CategoryUnknown
+	}
+}
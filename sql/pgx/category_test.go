@@ -0,0 +1,263 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type (
+	// safeToRetryError is an error that pgconn reports as safe to retry
+	safeToRetryError struct{}
+)
+
+func (safeToRetryError) Error() string {
+	return "connection closed before sending"
+}
+
+func (safeToRetryError) SafeToRetry() bool {
+	return true
+}
+
+func TestClassifyWithDetails(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		want        Category
+		wantDetails bool
+	}{
+		{name: "nil", want: CategoryNone},
+		{name: "unknown", err: errors.New("boom"), want: CategoryUnknown},
+		{
+			name:        "unique violation",
+			err:         &pgconn.PgError{Code: UniqueViolationCode, ConstraintName: "users_email_key"},
+			want:        CategoryUniqueViolation,
+			wantDetails: true,
+		},
+		{
+			name:        "wrapped foreign key violation",
+			err:         fmt.Errorf("insert: %w", &pgconn.PgError{Code: ForeignKeyViolationCode}),
+			want:        CategoryForeignKeyViolation,
+			wantDetails: true,
+		},
+		{
+			name:        "restrict violation",
+			err:         &pgconn.PgError{Code: RestrictViolationCode},
+			want:        CategoryForeignKeyViolation,
+			wantDetails: true,
+		},
+		{
+			name:        "other integrity constraint violation",
+			err:         &pgconn.PgError{Code: "23000"},
+			want:        CategoryIntegrityConstraintViolation,
+			wantDetails: true,
+		},
+		{
+			name:        "serialization failure",
+			err:         &pgconn.PgError{Code: SerializationFailureCode},
+			want:        CategorySerializationFailure,
+			wantDetails: true,
+		},
+		{
+			name:        "deadlock detected",
+			err:         &pgconn.PgError{Code: DeadlockDetectedCode},
+			want:        CategoryDeadlockDetected,
+			wantDetails: true,
+		},
+		{
+			name:        "other transaction rollback",
+			err:         &pgconn.PgError{Code: "40003"},
+			want:        CategoryTransactionRollback,
+			wantDetails: true,
+		},
+		{
+			name:        "lock not available",
+			err:         &pgconn.PgError{Code: LockNotAvailableCode},
+			want:        CategoryLockNotAvailable,
+			wantDetails: true,
+		},
+		{
+			name:        "query canceled",
+			err:         &pgconn.PgError{Code: QueryCanceledCode},
+			want:        CategoryQueryCanceled,
+			wantDetails: true,
+		},
+		{
+			name:        "admin shutdown",
+			err:         &pgconn.PgError{Code: AdminShutdownCode},
+			want:        CategoryConnection,
+			wantDetails: true,
+		},
+		{
+			name:        "idle session timeout",
+			err:         &pgconn.PgError{Code: "57P05"},
+			want:        CategoryConnection,
+			wantDetails: true,
+		},
+		{
+			name:        "connection exception",
+			err:         &pgconn.PgError{Code: "08006"},
+			want:        CategoryConnection,
+			wantDetails: true,
+		},
+		{
+			name:        "data exception",
+			err:         &pgconn.PgError{Code: "22P02"},
+			want:        CategoryDataException,
+			wantDetails: true,
+		},
+		{
+			name:        "syntax error",
+			err:         &pgconn.PgError{Code: "42601"},
+			want:        CategorySyntaxOrAccess,
+			wantDetails: true,
+		},
+		{
+			name:        "insufficient resources",
+			err:         &pgconn.PgError{Code: "53300"},
+			want:        CategoryInsufficientResources,
+			wantDetails: true,
+		},
+		{
+			name:        "unknown code",
+			err:         &pgconn.PgError{Code: "XX000"},
+			want:        CategoryUnknown,
+			wantDetails: true,
+		},
+		{name: "canceled context", err: context.Canceled, want: CategoryQueryCanceled},
+		{
+			name: "deadline exceeded",
+			err:  fmt.Errorf("query: %w", context.DeadlineExceeded),
+			want: CategoryQueryCanceled,
+		},
+		{name: "eof", err: fmt.Errorf("read: %w", io.EOF), want: CategoryConnection},
+		{name: "unexpected eof", err: io.ErrUnexpectedEOF, want: CategoryConnection},
+		{
+			name: "network error",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			want: CategoryConnection,
+		},
+		{name: "safe to retry", err: safeToRetryError{}, want: CategoryConnection},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, details := ClassifyWithDetails(tt.err)
+				if got != tt.want {
+					t.Errorf("ClassifyWithDetails() category = %s, want %s", got, tt.want)
+				}
+				if (details != nil) != tt.wantDetails {
+					t.Errorf("ClassifyWithDetails() details = %+v, want details %v", details, tt.wantDetails)
+				}
+				if classified := Classify(tt.err); classified != got {
+					t.Errorf("Classify() = %s, want %s", classified, got)
+				}
+			},
+		)
+	}
+}
+
+func TestIsErrorHelpers(t *testing.T) {
+	tests := []struct {
+		name        string
+		checkFn     func(error) (bool, *ErrorDetails)
+		err         error
+		want        bool
+		wantDetails bool
+	}{
+		{
+			name:        "serialization failure",
+			checkFn:     IsSerializationFailureError,
+			err:         &pgconn.PgError{Code: SerializationFailureCode},
+			want:        true,
+			wantDetails: true,
+		},
+		{
+			name:    "not a serialization failure",
+			checkFn: IsSerializationFailureError,
+			err:     &pgconn.PgError{Code: DeadlockDetectedCode},
+		},
+		{
+			name:        "deadlock detected",
+			checkFn:     IsDeadlockDetectedError,
+			err:         fmt.Errorf("tx: %w", &pgconn.PgError{Code: DeadlockDetectedCode}),
+			want:        true,
+			wantDetails: true,
+		},
+		{
+			name:    "not a deadlock detected",
+			checkFn: IsDeadlockDetectedError,
+			err:     io.EOF,
+		},
+		{
+			name:        "query canceled",
+			checkFn:     IsQueryCanceledError,
+			err:         &pgconn.PgError{Code: QueryCanceledCode},
+			want:        true,
+			wantDetails: true,
+		},
+		{
+			name:    "canceled context as query canceled",
+			checkFn: IsQueryCanceledError,
+			err:     fmt.Errorf("query: %w", context.Canceled),
+			want:    true,
+		},
+		{
+			name:    "timed out context as query canceled",
+			checkFn: IsQueryCanceledError,
+			err:     context.DeadlineExceeded,
+			want:    true,
+		},
+		{
+			name:    "not a query canceled",
+			checkFn: IsQueryCanceledError,
+			err:     &pgconn.PgError{Code: UniqueViolationCode},
+		},
+		{
+			name: "retryable",
+			checkFn: func(err error) (bool, *ErrorDetails) {
+				return IsRetryableError(err), nil
+			},
+			err:  &pgconn.PgError{Code: SerializationFailureCode},
+			want: true,
+		},
+		{
+			name: "not retryable",
+			checkFn: func(err error) (bool, *ErrorDetails) {
+				return IsRetryableError(err), nil
+			},
+			err: &pgconn.PgError{Code: UniqueViolationCode},
+		},
+		{
+			name:    "connection error",
+			checkFn: IsConnectionError,
+			err:     io.EOF,
+			want:    true,
+		},
+		{
+			name:        "restrict violation as foreign key violation",
+			checkFn:     IsForeignKeyViolationError,
+			err:         &pgconn.PgError{Code: RestrictViolationCode},
+			want:        true,
+			wantDetails: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, details := tt.checkFn(tt.err)
+				if got != tt.want {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+				if (details != nil) != tt.wantDetails {
+					t.Errorf("details = %+v, want details %v", details, tt.wantDetails)
+				}
+			},
+		)
+	}
+}
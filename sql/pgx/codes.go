@@ -6,6 +6,124 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
+type (
+	// ErrorDetails holds the fields reported by PostgreSQL for an error
+	ErrorDetails struct {
+		Code           string
+		Message        string
+		Detail         string
+		Hint           string
+		SchemaName     string
+		TableName      string
+		ColumnName     string
+		ConstraintName string
+	}
+)
+
+// GetErrorDetails extracts the details of a PostgreSQL error from the error chain
+//
+// Parameters:
+//
+//   - err: the error to inspect
+//
+// Returns:
+//
+//   - *ErrorDetails: the details of the PostgreSQL error, nil if the error is not a PostgreSQL error
+//   - bool: true if the error chain contains a PostgreSQL error, false otherwise
+func GetErrorDetails(err error) (*ErrorDetails, bool) {
+	var pqErr *pgconn.PgError
+	if !errors.As(err, &pqErr) {
+		return nil, false
+	}
+	return &ErrorDetails{
+		Code:           pqErr.Code,
+		Message:        pqErr.Message,
+		Detail:         pqErr.Detail,
+		Hint:           pqErr.Hint,
+		SchemaName:     pqErr.SchemaName,
+		TableName:      pqErr.TableName,
+		ColumnName:     pqErr.ColumnName,
+		ConstraintName: pqErr.ConstraintName,
+	}, true
+}
+
+// GetErrorCode returns the SQLSTATE code of a PostgreSQL error
+//
+// Parameters:
+//
+//   - err: the error to inspect
+//
+// Returns:
+//
+//   - string: the SQLSTATE code, empty string if the error is not a PostgreSQL error
+func GetErrorCode(err error) string {
+	var pqErr *pgconn.PgError
+	if errors.As(err, &pqErr) {
+		return pqErr.Code
+	}
+	return ""
+}
+
+// GetErrorClass returns the SQLSTATE class, the first two characters of the code, of a PostgreSQL error
+//
+// Parameters:
+//
+//   - err: the error to inspect
+//
+// Returns:
+//
+//   - string: the SQLSTATE class, empty string if the error is not a PostgreSQL error
+func GetErrorClass(err error) string {
+	code := GetErrorCode(err)
+	if len(code) < 2 {
+		return ""
+	}
+	return code[:2]
+}
+
+// isErrorWithCode checks if the error is a PostgreSQL error with one of the given codes
+//
+// Parameters:
+//
+//   - err: the error to check
+//   - codes: the SQLSTATE codes to match
+//
+// Returns:
+//
+//   - bool: true if the error matches one of the codes, false otherwise
+//   - *ErrorDetails: the details of the error if it matches, nil otherwise
+func isErrorWithCode(err error, codes ...string) (bool, *ErrorDetails) {
+	details, ok := GetErrorDetails(err)
+	if !ok {
+		return false, nil
+	}
+	for _, code := range codes {
+		if details.Code == code {
+			return true, details
+		}
+	}
+	return false, nil
+}
+
+// isErrorWithCategory checks if the error is classified in the given category
+//
+// Parameters:
+//
+//   - err: the error to check
+//   - category: the category to match
+//
+// Returns:
+//
+//   - bool: true if the error is classified in the category, false otherwise
+//   - *ErrorDetails: the details of the error if it matches and was reported by the server, nil otherwise
+func isErrorWithCategory(err error, category Category) (bool, *ErrorDetails) {
+	classified, details := ClassifyWithDetails(err)
+	if classified != category {
+		return false, nil
+	}
+	return true, details
+}
+
 // IsUniqueViolationError checks if the error is a unique violation error
 //
 // Parameters:
@@ -23,3 +141,148 @@ func IsUniqueViolationError(err error) (isUniqueValidationErr bool, constraintNa
 	}
 	return false, ""
 }
+
+// IsForeignKeyViolationError checks if the error is a foreign key violation error
+//
+// The restrict violations, raised by the ON DELETE RESTRICT and ON UPDATE RESTRICT actions, are reported as
+// foreign key violations too.
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a foreign key violation error, false otherwise
+//   - *ErrorDetails: the constraint, table, column and detail of the violation, nil if not a foreign key violation error
+func IsForeignKeyViolationError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCode(err, ForeignKeyViolationCode, RestrictViolationCode)
+}
+
+// IsNotNullViolationError checks if the error is a not-null violation error
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a not-null violation error, false otherwise
+//   - *ErrorDetails: the table, column and detail of the violation, nil if not a not-null violation error
+func IsNotNullViolationError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCode(err, NotNullViolationCode)
+}
+
+// IsCheckViolationError checks if the error is a check constraint violation error
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a check violation error, false otherwise
+//   - *ErrorDetails: the constraint, table and detail of the violation, nil if not a check violation error
+func IsCheckViolationError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCode(err, CheckViolationCode)
+}
+
+// IsExclusionViolationError checks if the error is an exclusion constraint violation error
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is an exclusion violation error, false otherwise
+//   - *ErrorDetails: the constraint, table and detail of the violation, nil if not an exclusion violation error
+func IsExclusionViolationError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCode(err, ExclusionViolationCode)
+}
+
+// IsSerializationFailureError checks if the error is a serialization failure error
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a serialization failure error, false otherwise
+//   - *ErrorDetails: the details of the error, nil if not a serialization failure error
+func IsSerializationFailureError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCategory(err, CategorySerializationFailure)
+}
+
+// IsDeadlockDetectedError checks if the error is a deadlock detected error
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a deadlock detected error, false otherwise
+//   - *ErrorDetails: the details of the error, nil if not a deadlock detected error
+func IsDeadlockDetectedError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCategory(err, CategoryDeadlockDetected)
+}
+
+// IsLockNotAvailableError checks if the error is a lock not available error, raised by NOWAIT locks and lock timeouts
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a lock not available error, false otherwise
+//   - *ErrorDetails: the details of the error, nil if not a lock not available error
+func IsLockNotAvailableError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCode(err, LockNotAvailableCode)
+}
+
+// IsQueryCanceledError checks if the error is a query canceled error, raised by statement timeouts and cancel requests
+//
+// The canceled and timed out contexts are reported as query canceled errors too.
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a query canceled error, false otherwise
+//   - *ErrorDetails: the details of the error, nil if not a query canceled error or if it was not reported by the
+//     server
+func IsQueryCanceledError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCategory(err, CategoryQueryCanceled)
+}
+
+// IsConnectionError checks if the error is caused by a failed or lost connection to the server
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a connection error, false otherwise
+//   - *ErrorDetails: the details of the error, nil if not a connection error or if it was not reported by the server
+func IsConnectionError(err error) (bool, *ErrorDetails) {
+	return isErrorWithCategory(err, CategoryConnection)
+}
+
+// IsRetryableError checks if the error is a transient error that can be solved by retrying the transaction
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is a serialization failure or a deadlock detected error, false otherwise
+func IsRetryableError(err error) bool {
+	return Classify(err).IsRetryable()
+}
@@ -1,5 +1,41 @@
 package pgx
 
 const (
-	UniqueViolationCode = "23505"
+	// ClassConnectionException is the SQLSTATE class of connection errors
+	ClassConnectionException = "08"
+
+	// ClassDataException is the SQLSTATE class of invalid data errors
+	ClassDataException = "22"
+
+	// ClassIntegrityConstraintViolation is the SQLSTATE class of constraint violation errors
+	ClassIntegrityConstraintViolation = "23"
+
+	// ClassTransactionRollback is the SQLSTATE class of transaction rollback errors
+	ClassTransactionRollback = "40"
+
+	// ClassSyntaxErrorOrAccessRuleViolation is the SQLSTATE class of syntax and permission errors
+	ClassSyntaxErrorOrAccessRuleViolation = "42"
+
+	// ClassInsufficientResources is the SQLSTATE class of resource exhaustion errors
+	ClassInsufficientResources = "53"
+
+	// ClassOperatorIntervention is the SQLSTATE class of cancellation and shutdown errors, the ones other than
+	// the query cancellations end the session
+	ClassOperatorIntervention = "57"
+)
+
+const (
+	RestrictViolationCode    = "23001"
+	NotNullViolationCode     = "23502"
+	ForeignKeyViolationCode  = "23503"
+	UniqueViolationCode      = "23505"
+	CheckViolationCode       = "23514"
+	ExclusionViolationCode   = "23P01"
+	SerializationFailureCode = "40001"
+	DeadlockDetectedCode     = "40P01"
+	LockNotAvailableCode     = "55P03"
+	QueryCanceledCode        = "57014"
+	AdminShutdownCode        = "57P01"
+	CrashShutdownCode        = "57P02"
+	CannotConnectNowCode     = "57P03"
 )
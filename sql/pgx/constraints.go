@@ -0,0 +1,320 @@
+package pgx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// PlaceholderField is replaced by the field name in message templates
+	PlaceholderField = "{field}"
+
+	// PlaceholderValue is replaced by the offending value in message templates, when PostgreSQL reports it
+	PlaceholderValue = "{value}"
+
+	// PlaceholderConstraint is replaced by the constraint name in message templates
+	PlaceholderConstraint = "{constraint}"
+
+	// PlaceholderTable is replaced by the table name in message templates
+	PlaceholderTable = "{table}"
+
+	// PlaceholderColumn is replaced by the column name in message templates
+	PlaceholderColumn = "{column}"
+)
+
+var (
+	// detailKeyRegexp matches the key and value reported in the detail of constraint violations,
+	// such as 'Key (email)=(john@example.com) already exists.'
+	detailKeyRegexp = regexp.MustCompile(`^Key \((.+?)\)=\((.*)\)`)
+)
+
+type (
+	// ConstraintRule describes how a constraint violation is translated into a field error
+	ConstraintRule struct {
+		Field           string
+		Err             error
+		MessageTemplate string
+	}
+
+	// constraintPattern is a constraint rule matched by a regular expression
+	constraintPattern struct {
+		pattern *regexp.Regexp
+		rule    ConstraintRule
+	}
+
+	// FieldError is a constraint violation translated into a field error
+	FieldError struct {
+		Field      string   `json:"field"`
+		Message    string   `json:"message"`
+		Category   Category `json:"-"`
+		Constraint string   `json:"-"`
+		Table      string   `json:"-"`
+		Column     string   `json:"-"`
+		Value      string   `json:"-"`
+		Err        error    `json:"-"`
+		Cause      error    `json:"-"`
+	}
+
+	// ConstraintRegistry maps constraint names to field errors
+	ConstraintRegistry struct {
+		names    map[string]ConstraintRule
+		patterns []constraintPattern
+		mutex    sync.RWMutex
+	}
+)
+
+// Error returns the message of the field error
+//
+// Returns:
+//
+//   - string: the message of the field error
+func (f *FieldError) Error() string {
+	if f == nil {
+		return ""
+	}
+	if f.Message != "" {
+		return f.Message
+	}
+	if f.Err != nil {
+		return f.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", f.Field, f.Category)
+}
+
+// Unwrap returns the domain error and the original PostgreSQL error, so both can be matched with errors.Is and errors.As
+//
+// Returns:
+//
+//   - []error: the wrapped errors
+func (f *FieldError) Unwrap() []error {
+	if f == nil {
+		return nil
+	}
+	var errs []error
+	if f.Err != nil {
+		errs = append(errs, f.Err)
+	}
+	if f.Cause != nil {
+		errs = append(errs, f.Cause)
+	}
+	return errs
+}
+
+// NewConstraintRegistry creates a new constraint registry
+//
+// Returns:
+//
+//   - *ConstraintRegistry: the constraint registry
+func NewConstraintRegistry() *ConstraintRegistry {
+	return &ConstraintRegistry{
+		names: make(map[string]ConstraintRule),
+	}
+}
+
+// Register registers the rule of a constraint by its exact name
+//
+// Parameters:
+//
+//   - constraintName: the name of the constraint, or 'table.column' for not-null violations that have no constraint name
+//   - field: the name of the field reported in the field error
+//   - err: the domain error wrapped by the field error, it can be nil
+//   - messageTemplate: the message of the field error, it may contain the Placeholder* constants. If empty, the domain error message is used
+//
+// Returns:
+//
+//   - error: if the constraint name or field is empty, or if the constraint is already registered
+func (c *ConstraintRegistry) Register(
+	constraintName string,
+	field string,
+	err error,
+	messageTemplate string,
+) error {
+	if c == nil {
+		return ErrNilConstraintRegistry
+	}
+
+	// Check the constraint name and field
+	if constraintName == "" {
+		return ErrEmptyConstraintName
+	}
+	if field == "" {
+		return ErrEmptyFieldName
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Check if the constraint is already registered
+	if _, ok := c.names[constraintName]; ok {
+		return fmt.Errorf(ErrConstraintAlreadyRegistered, constraintName)
+	}
+	c.names[constraintName] = ConstraintRule{
+		Field:           field,
+		Err:             err,
+		MessageTemplate: messageTemplate,
+	}
+	return nil
+}
+
+// RegisterPattern registers the rule of the constraints whose name matches a regular expression. Patterns are
+// checked in registration order, after the exact names
+//
+// Parameters:
+//
+//   - pattern: the regular expression matched against the constraint name
+//   - field: the name of the field reported in the field error
+//   - err: the domain error wrapped by the field error, it can be nil
+//   - messageTemplate: the message of the field error, it may contain the Placeholder* constants. If empty, the domain error message is used
+//
+// Returns:
+//
+//   - error: if the pattern is nil or the field is empty
+func (c *ConstraintRegistry) RegisterPattern(
+	pattern *regexp.Regexp,
+	field string,
+	err error,
+	messageTemplate string,
+) error {
+	if c == nil {
+		return ErrNilConstraintRegistry
+	}
+
+	// Check the pattern and field
+	if pattern == nil {
+		return ErrNilConstraintPattern
+	}
+	if field == "" {
+		return ErrEmptyFieldName
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.patterns = append(
+		c.patterns, constraintPattern{
+			pattern: pattern,
+			rule: ConstraintRule{
+				Field:           field,
+				Err:             err,
+				MessageTemplate: messageTemplate,
+			},
+		},
+	)
+	return nil
+}
+
+// lookup returns the rule registered for a constraint name
+//
+// Parameters:
+//
+//   - name: the name of the constraint
+//
+// Returns:
+//
+//   - ConstraintRule: the rule of the constraint
+//   - bool: true if a rule was found, false otherwise
+func (c *ConstraintRegistry) lookup(name string) (ConstraintRule, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	// Check the exact names first
+	if rule, ok := c.names[name]; ok {
+		return rule, true
+	}
+
+	// Check the patterns
+	for _, p := range c.patterns {
+		if p.pattern.MatchString(name) {
+			return p.rule, true
+		}
+	}
+	return ConstraintRule{}, false
+}
+
+// TranslateFieldError converts a PostgreSQL constraint violation into a field error. The PostgreSQL error is
+// found with errors.As, so errors wrapped by other layers such as GORM are also translated, as long as GORM is not
+// configured to replace them with its own errors through TranslateError
+//
+// Parameters:
+//
+//   - err: the error to translate
+//
+// Returns:
+//
+//   - *FieldError: the field error, nil if the error is not a registered constraint violation
+//   - bool: true if the error was translated, false otherwise
+func (c *ConstraintRegistry) TranslateFieldError(err error) (*FieldError, bool) {
+	if c == nil || err == nil {
+		return nil, false
+	}
+
+	// Check if the error is a constraint violation
+	category := Classify(err)
+	if !category.IsConstraintViolation() {
+		return nil, false
+	}
+	details, _ := GetErrorDetails(err)
+
+	// Get the rule of the constraint, not-null violations are looked up by their table and column
+	name := details.ConstraintName
+	if name == "" && details.ColumnName != "" {
+		name = details.TableName + "." + details.ColumnName
+	}
+	rule, ok := c.lookup(name)
+	if !ok {
+		return nil, false
+	}
+
+	// Get the column and value reported in the detail
+	column := details.ColumnName
+	var value string
+	if matches := detailKeyRegexp.FindStringSubmatch(details.Detail); matches != nil {
+		if column == "" {
+			column = matches[1]
+		}
+		value = matches[2]
+	}
+
+	// Build the message
+	message := rule.MessageTemplate
+	if message == "" && rule.Err != nil {
+		message = rule.Err.Error()
+	}
+	message = strings.NewReplacer(
+		PlaceholderField, rule.Field,
+		PlaceholderValue, value,
+		PlaceholderConstraint, details.ConstraintName,
+		PlaceholderTable, details.TableName,
+		PlaceholderColumn, column,
+	).Replace(message)
+
+	return &FieldError{
+		Field:      rule.Field,
+		Message:    message,
+		Category:   category,
+		Constraint: details.ConstraintName,
+		Table:      details.TableName,
+		Column:     column,
+		Value:      value,
+		Err:        rule.Err,
+		Cause:      err,
+	}, true
+}
+
+// Translate converts a PostgreSQL constraint violation into a *FieldError, leaving any other error unchanged
+//
+// Parameters:
+//
+//   - err: the error to translate
+//
+// Returns:
+//
+//   - error: the *FieldError if the error is a registered constraint violation, the original error otherwise
+func (c *ConstraintRegistry) Translate(err error) error {
+	if fieldErr, ok := c.TranslateFieldError(err); ok {
+		return fieldErr
+	}
+	return err
+}
@@ -0,0 +1,251 @@
+package pgx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	errEmailTaken     = errors.New("email is already taken")
+	errUnknownCountry = errors.New("country does not exist")
+	errNameRequired   = errors.New("name is required")
+	errInvalidAmount  = errors.New("amount is invalid")
+)
+
+// newTestRegistry creates a constraint registry with the rules used by the tests
+func newTestRegistry(t *testing.T) *ConstraintRegistry {
+	t.Helper()
+	registry := NewConstraintRegistry()
+	if err := registry.Register(
+		"users_email_key",
+		"email",
+		errEmailTaken,
+		"{field} '{value}' is already taken",
+	); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.Register("users.name", "name", errNameRequired, ""); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.RegisterPattern(
+		regexp.MustCompile(`_country_id_fkey$`),
+		"country_id",
+		errUnknownCountry,
+		"",
+	); err != nil {
+		t.Fatalf("RegisterPattern() error = %v", err)
+	}
+	if err := registry.RegisterPattern(
+		regexp.MustCompile(`_amount_check$`),
+		"amount",
+		errInvalidAmount,
+		"{table}.{column} failed {constraint}",
+	); err != nil {
+		t.Fatalf("RegisterPattern() error = %v", err)
+	}
+	return registry
+}
+
+func TestConstraintRegistryTranslateFieldError(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantOK      bool
+		wantField   string
+		wantMessage string
+		wantValue   string
+		wantErr     error
+		wantCat     Category
+	}{
+		{
+			name: "unique violation by exact name",
+			err: &pgconn.PgError{
+				Code:           UniqueViolationCode,
+				ConstraintName: "users_email_key",
+				TableName:      "users",
+				Detail:         "Key (email)=(john@example.com) already exists.",
+			},
+			wantOK:      true,
+			wantField:   "email",
+			wantMessage: "email 'john@example.com' is already taken",
+			wantValue:   "john@example.com",
+			wantErr:     errEmailTaken,
+			wantCat:     CategoryUniqueViolation,
+		},
+		{
+			name: "wrapped foreign key violation by pattern",
+			err: fmt.Errorf(
+				"create address: %w",
+				&pgconn.PgError{
+					Code:           ForeignKeyViolationCode,
+					ConstraintName: "addresses_country_id_fkey",
+					TableName:      "addresses",
+					Detail:         `Key (country_id)=(99) is not present in table "countries".`,
+				},
+			),
+			wantOK:      true,
+			wantField:   "country_id",
+			wantMessage: errUnknownCountry.Error(),
+			wantValue:   "99",
+			wantErr:     errUnknownCountry,
+			wantCat:     CategoryForeignKeyViolation,
+		},
+		{
+			name: "not-null violation by table and column",
+			err: &pgconn.PgError{
+				Code:       NotNullViolationCode,
+				TableName:  "users",
+				ColumnName: "name",
+			},
+			wantOK:      true,
+			wantField:   "name",
+			wantMessage: errNameRequired.Error(),
+			wantErr:     errNameRequired,
+			wantCat:     CategoryNotNullViolation,
+		},
+		{
+			name: "check violation with placeholders",
+			err: &pgconn.PgError{
+				Code:           CheckViolationCode,
+				ConstraintName: "orders_amount_check",
+				TableName:      "orders",
+				ColumnName:     "amount",
+			},
+			wantOK:      true,
+			wantField:   "amount",
+			wantMessage: "orders.amount failed orders_amount_check",
+			wantErr:     errInvalidAmount,
+			wantCat:     CategoryCheckViolation,
+		},
+		{
+			name: "unregistered constraint",
+			err: &pgconn.PgError{
+				Code:           UniqueViolationCode,
+				ConstraintName: "users_username_key",
+			},
+		},
+		{
+			name: "not a constraint violation",
+			err: &pgconn.PgError{
+				Code:           SerializationFailureCode,
+				ConstraintName: "users_email_key",
+			},
+		},
+		{name: "not a PostgreSQL error", err: errors.New("boom")},
+		{name: "nil error"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				fieldErr, ok := registry.TranslateFieldError(tt.err)
+				if ok != tt.wantOK {
+					t.Fatalf("TranslateFieldError() ok = %v, want %v", ok, tt.wantOK)
+				}
+
+				// Check that the untranslated errors are returned unchanged
+				if !ok {
+					if translated := registry.Translate(tt.err); translated != tt.err {
+						t.Errorf("Translate() = %v, want the original error", translated)
+					}
+					return
+				}
+
+				if fieldErr.Field != tt.wantField ||
+					fieldErr.Message != tt.wantMessage ||
+					fieldErr.Value != tt.wantValue ||
+					fieldErr.Category != tt.wantCat {
+					t.Errorf(
+						"TranslateFieldError() = %+v, want field %q, message %q, value %q and category %s",
+						fieldErr,
+						tt.wantField,
+						tt.wantMessage,
+						tt.wantValue,
+						tt.wantCat,
+					)
+				}
+
+				// Check that both the domain and the PostgreSQL errors can be matched
+				translated := registry.Translate(tt.err)
+				if !errors.Is(translated, tt.wantErr) {
+					t.Errorf("Translate() error = %v, want %v", translated, tt.wantErr)
+				}
+				var pgErr *pgconn.PgError
+				if !errors.As(translated, &pgErr) {
+					t.Errorf("Translate() error = %v, want a *pgconn.PgError cause", translated)
+				}
+			},
+		)
+	}
+}
+
+func TestConstraintRegistryRegister(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	tests := []struct {
+		name       string
+		registerFn func(*ConstraintRegistry) error
+		errIs      error
+	}{
+		{
+			name: "empty constraint name",
+			registerFn: func(c *ConstraintRegistry) error {
+				return c.Register("", "email", nil, "")
+			},
+			errIs: ErrEmptyConstraintName,
+		},
+		{
+			name: "empty field name",
+			registerFn: func(c *ConstraintRegistry) error {
+				return c.Register("users_phone_key", "", nil, "")
+			},
+			errIs: ErrEmptyFieldName,
+		},
+		{
+			name: "already registered",
+			registerFn: func(c *ConstraintRegistry) error {
+				return c.Register("users_email_key", "email", nil, "")
+			},
+		},
+		{
+			name: "nil pattern",
+			registerFn: func(c *ConstraintRegistry) error {
+				return c.RegisterPattern(nil, "email", nil, "")
+			},
+			errIs: ErrNilConstraintPattern,
+		},
+		{
+			name: "pattern with an empty field name",
+			registerFn: func(c *ConstraintRegistry) error {
+				return c.RegisterPattern(regexp.MustCompile(`_key$`), "", nil, "")
+			},
+			errIs: ErrEmptyFieldName,
+		},
+		{
+			name: "nil registry",
+			registerFn: func(*ConstraintRegistry) error {
+				var c *ConstraintRegistry
+				return c.Register("users_email_key", "email", nil, "")
+			},
+			errIs: ErrNilConstraintRegistry,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := tt.registerFn(registry)
+				if err == nil {
+					t.Fatalf("error = nil, want error")
+				}
+				if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+					t.Errorf("error = %v, want %v", err, tt.errIs)
+				}
+			},
+		)
+	}
+}
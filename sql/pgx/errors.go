@@ -0,0 +1,16 @@
+package pgx
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilConstraintRegistry = errors.New("constraint registry cannot be nil")
+	ErrEmptyConstraintName   = errors.New("constraint name cannot be empty")
+	ErrNilConstraintPattern  = errors.New("constraint pattern cannot be nil")
+	ErrEmptyFieldName        = errors.New("field name cannot be empty")
+)
+
+const (
+	ErrConstraintAlreadyRegistered = "constraint '%s' is already registered"
+)
@@ -0,0 +1,347 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+type (
+	// AdvisoryLock is a session-level PostgreSQL advisory lock held on a dedicated connection
+	AdvisoryLock struct {
+		conn *pgxpool.Conn
+		key  gosql.AdvisoryLockKey
+	}
+)
+
+// lock acquires a session-level advisory lock on a dedicated connection
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - key: the lock key
+//   - wait: true to wait until the lock is released by other sessions, false to try to acquire it once
+//
+// Returns:
+//
+//   - *AdvisoryLock: the advisory lock
+//   - error: if any error occurs, or gosql.ErrAdvisoryLockNotAcquired if it is held by another session
+func lock(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	key gosql.AdvisoryLockKey,
+	wait bool,
+) (*AdvisoryLock, error) {
+	// Check if the pool is nil
+	if pool == nil {
+		return nil, godatabases.ErrNilPool
+	}
+
+	// Get a dedicated connection, since session-level locks belong to it
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Acquire the lock
+	acquired := true
+	if wait {
+		_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", int64(key))
+	} else {
+		err = conn.QueryRow(
+			ctx,
+			"SELECT pg_try_advisory_lock($1)",
+			int64(key),
+		).Scan(&acquired)
+	}
+	if err != nil {
+		discardConn(conn)
+		return nil, err
+	}
+	if !acquired {
+		conn.Release()
+		return nil, gosql.ErrAdvisoryLockNotAcquired
+	}
+	return &AdvisoryLock{conn: conn, key: key}, nil
+}
+
+// TryLock tries to acquire a session-level advisory lock without waiting
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - key: the lock key
+//
+// Returns:
+//
+//   - *AdvisoryLock: the advisory lock
+//   - error: if any error occurs, or gosql.ErrAdvisoryLockNotAcquired if it is held by another session
+func TryLock(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	key gosql.AdvisoryLockKey,
+) (*AdvisoryLock, error) {
+	return lock(ctx, pool, key, false)
+}
+
+// Lock acquires a session-level advisory lock, waiting until it is released by other sessions or the context
+// is done
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - key: the lock key
+//
+// Returns:
+//
+//   - *AdvisoryLock: the advisory lock
+//   - error: if any error occurs
+func Lock(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	key gosql.AdvisoryLockKey,
+) (*AdvisoryLock, error) {
+	return lock(ctx, pool, key, true)
+}
+
+// Key returns the key of the advisory lock
+//
+// Returns:
+//
+//   - gosql.AdvisoryLockKey: the lock key
+func (a *AdvisoryLock) Key() gosql.AdvisoryLockKey {
+	if a == nil {
+		return 0
+	}
+	return a.key
+}
+
+// Unlock releases the advisory lock and its dedicated connection
+//
+// If the lock could not be released, the connection is discarded, which ends the session and releases the lock.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - error: if the lock could not be released
+func (a *AdvisoryLock) Unlock(ctx context.Context) error {
+	if a == nil || a.conn == nil {
+		return gosql.ErrNilAdvisoryLock
+	}
+
+	// Release the lock
+	var released bool
+	err := a.conn.QueryRow(
+		ctx,
+		"SELECT pg_advisory_unlock($1)",
+		int64(a.key),
+	).Scan(&released)
+	if err == nil && !released {
+		err = gosql.ErrAdvisoryLockNotHeld
+	}
+
+	// Release the connection, discarding it if the lock could not be released
+	if err != nil {
+		discardConn(a.conn)
+	} else {
+		a.conn.Release()
+	}
+	a.conn = nil
+	return err
+}
+
+// discardConn closes the connection without returning it to the pool, ending its session
+//
+// Parameters:
+//
+//   - conn: the connection to discard
+func discardConn(conn *pgxpool.Conn) {
+	//nolint:contextcheck // The connection must be closed even if the context is done
+	_ = conn.Hijack().Close(context.Background())
+}
+
+// runLocked runs the function while holding the advisory lock, releasing it once the function returns
+//
+// If the context is done while the function runs, the dedicated connection is discarded, which ends the
+// session and releases the lock without waiting for the function to return.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - advisoryLock: the acquired advisory lock
+//   - fn: the function to run
+//
+// Returns:
+//
+//   - error: the error returned by the function, or if the lock was released before the function returned
+func runLocked(
+	ctx context.Context,
+	advisoryLock *AdvisoryLock,
+	fn gosql.LockFn,
+) (err error) {
+	// Discard the connection once the context is done
+	conn := advisoryLock.conn
+	stop := context.AfterFunc(
+		ctx, func() {
+			discardConn(conn)
+		},
+	)
+
+	// Release the lock once the function returns
+	defer func() {
+		// Check if the connection was already discarded
+		if !stop() {
+			advisoryLock.conn = nil
+			if err == nil {
+				err = fmt.Errorf("%w: %w", gosql.ErrAdvisoryLockReleased, ctx.Err())
+			}
+			return
+		}
+
+		//nolint:contextcheck // The lock must be released even if the context is done
+		if unlockErr := advisoryLock.Unlock(context.Background()); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// WithLock runs the function while holding a session-level advisory lock, waiting to acquire it
+//
+// If the context is done while waiting, the wait is canceled and the dedicated connection is released. If it
+// is done while the function runs, the dedicated connection is discarded, which releases the lock without
+// waiting for the function to return, and gosql.ErrAdvisoryLockReleased is returned if the function did not
+// fail. Otherwise, the lock is released once the function returns.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - key: the lock key
+//   - fn: the function to run
+//
+// Returns:
+//
+//   - error: if the lock could not be acquired or was released early, or the error returned by the function
+func WithLock(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	key gosql.AdvisoryLockKey,
+	fn gosql.LockFn,
+) error {
+	// Check if the function is nil
+	if fn == nil {
+		return gosql.ErrNilLockFn
+	}
+
+	// Acquire the lock
+	advisoryLock, err := Lock(ctx, pool, key)
+	if err != nil {
+		return err
+	}
+	return runLocked(ctx, advisoryLock, fn)
+}
+
+// TryWithLock runs the function only if the session-level advisory lock can be acquired without waiting
+//
+// The lock is released as done by WithLock.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - key: the lock key
+//   - fn: the function to run
+//
+// Returns:
+//
+//   - bool: true if the lock was acquired and the function was run, false otherwise
+//   - error: if the lock could not be checked or was released early, or the error returned by the function
+func TryWithLock(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	key gosql.AdvisoryLockKey,
+	fn gosql.LockFn,
+) (bool, error) {
+	// Check if the function is nil
+	if fn == nil {
+		return false, gosql.ErrNilLockFn
+	}
+
+	// Try to acquire the lock, it is not an error if it is held by another session
+	advisoryLock, err := TryLock(ctx, pool, key)
+	if errors.Is(err, gosql.ErrAdvisoryLockNotAcquired) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, runLocked(ctx, advisoryLock, fn)
+}
+
+// XactLock acquires a transaction-level advisory lock, waiting until it is released by other sessions. The lock
+// is released when the transaction ends
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - tx: the transaction, such as the one passed to the function of CreateTransaction
+//   - key: the lock key
+//
+// Returns:
+//
+//   - error: if any error occurs
+func XactLock(ctx context.Context, tx pgx.Tx, key gosql.AdvisoryLockKey) error {
+	// Check if the transaction is nil
+	if tx == nil {
+		return ErrNilTransaction
+	}
+
+	_, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", int64(key))
+	return err
+}
+
+// TryXactLock tries to acquire a transaction-level advisory lock without waiting. The lock is released when the
+// transaction ends
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - tx: the transaction, such as the one passed to the function of CreateTransaction
+//   - key: the lock key
+//
+// Returns:
+//
+//   - bool: true if the lock was acquired, false otherwise
+//   - error: if any error occurs
+func TryXactLock(
+	ctx context.Context,
+	tx pgx.Tx,
+	key gosql.AdvisoryLockKey,
+) (bool, error) {
+	// Check if the transaction is nil
+	if tx == nil {
+		return false, ErrNilTransaction
+	}
+
+	var acquired bool
+	err := tx.QueryRow(
+		ctx,
+		"SELECT pg_try_advisory_xact_lock($1)",
+		int64(key),
+	).Scan(&acquired)
+	return acquired, err
+}
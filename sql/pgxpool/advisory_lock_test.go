@@ -0,0 +1,315 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/internal/pgtest"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+type (
+	// advisoryLockServer is the state of the fake server answering the advisory lock statements
+	advisoryLockServer struct {
+		acquired bool
+		released bool
+		lockErr  error
+	}
+)
+
+// handle answers the advisory lock statements
+func (a advisoryLockServer) handle(query string) (*pgtest.Result, error) {
+	switch {
+	case strings.Contains(query, "pg_try_advisory_lock"):
+		return pgtest.BoolResult("pg_try_advisory_lock", a.acquired), nil
+	case strings.Contains(query, "pg_advisory_lock"):
+		if a.lockErr != nil {
+			return nil, a.lockErr
+		}
+		return &pgtest.Result{CommandTag: "SELECT 1"}, nil
+	case strings.Contains(query, "pg_advisory_unlock"):
+		return pgtest.BoolResult("pg_advisory_unlock", a.released), nil
+	default:
+		return &pgtest.Result{}, nil
+	}
+}
+
+// newAdvisoryLockPool creates a pool served by a fake server answering the advisory lock statements
+func newAdvisoryLockPool(t *testing.T, state advisoryLockServer) (*pgxpool.Pool, *pgtest.Server) {
+	t.Helper()
+	pool, server, err := pgtest.NewPool(context.Background(), state.handle)
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool, server
+}
+
+// unlocked checks if the advisory lock was released through the unlock statement
+func unlocked(server *pgtest.Server) bool {
+	return slices.ContainsFunc(
+		server.Statements(), func(statement string) bool {
+			return strings.Contains(statement, "pg_advisory_unlock")
+		},
+	)
+}
+
+// waitForDiscardedConnections waits until every connection of the server is closed
+func waitForDiscardedConnections(t *testing.T, pool *pgxpool.Pool, server *pgtest.Server) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for server.OpenConnections() != 0 || pool.Stat().TotalConns() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf(
+				"open connections = %d, pool connections = %d, want 0",
+				server.OpenConnections(),
+				pool.Stat().TotalConns(),
+			)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		acquired bool
+		wantErr  error
+	}{
+		{name: "acquired", acquired: true},
+		{name: "held by another session", wantErr: gosql.ErrAdvisoryLockNotAcquired},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				pool, _ := newAdvisoryLockPool(t, advisoryLockServer{acquired: tt.acquired, released: true})
+
+				advisoryLock, err := TryLock(context.Background(), pool, gosql.NewAdvisoryLockKey("job"))
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("TryLock() error = %v, want %v", err, tt.wantErr)
+				}
+				if (advisoryLock != nil) != tt.acquired {
+					t.Fatalf("TryLock() lock = %v, want acquired %v", advisoryLock, tt.acquired)
+				}
+				if advisoryLock != nil {
+					if err = advisoryLock.Unlock(context.Background()); err != nil {
+						t.Errorf("Unlock() error = %v", err)
+					}
+					if err = advisoryLock.Unlock(context.Background()); !errors.Is(err, gosql.ErrNilAdvisoryLock) {
+						t.Errorf("second Unlock() error = %v, want %v", err, gosql.ErrNilAdvisoryLock)
+					}
+				}
+
+				// Check that the dedicated connection was returned to the pool
+				if stat := pool.Stat(); stat.AcquiredConns() != 0 || stat.IdleConns() != 1 {
+					t.Errorf(
+						"acquired connections = %d, idle connections = %d, want 0 and 1",
+						stat.AcquiredConns(),
+						stat.IdleConns(),
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestLockFailures(t *testing.T) {
+	errLock := &pgconn.PgError{Code: "57014", Message: "canceling statement due to lock timeout"}
+
+	tests := []struct {
+		name     string
+		state    advisoryLockServer
+		lockFn   func(pool *pgxpool.Pool) error
+		wantErr  error
+		wantCode string
+	}{
+		{
+			name:  "lock not acquired",
+			state: advisoryLockServer{lockErr: errLock},
+			lockFn: func(pool *pgxpool.Pool) error {
+				_, err := Lock(context.Background(), pool, gosql.NewAdvisoryLockKey("job"))
+				return err
+			},
+			wantCode: errLock.Code,
+		},
+		{
+			name:  "lock not held when released",
+			state: advisoryLockServer{acquired: true},
+			lockFn: func(pool *pgxpool.Pool) error {
+				advisoryLock, err := Lock(context.Background(), pool, gosql.NewAdvisoryLockKey("job"))
+				if err != nil {
+					return err
+				}
+				return advisoryLock.Unlock(context.Background())
+			},
+			wantErr: gosql.ErrAdvisoryLockNotHeld,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				pool, server := newAdvisoryLockPool(t, tt.state)
+
+				// Check the error, by its code if it was sent by the server
+				err := tt.lockFn(pool)
+				var pgErr *pgconn.PgError
+				switch {
+				case tt.wantCode != "":
+					if !errors.As(err, &pgErr) || pgErr.Code != tt.wantCode {
+						t.Fatalf("error = %v, want code %s", err, tt.wantCode)
+					}
+				case !errors.Is(err, tt.wantErr):
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+
+				// Check that the dedicated connection was discarded, ending its session
+				waitForDiscardedConnections(t, pool, server)
+			},
+		)
+	}
+
+	// Check that a nil pool is reported
+	if _, err := Lock(context.Background(), nil, 1); !errors.Is(err, godatabases.ErrNilPool) {
+		t.Errorf("Lock() error = %v, want %v", err, godatabases.ErrNilPool)
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	errFn := errors.New("function failed")
+
+	tests := []struct {
+		name       string
+		fn         func(cancel context.CancelFunc) error
+		wantErr    []error
+		wantUnlock bool
+	}{
+		{
+			name: "releases the lock",
+			fn: func(context.CancelFunc) error {
+				return nil
+			},
+			wantUnlock: true,
+		},
+		{
+			name: "releases the lock on failure",
+			fn: func(context.CancelFunc) error {
+				return errFn
+			},
+			wantErr:    []error{errFn},
+			wantUnlock: true,
+		},
+		{
+			name: "discards the connection once the context is done",
+			fn: func(cancel context.CancelFunc) error {
+				cancel()
+				return nil
+			},
+			wantErr: []error{gosql.ErrAdvisoryLockReleased, context.Canceled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				pool, server := newAdvisoryLockPool(t, advisoryLockServer{acquired: true, released: true})
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				err := WithLock(
+					ctx,
+					pool,
+					gosql.NewAdvisoryLockKey("job"),
+					func(context.Context) error {
+						return tt.fn(cancel)
+					},
+				)
+				for _, wantErr := range tt.wantErr {
+					if !errors.Is(err, wantErr) {
+						t.Errorf("WithLock() error = %v, want %v", err, wantErr)
+					}
+				}
+				if len(tt.wantErr) == 0 && err != nil {
+					t.Errorf("WithLock() error = %v", err)
+				}
+
+				// Check how the lock was released
+				if got := unlocked(server); got != tt.wantUnlock {
+					t.Errorf("unlocked = %v, want %v", got, tt.wantUnlock)
+				}
+				if !tt.wantUnlock {
+					waitForDiscardedConnections(t, pool, server)
+				}
+			},
+		)
+	}
+}
+
+func TestTryWithLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		acquired bool
+		fn       gosql.LockFn
+		wantErr  error
+	}{
+		{
+			name:     "runs the function",
+			acquired: true,
+			fn: func(context.Context) error {
+				return nil
+			},
+		},
+		{
+			name: "skips the function",
+			fn: func(context.Context) error {
+				return errors.New("the function should not run")
+			},
+		},
+		{
+			name:     "nil function",
+			acquired: true,
+			wantErr:  gosql.ErrNilLockFn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				pool, server := newAdvisoryLockPool(t, advisoryLockServer{acquired: tt.acquired, released: true})
+
+				ran, err := TryWithLock(
+					context.Background(),
+					pool,
+					gosql.NewAdvisoryLockKey("job"),
+					tt.fn,
+				)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("TryWithLock() error = %v, want %v", err, tt.wantErr)
+				}
+				wantRan := tt.acquired && tt.fn != nil
+				if ran != wantRan {
+					t.Errorf("TryWithLock() ran = %v, want %v", ran, wantRan)
+				}
+
+				// Check that only the acquired locks are released
+				if got := unlocked(server); got != wantRan {
+					t.Errorf("unlocked = %v, want %v", got, wantRan)
+				}
+			},
+		)
+	}
+}
+
+func TestXactLockNilTransaction(t *testing.T) {
+	if err := XactLock(context.Background(), nil, 1); !errors.Is(err, ErrNilTransaction) {
+		t.Errorf("XactLock() error = %v, want %v", err, ErrNilTransaction)
+	}
+	if _, err := TryXactLock(context.Background(), nil, 1); !errors.Is(err, ErrNilTransaction) {
+		t.Errorf("TryXactLock() error = %v, want %v", err, ErrNilTransaction)
+	}
+}
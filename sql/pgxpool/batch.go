@@ -0,0 +1,362 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+type (
+	// ExecCallback is the function type called with the command tag of a queued exec statement
+	ExecCallback func(commandTag pgconn.CommandTag) error
+
+	// RowCallback is the function type called with the row of a queued query row statement
+	RowCallback func(row pgx.Row) error
+
+	// RowsCallback is the function type called with the rows of a queued query statement, which are closed
+	// once it returns
+	RowsCallback func(rows pgx.Rows) error
+
+	// batchSender is the interface implemented by both *pgxpool.Pool and pgx.Tx to send batches
+	batchSender interface {
+		SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	}
+
+	// batchStatement is a statement queued in a batch with the function that reads its result
+	batchStatement struct {
+		query  string
+		args   []any
+		handle func(results pgx.BatchResults) error
+	}
+
+	// Batch queues statements to be sent to the database in a single round trip
+	Batch struct {
+		statements []batchStatement
+	}
+
+	// BatchError is the error of a statement of a batch
+	BatchError struct {
+		Index int
+		Query string
+		Err   error
+	}
+)
+
+// Error returns the message of the batch error
+//
+// Returns:
+//
+//   - string: the message of the batch error
+func (b *BatchError) Error() string {
+	return fmt.Errorf(ErrBatchStatementFailed, b.Index, b.Err).Error()
+}
+
+// Unwrap returns the error of the statement
+//
+// Returns:
+//
+//   - error: the error of the statement
+func (b *BatchError) Unwrap() error {
+	return b.Err
+}
+
+// NewBatch creates a new empty batch
+//
+// Returns:
+//
+//   - *Batch: the batch
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// Len returns the number of queued statements
+//
+// Returns:
+//
+//   - int: the number of queued statements
+func (b *Batch) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.statements)
+}
+
+// queue queues a statement with the function that reads its result
+//
+// Parameters:
+//
+//   - query: the statement to queue
+//   - args: the arguments of the statement
+//   - handle: the function that reads the result of the statement
+//
+// Returns:
+//
+//   - error: if the batch is nil
+func (b *Batch) queue(
+	query string,
+	args []any,
+	handle func(results pgx.BatchResults) error,
+) error {
+	if b == nil {
+		return ErrNilBatch
+	}
+
+	b.statements = append(
+		b.statements, batchStatement{
+			query:  query,
+			args:   args,
+			handle: handle,
+		},
+	)
+	return nil
+}
+
+// QueueExec queues a statement whose command tag is passed to the callback
+//
+// Parameters:
+//
+//   - fn: the callback called with the command tag, it can be nil
+//   - query: the statement to queue
+//   - args: the arguments of the statement
+//
+// Returns:
+//
+//   - error: if the batch is nil
+func (b *Batch) QueueExec(fn ExecCallback, query string, args ...any) error {
+	return b.queue(
+		query, args, func(results pgx.BatchResults) error {
+			commandTag, err := results.Exec()
+			if err != nil || fn == nil {
+				return err
+			}
+			return fn(commandTag)
+		},
+	)
+}
+
+// QueueQueryRow queues a query whose single row is passed to the callback, which must scan it
+//
+// Parameters:
+//
+//   - fn: the callback called with the row, the row is discarded if it is nil
+//   - query: the query to queue
+//   - args: the arguments of the query
+//
+// Returns:
+//
+//   - error: if the batch is nil
+func (b *Batch) QueueQueryRow(fn RowCallback, query string, args ...any) error {
+	// Discard the row if there is no callback
+	if fn == nil {
+		return b.QueueQuery(nil, query, args...)
+	}
+
+	return b.queue(
+		query, args, func(results pgx.BatchResults) error {
+			return fn(results.QueryRow())
+		},
+	)
+}
+
+// QueueQuery queues a query whose rows are passed to the callback
+//
+// Parameters:
+//
+//   - fn: the callback called with the rows, it can be nil
+//   - query: the query to queue
+//   - args: the arguments of the query
+//
+// Returns:
+//
+//   - error: if the batch is nil
+func (b *Batch) QueueQuery(fn RowsCallback, query string, args ...any) error {
+	return b.queue(
+		query, args, func(results pgx.BatchResults) error {
+			rows, err := results.Query()
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			// Call the callback
+			if fn != nil {
+				if err = fn(rows); err != nil {
+					return err
+				}
+			}
+			rows.Close()
+			return rows.Err()
+		},
+	)
+}
+
+// QueueOne queues a query whose single row is scanned into a struct by ScanStruct, matching the columns with
+// the 'db' tags of its fields
+//
+// Parameters:
+//
+//   - b: the batch
+//   - fn: the callback called with the scanned struct
+//   - query: the query to queue
+//   - args: the arguments of the query
+//
+// Returns:
+//
+//   - error: if the batch is nil
+func QueueOne[T any](b *Batch, fn func(value *T) error, query string, args ...any) error {
+	return b.QueueQuery(
+		func(rows pgx.Rows) error {
+			value, err := pgx.CollectOneRow(rows, ScanStruct[T])
+			if err != nil || fn == nil {
+				return err
+			}
+			return fn(value)
+		}, query, args...,
+	)
+}
+
+// QueueAll queues a query whose rows are scanned into structs by ScanStruct, matching the columns with the 'db'
+// tags of their fields
+//
+// Parameters:
+//
+//   - b: the batch
+//   - fn: the callback called with the scanned structs
+//   - query: the query to queue
+//   - args: the arguments of the query
+//
+// Returns:
+//
+//   - error: if the batch is nil
+func QueueAll[T any](b *Batch, fn func(values []*T) error, query string, args ...any) error {
+	return b.QueueQuery(
+		func(rows pgx.Rows) error {
+			values, err := pgx.CollectRows(rows, ScanStruct[T])
+			if err != nil || fn == nil {
+				return err
+			}
+			return fn(values)
+		}, query, args...,
+	)
+}
+
+// send sends the batch and reads the result of each statement in order
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - sender: the pool or transaction to send the batch with
+//
+// Returns:
+//
+//   - error: the joined *BatchError of the failed statements, nil if every statement succeeded
+func (b *Batch) send(ctx context.Context, sender batchSender) (err error) {
+	// Start the batch span
+	ctx, span := telemetry.StartSpan(
+		ctx,
+		telemetry.SystemPostgreSQL,
+		telemetry.OperationBatch,
+		"",
+	)
+	defer func() {
+		telemetry.EndSpan(span, err)
+	}()
+
+	// Build the batch
+	batch := &pgx.Batch{}
+	for _, statement := range b.statements {
+		batch.Queue(statement.query, statement.args...)
+	}
+
+	// Send the batch and read the results, the statements after a failed one usually fail too
+	results := sender.SendBatch(ctx, batch)
+	var errs []error
+	for i, statement := range b.statements {
+		if handleErr := statement.handle(results); handleErr != nil {
+			errs = append(
+				errs, &BatchError{
+					Index: i,
+					Query: statement.query,
+					Err:   handleErr,
+				},
+			)
+		}
+	}
+
+	// Close the batch results
+	if closeErr := results.Close(); closeErr != nil && len(errs) == 0 {
+		errs = append(errs, closeErr)
+	}
+	return errors.Join(errs...)
+}
+
+// SendBatch sends the queued statements in a single round trip
+//
+// If the context carries an active transaction, the batch is sent within it. Otherwise, the statements are
+// run in an implicit transaction, so a failed statement rolls back the previous ones.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - b: the batch to send
+//
+// Returns:
+//
+//   - error: the joined *BatchError of the failed statements, nil if every statement succeeded
+func SendBatch(ctx context.Context, pool *pgxpool.Pool, b *Batch) error {
+	// Check if the pool or the batch is nil
+	if pool == nil {
+		return godatabases.ErrNilPool
+	}
+	if b == nil {
+		return ErrNilBatch
+	}
+
+	// Check if the batch is empty
+	if b.Len() == 0 {
+		return nil
+	}
+
+	// Check if there is an active transaction in the context
+	if tx, ok := GetTxFromContext(ctx); ok {
+		return b.send(ctx, tx)
+	}
+	return b.send(ctx, pool)
+}
+
+// SendBatchInTransaction sends the queued statements in a single round trip within a transaction created by
+// CreateTransaction, or within a savepoint if the context already carries an active transaction
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - b: the batch to send
+//
+// Returns:
+//
+//   - error: the joined *BatchError of the failed statements, or the transaction error
+func SendBatchInTransaction(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	b *Batch,
+) error {
+	// Check if the batch is nil
+	if b == nil {
+		return ErrNilBatch
+	}
+
+	return CreateTransaction(
+		ctx, pool, func(ctx context.Context, tx pgx.Tx) error {
+			return b.send(ctx, tx)
+		},
+	)
+}
@@ -0,0 +1,319 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type (
+	// batchResult is the result of a statement of a fake batch
+	batchResult struct {
+		commandTag pgconn.CommandTag
+		rows       *valueRows
+		err        error
+	}
+
+	// fakeBatchResults are batch results that return the given results in order
+	fakeBatchResults struct {
+		results []batchResult
+		next    int
+		closed  bool
+	}
+
+	// fakeBatchSender sends the batches returning the given results, recording the queued statements
+	fakeBatchSender struct {
+		results    []batchResult
+		statements []string
+		batch      *fakeBatchResults
+	}
+
+	// valueRows are rows that yield the given values for the given columns
+	valueRows struct {
+		pgx.Rows
+		columns []string
+		values  [][]any
+		current []any
+		closed  bool
+	}
+
+	// batchUser is the struct scanned from the batch queries
+	batchUser struct {
+		ID    int64  `db:"id"`
+		Name  string `db:"name"`
+		Email string
+	}
+)
+
+func (f *fakeBatchResults) result() batchResult {
+	result := f.results[f.next]
+	f.next++
+	return result
+}
+
+func (f *fakeBatchResults) Exec() (pgconn.CommandTag, error) {
+	result := f.result()
+	return result.commandTag, result.err
+}
+
+func (f *fakeBatchResults) Query() (pgx.Rows, error) {
+	result := f.result()
+	if result.err != nil {
+		return nil, result.err
+	}
+	if result.rows == nil {
+		return &valueRows{}, nil
+	}
+	return result.rows, nil
+}
+
+func (f *fakeBatchResults) QueryRow() pgx.Row {
+	result := f.result()
+	return errRow{err: result.err}
+}
+
+func (f *fakeBatchResults) Close() error {
+	f.closed = true
+	return nil
+}
+
+func (f *fakeBatchSender) SendBatch(_ context.Context, b *pgx.Batch) pgx.BatchResults {
+	for _, query := range b.QueuedQueries {
+		f.statements = append(f.statements, query.SQL)
+	}
+	f.batch = &fakeBatchResults{results: f.results}
+	return f.batch
+}
+
+func (v *valueRows) Next() bool {
+	if v.closed || len(v.values) == 0 {
+		return false
+	}
+	v.current, v.values = v.values[0], v.values[1:]
+	return true
+}
+
+func (v *valueRows) Scan(dest ...any) error {
+	for i, value := range v.current {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func (v *valueRows) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(v.columns))
+	for i, column := range v.columns {
+		fields[i].Name = column
+	}
+	return fields
+}
+
+func (v *valueRows) Err() error {
+	return nil
+}
+
+func (v *valueRows) Close() {
+	v.closed = true
+}
+
+func (v *valueRows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag("SELECT")
+}
+
+func TestBatchSendErrors(t *testing.T) {
+	errStatement := errors.New("statement failed")
+	errCallback := errors.New("callback failed")
+
+	// Queue statements whose results or callbacks fail at known positions
+	b := NewBatch()
+	var commandTags []string
+	execFn := func(commandTag pgconn.CommandTag) error {
+		commandTags = append(commandTags, commandTag.String())
+		return nil
+	}
+	for _, err := range []error{
+		b.QueueExec(execFn, "INSERT INTO users VALUES (1)"),
+		b.QueueExec(execFn, "INSERT INTO users VALUES (2)"),
+		b.QueueQueryRow(func(row pgx.Row) error { return row.Scan() }, "SELECT 1"),
+		b.QueueQuery(func(pgx.Rows) error { return errCallback }, "SELECT 2"),
+		b.QueueExec(nil, "DELETE FROM users"),
+	} {
+		if err != nil {
+			t.Fatalf("Queue() error = %v", err)
+		}
+	}
+	if b.Len() != 5 {
+		t.Fatalf("Len() = %d, want 5", b.Len())
+	}
+
+	sender := &fakeBatchSender{
+		results: []batchResult{
+			{commandTag: pgconn.NewCommandTag("INSERT 0 1")},
+			{err: errStatement},
+			{},
+			{},
+			{err: errStatement},
+		},
+	}
+	err := b.send(context.Background(), sender)
+	if !errors.Is(err, errStatement) || !errors.Is(err, errCallback) {
+		t.Fatalf("send() error = %v, want %v and %v", err, errStatement, errCallback)
+	}
+
+	// Check that each failed statement is reported with its index and query
+	var batchErrs []*BatchError
+	for _, joinedErr := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var batchErr *BatchError
+		if !errors.As(joinedErr, &batchErr) {
+			t.Fatalf("error = %v, want a *BatchError", joinedErr)
+		}
+		batchErrs = append(batchErrs, batchErr)
+	}
+	want := []BatchError{
+		{Index: 1, Query: "INSERT INTO users VALUES (2)", Err: errStatement},
+		{Index: 3, Query: "SELECT 2", Err: errCallback},
+		{Index: 4, Query: "DELETE FROM users", Err: errStatement},
+	}
+	if len(batchErrs) != len(want) {
+		t.Fatalf("batch errors = %v, want %d", batchErrs, len(want))
+	}
+	for i, batchErr := range batchErrs {
+		if *batchErr != want[i] {
+			t.Errorf("batch error %d = %+v, want %+v", i, *batchErr, want[i])
+		}
+		if !strings.Contains(batchErr.Error(), fmt.Sprintf("index %d", want[i].Index)) {
+			t.Errorf("Error() = %q, want the statement index %d", batchErr.Error(), want[i].Index)
+		}
+	}
+
+	// Check that the statements were sent in order and the results closed
+	wantStatements := []string{
+		"INSERT INTO users VALUES (1)",
+		"INSERT INTO users VALUES (2)",
+		"SELECT 1",
+		"SELECT 2",
+		"DELETE FROM users",
+	}
+	if !slices.Equal(sender.statements, wantStatements) {
+		t.Errorf("statements = %v, want %v", sender.statements, wantStatements)
+	}
+	if !slices.Equal(commandTags, []string{"INSERT 0 1"}) {
+		t.Errorf("command tags = %v, want the one of the first statement", commandTags)
+	}
+	if !sender.batch.closed {
+		t.Errorf("batch results closed = false, want true")
+	}
+}
+
+func TestQueueOneQueueAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    *valueRows
+		wantErr bool
+		want    []batchUser
+	}{
+		{
+			name: "tagged columns",
+			rows: &valueRows{
+				columns: []string{"id", "name"},
+				values:  [][]any{{int64(1), "alice"}, {int64(2), "bob"}},
+			},
+			want: []batchUser{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}},
+		},
+		{
+			name: "untagged column not mapped",
+			rows: &valueRows{
+				columns: []string{"id", "email"},
+				values:  [][]any{{int64(1), "alice@example.com"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				b := NewBatch()
+				var got []batchUser
+				if err := QueueAll(
+					b, func(values []*batchUser) error {
+						for _, value := range values {
+							got = append(got, *value)
+						}
+						return nil
+					}, "SELECT * FROM users",
+				); err != nil {
+					t.Fatalf("QueueAll() error = %v", err)
+				}
+
+				// Return the first row to the single row query
+				oneRows := &valueRows{columns: tt.rows.columns, values: tt.rows.values[:1]}
+				var gotOne *batchUser
+				if err := QueueOne(
+					b, func(value *batchUser) error {
+						gotOne = value
+						return nil
+					}, "SELECT * FROM users LIMIT 1",
+				); err != nil {
+					t.Fatalf("QueueOne() error = %v", err)
+				}
+
+				err := b.send(
+					context.Background(),
+					&fakeBatchSender{results: []batchResult{{rows: tt.rows}, {rows: oneRows}}},
+				)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("send() error = %v, want error %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					if !strings.Contains(err.Error(), "email") {
+						t.Errorf("send() error = %v, want the unmapped column", err)
+					}
+					return
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("QueueAll() values = %+v, want %+v", got, tt.want)
+				}
+				if gotOne == nil || *gotOne != tt.want[0] {
+					t.Errorf("QueueOne() value = %+v, want %+v", gotOne, tt.want[0])
+				}
+			},
+		)
+	}
+}
+
+func TestNilBatch(t *testing.T) {
+	var b *Batch
+
+	tests := []struct {
+		name    string
+		queueFn func() error
+	}{
+		{name: "exec", queueFn: func() error { return b.QueueExec(nil, "SELECT 1") }},
+		{name: "query row", queueFn: func() error { return b.QueueQueryRow(nil, "SELECT 1") }},
+		{name: "query", queueFn: func() error { return b.QueueQuery(nil, "SELECT 1") }},
+		{name: "one", queueFn: func() error { return QueueOne[batchUser](b, nil, "SELECT 1") }},
+		{name: "all", queueFn: func() error { return QueueAll[batchUser](b, nil, "SELECT 1") }},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if err := tt.queueFn(); !errors.Is(err, ErrNilBatch) {
+					t.Errorf("Queue() error = %v, want %v", err, ErrNilBatch)
+				}
+			},
+		)
+	}
+	if b.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", b.Len())
+	}
+	if err := SendBatchInTransaction(context.Background(), nil, b); !errors.Is(err, ErrNilBatch) {
+		t.Errorf("SendBatchInTransaction() error = %v, want %v", err, ErrNilBatch)
+	}
+}
@@ -0,0 +1,108 @@
+package pgxpool
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+type (
+	// Config struct
+	//
+	// The pool sizing, lifetimes and health check period override the ones parsed from the data source name
+	// when they are positive. PingTimeout is the timeout for each ping done while connecting,
+	// gosql.DefaultPingTimeout is used if it is not positive. ConnectRetryPolicy is the retry policy for the
+	// pings done while connecting, the database is pinged only once if it is nil, and every ping error is
+	// retried if its IsRetryable function is nil.
+	Config struct {
+		DataSourceName        string
+		MaxConnections        int32
+		MinConnections        int32
+		MaxConnectionLifetime time.Duration
+		MaxConnectionIdleTime time.Duration
+		HealthCheckPeriod     time.Duration
+		PingTimeout           time.Duration
+		ConnectRetryPolicy    *gosql.RetryPolicy
+	}
+)
+
+// NewConfig creates a new configuration for the pool
+//
+// Parameters:
+//
+//   - dataSourceName: the data source name, as a URL or as key-value pairs
+//   - maxConnections: the maximum number of connections of the pool
+//   - minConnections: the minimum number of connections kept open by the pool
+//   - maxConnectionIdleTime: the maximum idle time for a connection
+//   - maxConnectionLifetime: the maximum lifetime for a connection
+//   - healthCheckPeriod: the period between the health checks of the idle connections
+//
+// Returns:
+//
+//   - *Config: the pool configuration
+//   - error: if any error occurs
+func NewConfig(
+	dataSourceName string,
+	maxConnections,
+	minConnections int32,
+	maxConnectionIdleTime,
+	maxConnectionLifetime,
+	healthCheckPeriod time.Duration,
+) (*Config, error) {
+	// Check if the data source name is empty
+	if dataSourceName == "" {
+		return nil, godatabases.ErrEmptyDataSourceName
+	}
+
+	return &Config{
+		DataSourceName:        dataSourceName,
+		MaxConnections:        maxConnections,
+		MinConnections:        minConnections,
+		MaxConnectionLifetime: maxConnectionLifetime,
+		MaxConnectionIdleTime: maxConnectionIdleTime,
+		HealthCheckPeriod:     healthCheckPeriod,
+	}, nil
+}
+
+// PoolConfig parses the data source name and applies the pool settings to the resulting pool configuration
+//
+// Returns:
+//
+//   - *pgxpool.Config: the pool configuration
+//   - error: if the data source name could not be parsed
+func (c *Config) PoolConfig() (*pgxpool.Config, error) {
+	if c == nil {
+		return nil, godatabases.ErrNilConfig
+	}
+
+	// Parse the data source name
+	poolConfig, err := pgxpool.ParseConfig(c.DataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set the pool sizing
+	if c.MaxConnections > 0 {
+		poolConfig.MaxConns = c.MaxConnections
+	}
+	if c.MinConnections > 0 {
+		poolConfig.MinConns = c.MinConnections
+	}
+
+	// Set the connection lifetimes
+	if c.MaxConnectionLifetime > 0 {
+		poolConfig.MaxConnLifetime = c.MaxConnectionLifetime
+	}
+	if c.MaxConnectionIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = c.MaxConnectionIdleTime
+	}
+
+	// Set the health check period
+	if c.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = c.HealthCheckPeriod
+	}
+	return poolConfig, nil
+}
@@ -0,0 +1,240 @@
+package pgxpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+type (
+	// DefaultHandler struct
+	DefaultHandler struct {
+		config *Config
+		pool   *pgxpool.Pool
+		mutex  sync.Mutex
+	}
+)
+
+// NewDefaultHandler creates a new pool handler
+//
+// Parameters:
+//
+//   - config: the configuration for the pool
+//
+// Returns:
+//
+//   - *DefaultHandler: the pool handler
+//   - error: if the configuration is nil
+func NewDefaultHandler(
+	config *Config,
+) (*DefaultHandler, error) {
+	// Check if the configuration is nil
+	if config == nil {
+		return nil, godatabases.ErrNilConfig
+	}
+
+	return &DefaultHandler{
+		config: config,
+	}, nil
+}
+
+// Connect returns a new pool
+//
+// The database is pinged before returning the pool, retrying the ping as configured by the connection retry
+// policy, so an unreachable database or a bad data source name is reported here.
+//
+// Returns:
+//
+//   - *pgxpool.Pool: the pool
+//   - error: if any error occurred
+func (d *DefaultHandler) Connect() (*pgxpool.Pool, error) {
+	if d == nil {
+		return nil, godatabases.ErrNilHandler
+	}
+
+	// Lock the mutex to ensure thread safety
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	// Check if the pool is already created
+	if d.IsConnected() {
+		return d.pool, nil
+	}
+
+	// Parse the pool configuration
+	poolConfig, err := d.config.PoolConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrConnectionFailed, err)
+	}
+
+	// Create the pool
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrConnectionFailed, err)
+	}
+
+	// Ping the database
+	if err = d.ping(pool); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("%w: %w", godatabases.ErrPingFailed, err)
+	}
+
+	// Set pool
+	d.pool = pool
+
+	return pool, nil
+}
+
+// ping pings the database, retrying the ping as configured by the connection retry policy
+//
+// Parameters:
+//
+//   - pool: the pool to ping
+//
+// Returns:
+//
+//   - error: if the database could not be pinged
+func (d *DefaultHandler) ping(pool *pgxpool.Pool) error {
+	// Set the ping timeout
+	pingTimeout := d.config.PingTimeout
+	if pingTimeout <= 0 {
+		pingTimeout = gosql.DefaultPingTimeout
+	}
+
+	// Set the retry policy, retrying every ping error if no classifier is set
+	var policy *gosql.RetryPolicy
+	if d.config.ConnectRetryPolicy != nil {
+		connectRetryPolicy := *d.config.ConnectRetryPolicy
+		if connectRetryPolicy.IsRetryable == nil {
+			connectRetryPolicy.IsRetryable = func(err error) bool {
+				return err != nil
+			}
+		}
+		policy = &connectRetryPolicy
+	}
+
+	// Ping the database
+	return policy.Run(
+		context.Background(),
+		func(ctx context.Context) error {
+			pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+			defer cancel()
+			return pool.Ping(pingCtx)
+		},
+	)
+}
+
+// Ping pings the database through the pool
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - time.Duration: the ping latency
+//   - error: if any error occurred
+func (d *DefaultHandler) Ping(ctx context.Context) (time.Duration, error) {
+	if d == nil {
+		return 0, godatabases.ErrNilHandler
+	}
+
+	// Get the pool
+	pool, err := d.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	// Ping the database
+	start := time.Now()
+	if err = pool.Ping(ctx); err != nil {
+		return time.Since(start), fmt.Errorf("%w: %w", godatabases.ErrPingFailed, err)
+	}
+	return time.Since(start), nil
+}
+
+// DB returns the pool
+//
+// Returns:
+//
+//   - *pgxpool.Pool: the pool
+//   - error: if any error occurred
+func (d *DefaultHandler) DB() (*pgxpool.Pool, error) {
+	if d == nil {
+		return nil, godatabases.ErrNilHandler
+	}
+
+	// Lock the mutex to ensure thread safety
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.IsConnected() {
+		return nil, godatabases.ErrNotConnected
+	}
+
+	return d.pool, nil
+}
+
+// Stats returns the statistics of the pool
+//
+// Returns:
+//
+//   - *pgxpool.Stat: the pool statistics
+//   - error: if any error occurred
+func (d *DefaultHandler) Stats() (*pgxpool.Stat, error) {
+	if d == nil {
+		return nil, godatabases.ErrNilHandler
+	}
+
+	// Get the pool
+	pool, err := d.DB()
+	if err != nil {
+		return nil, err
+	}
+	return pool.Stat(), nil
+}
+
+// IsConnected checks if the pool is created
+//
+// Returns:
+//
+//   - bool: true if the pool is created, false otherwise
+func (d *DefaultHandler) IsConnected() bool {
+	if d == nil {
+		return false
+	}
+	return d.pool != nil
+}
+
+// Disconnect closes the pool, waiting for the acquired connections to be released
+//
+// Returns:
+//
+//   - error: if any error occurred
+func (d *DefaultHandler) Disconnect() error {
+	if d == nil {
+		return godatabases.ErrNilHandler
+	}
+
+	// Lock the mutex to ensure thread safety
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	// Check if the pool is created
+	if !d.IsConnected() {
+		return nil
+	}
+
+	// Close the pool
+	d.pool.Close()
+
+	// Set the pool to nil
+	d.pool = nil
+	return nil
+}
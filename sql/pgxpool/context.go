@@ -0,0 +1,47 @@
+package pgxpool
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	// txContextKey is the context key for the active transaction
+	txContextKey struct{}
+)
+
+// WithTx returns a copy of the context that carries the given transaction
+//
+// Parameters:
+//
+//   - ctx: the parent context
+//   - tx: the transaction
+//
+// Returns:
+//
+//   - context.Context: the context carrying the transaction
+func WithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// GetTxFromContext gets the active transaction from the context
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - pgx.Tx: the active transaction
+//   - bool: true if the context carries an active transaction, false otherwise
+func GetTxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txContextKey{}).(pgx.Tx)
+	if !ok || tx == nil {
+		return nil, false
+	}
+	return tx, true
+}
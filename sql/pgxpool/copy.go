@@ -0,0 +1,544 @@
+package pgxpool
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/internal/structs"
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+const (
+	// DefaultCopyChunkSize is the default number of rows copied by each COPY statement
+	DefaultCopyChunkSize = 10000
+
+	// StagingTablePrefix is the prefix of the temporary tables used to stage the rows before upserting them
+	StagingTablePrefix = "copy_staging_"
+)
+
+type (
+	// CopyProgressFn is the function type called after each chunk with the total number of rows copied so far
+	CopyProgressFn func(copied int64)
+
+	// CopyOptions are the options of CopyFrom
+	//
+	// ChunkSize is the number of rows copied by each COPY statement, DefaultCopyChunkSize is used if it is not
+	// positive. If ConflictColumns is set, each chunk is copied into a temporary table and then inserted into the
+	// target table with an ON CONFLICT clause on those columns, updating the remaining columns, or doing nothing
+	// if DoNothingOnConflict is true or there are no remaining columns.
+	CopyOptions struct {
+		ChunkSize           int
+		OnProgress          CopyProgressFn
+		ConflictColumns     []string
+		DoNothingOnConflict bool
+	}
+
+	// copier is the interface implemented by both *pgxpool.Pool and pgx.Tx to copy rows
+	copier interface {
+		CopyFrom(
+			ctx context.Context,
+			tableName pgx.Identifier,
+			columnNames []string,
+			rowSrc pgx.CopyFromSource,
+		) (int64, error)
+	}
+
+	// chunkSource is a pgx.CopyFromSource that yields up to a fixed number of rows from a pulled iterator
+	chunkSource struct {
+		next    func() ([]any, error, bool)
+		pending []any
+		size    int
+		count   int
+		values  []any
+		err     error
+	}
+)
+
+// Next advances to the next row of the chunk
+//
+// Returns:
+//
+//   - bool: true if there is a row, false if the chunk or the iterator finished
+func (c *chunkSource) Next() bool {
+	// Check if the chunk is full or failed
+	if c.count >= c.size || c.err != nil {
+		return false
+	}
+
+	// Get the pending row, or pull the next one
+	if c.pending != nil {
+		c.values, c.pending = c.pending, nil
+	} else {
+		values, err, ok := c.next()
+		if !ok {
+			return false
+		}
+		if err != nil {
+			c.err = err
+			return false
+		}
+		c.values = values
+	}
+	c.count++
+	return true
+}
+
+// Values returns the values of the current row
+//
+// Returns:
+//
+//   - []any: the values of the current row
+//   - error: always nil
+func (c *chunkSource) Values() ([]any, error) {
+	return c.values, nil
+}
+
+// Err returns the error returned by the iterator
+//
+// Returns:
+//
+//   - error: the error returned by the iterator
+func (c *chunkSource) Err() error {
+	return c.err
+}
+
+// CopyFrom copies the rows yielded by the iterator into the table with the COPY protocol, in chunks
+//
+// If the context carries an active transaction, the chunks are copied within it. Otherwise, each chunk is
+// committed on its own, so the rows of the chunks copied before a failure are kept, which is why the
+// conflict columns should be set for imports that may be retried.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - table: the target table
+//   - columns: the target columns, in the order of the values of each row
+//   - rows: the iterator over the values of each row, it is stopped at the first error
+//   - opts: the copy options, it can be nil
+//
+// Returns:
+//
+//   - int64: the number of rows copied
+//   - error: if any error occurs
+func CopyFrom(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	table pgx.Identifier,
+	columns []string,
+	rows iter.Seq2[[]any, error],
+	opts *CopyOptions,
+) (copied int64, err error) {
+	// Check the arguments
+	if pool == nil {
+		return 0, godatabases.ErrNilPool
+	}
+	if len(table) == 0 {
+		return 0, ErrEmptyTableName
+	}
+	if len(columns) == 0 {
+		return 0, ErrEmptyCopyColumns
+	}
+	if rows == nil {
+		return 0, ErrNilCopyRows
+	}
+	if opts == nil {
+		opts = &CopyOptions{}
+	}
+
+	// Set the chunk size
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultCopyChunkSize
+	}
+
+	// Start the copy span
+	ctx, span := telemetry.StartSpan(
+		ctx,
+		telemetry.SystemPostgreSQL,
+		telemetry.OperationCopy,
+		"",
+		telemetry.AttributeDBTable.String(table.Sanitize()),
+	)
+	defer func() {
+		telemetry.EndSpan(span, err)
+	}()
+
+	// Copy the rows in chunks
+	return copyChunks(
+		rows,
+		chunkSize,
+		func(source *chunkSource) (int64, error) {
+			if len(opts.ConflictColumns) > 0 {
+				return copyStagedChunk(ctx, pool, table, columns, source, opts)
+			}
+			return copyChunk(ctx, pool, table, columns, source)
+		},
+		opts.OnProgress,
+	)
+}
+
+// copyChunks splits the rows yielded by the iterator into chunks, copying each one with the given function
+//
+// The iterator is stopped once the rows are copied, or at the first error.
+//
+// Parameters:
+//
+//   - rows: the iterator over the values of each row
+//   - chunkSize: the maximum number of rows of each chunk
+//   - copyFn: the function that copies a chunk, returning the number of rows copied
+//   - onProgress: the function called after each chunk with the total number of rows copied so far, it can be nil
+//
+// Returns:
+//
+//   - int64: the number of rows copied
+//   - error: if any error occurs
+func copyChunks(
+	rows iter.Seq2[[]any, error],
+	chunkSize int,
+	copyFn func(source *chunkSource) (int64, error),
+	onProgress CopyProgressFn,
+) (int64, error) {
+	// Pull the rows from the iterator
+	next, stop := iter.Pull2(rows)
+	defer stop()
+
+	var copied int64
+	for {
+		// Pull the first row of the chunk to check if there are rows left
+		first, rowErr, ok := next()
+		if !ok {
+			return copied, nil
+		}
+		if rowErr != nil {
+			return copied, rowErr
+		}
+		source := &chunkSource{
+			next:    next,
+			pending: first,
+			size:    chunkSize,
+		}
+
+		// Copy the chunk
+		chunkCopied, err := copyFn(source)
+		if err != nil {
+			return copied, err
+		}
+		copied += chunkCopied
+
+		// Report the progress
+		if onProgress != nil {
+			onProgress(copied)
+		}
+	}
+}
+
+// copyChunk copies a chunk into the table, within the active transaction in the context if any
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - table: the target table
+//   - columns: the target columns
+//   - source: the chunk to copy
+//
+// Returns:
+//
+//   - int64: the number of rows copied
+//   - error: if any error occurs
+func copyChunk(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	table pgx.Identifier,
+	columns []string,
+	source *chunkSource,
+) (int64, error) {
+	var executor copier = pool
+	if tx, ok := GetTxFromContext(ctx); ok {
+		executor = tx
+	}
+	return executor.CopyFrom(ctx, table, columns, source)
+}
+
+// copyStagedChunk copies a chunk into a temporary table and upserts it into the table within a transaction, or a
+// savepoint if the context carries an active transaction
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - table: the target table
+//   - columns: the target columns
+//   - source: the chunk to copy
+//   - opts: the copy options
+//
+// Returns:
+//
+//   - int64: the number of rows copied into the temporary table
+//   - error: if any error occurs
+func copyStagedChunk(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	table pgx.Identifier,
+	columns []string,
+	source *chunkSource,
+	opts *CopyOptions,
+) (copied int64, err error) {
+	stagingTable := pgx.Identifier{StagingTablePrefix + table[len(table)-1]}
+	err = CreateTransaction(
+		ctx, pool, func(ctx context.Context, tx pgx.Tx) error {
+			// Create or empty the temporary table, it may exist if an outer transaction copied a previous chunk
+			if _, txErr := tx.Exec(
+				ctx,
+				fmt.Sprintf(
+					"CREATE TEMPORARY TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP",
+					stagingTable.Sanitize(),
+					table.Sanitize(),
+				),
+			); txErr != nil {
+				return txErr
+			}
+			if _, txErr := tx.Exec(
+				ctx,
+				"TRUNCATE "+stagingTable.Sanitize(),
+			); txErr != nil {
+				return txErr
+			}
+
+			// Copy the chunk into the temporary table
+			var txErr error
+			copied, txErr = tx.CopyFrom(ctx, stagingTable, columns, source)
+			if txErr != nil {
+				return txErr
+			}
+
+			// Upsert the rows into the table
+			_, txErr = tx.Exec(
+				ctx,
+				upsertQuery(table, stagingTable, columns, opts),
+			)
+			return txErr
+		},
+	)
+	return copied, err
+}
+
+// upsertQuery builds the query that inserts the rows of the temporary table into the table
+//
+// Parameters:
+//
+//   - table: the target table
+//   - stagingTable: the temporary table
+//   - columns: the target columns
+//   - opts: the copy options
+//
+// Returns:
+//
+//   - string: the upsert query
+func upsertQuery(
+	table, stagingTable pgx.Identifier,
+	columns []string,
+	opts *CopyOptions,
+) string {
+	// Sanitize the columns
+	sanitizedColumns := sanitizeColumns(columns)
+	conflictColumns := sanitizeColumns(opts.ConflictColumns)
+
+	// Build the update of the columns that are not part of the conflict target
+	isConflictColumn := make(map[string]bool, len(opts.ConflictColumns))
+	for _, column := range opts.ConflictColumns {
+		isConflictColumn[column] = true
+	}
+	var updates []string
+	for i, column := range columns {
+		if !isConflictColumn[column] {
+			updates = append(
+				updates,
+				fmt.Sprintf(
+					"%s = EXCLUDED.%s",
+					sanitizedColumns[i],
+					sanitizedColumns[i],
+				),
+			)
+		}
+	}
+	action := "DO NOTHING"
+	if !opts.DoNothingOnConflict && len(updates) > 0 {
+		action = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+
+	columnList := strings.Join(sanitizedColumns, ", ")
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (%s) %s",
+		table.Sanitize(),
+		columnList,
+		columnList,
+		stagingTable.Sanitize(),
+		strings.Join(conflictColumns, ", "),
+		action,
+	)
+}
+
+// sanitizeColumns quotes the column names
+//
+// Parameters:
+//
+//   - columns: the column names
+//
+// Returns:
+//
+//   - []string: the quoted column names
+func sanitizeColumns(columns []string) []string {
+	sanitized := make([]string, len(columns))
+	for i, column := range columns {
+		sanitized[i] = pgx.Identifier{column}.Sanitize()
+	}
+	return sanitized
+}
+
+// StructRows returns an iterator over the values of the structs for the given columns, mapping the columns to the
+// fields through their 'db' tag
+//
+// Parameters:
+//
+//   - values: the structs, or pointers to them
+//   - columns: the columns, all the mapped columns in field order are used if empty
+//
+// Returns:
+//
+//   - []string: the columns
+//   - iter.Seq2[[]any, error]: the iterator over the values of each struct
+//   - error: if the type is not a struct or a column is not mapped to any field
+func StructRows[T any](values iter.Seq[T], columns []string) (
+	[]string,
+	iter.Seq2[[]any, error],
+	error,
+) {
+	// Get the struct mapping
+	structType := reflect.TypeFor[T]()
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	mapping, err := structs.GetMapping(structType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check the columns
+	if len(columns) == 0 {
+		columns = mapping.Columns()
+	}
+	for _, column := range columns {
+		if _, ok := mapping.ValueByColumn(reflect.New(structType).Elem(), column); !ok {
+			return nil, nil, fmt.Errorf(ErrUnmappedCopyColumn, column, structType)
+		}
+	}
+
+	return columns, func(yield func([]any, error) bool) {
+		for value := range values {
+			// Get the struct value
+			structValue := reflect.ValueOf(value)
+			if structValue.Kind() == reflect.Pointer {
+				if structValue.IsNil() {
+					yield(nil, ErrNilCopyValue)
+					return
+				}
+				structValue = structValue.Elem()
+			}
+
+			// Get the values of the columns
+			row := make([]any, len(columns))
+			for i, column := range columns {
+				row[i], _ = mapping.ValueByColumn(structValue, column)
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// CopyFromStructs copies the structs into the table with the COPY protocol, in chunks, mapping the columns to
+// the fields through their 'db' tag
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - table: the target table
+//   - columns: the target columns, all the mapped columns in field order are used if empty
+//   - values: the structs, or pointers to them
+//   - opts: the copy options, it can be nil
+//
+// Returns:
+//
+//   - int64: the number of rows copied
+//   - error: if any error occurs
+func CopyFromStructs[T any](
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	table pgx.Identifier,
+	columns []string,
+	values []T,
+	opts *CopyOptions,
+) (int64, error) {
+	return CopyFromStructsSeq(
+		ctx,
+		pool,
+		table,
+		columns,
+		func(yield func(T) bool) {
+			for _, value := range values {
+				if !yield(value) {
+					return
+				}
+			}
+		},
+		opts,
+	)
+}
+
+// CopyFromStructsSeq copies the structs yielded by the iterator into the table with the COPY protocol, in
+// chunks, mapping the columns to the fields through their 'db' tag
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - pool: the pgxpool.Pool instance
+//   - table: the target table
+//   - columns: the target columns, all the mapped columns in field order are used if empty
+//   - values: the iterator over the structs, or pointers to them
+//   - opts: the copy options, it can be nil
+//
+// Returns:
+//
+//   - int64: the number of rows copied
+//   - error: if any error occurs
+func CopyFromStructsSeq[T any](
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	table pgx.Identifier,
+	columns []string,
+	values iter.Seq[T],
+	opts *CopyOptions,
+) (int64, error) {
+	// Check if the iterator is nil
+	if values == nil {
+		return 0, ErrNilCopyRows
+	}
+
+	// Map the structs to rows
+	columns, rows, err := StructRows(values, columns)
+	if err != nil {
+		return 0, err
+	}
+	return CopyFrom(ctx, pool, table, columns, rows, opts)
+}
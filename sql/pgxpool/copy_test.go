@@ -0,0 +1,329 @@
+package pgxpool
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	// copyUser is the struct copied in the tests
+	copyUser struct {
+		ID      int64  `db:"id"`
+		Name    string `db:"name"`
+		Ignored string `db:"-"`
+		copyAudit
+	}
+
+	// copyAudit is the embedded struct of copyUser
+	copyAudit struct {
+		CreatedBy string `db:"created_by"`
+	}
+)
+
+// countRows returns an iterator over the given number of rows, failing at the given row if positive, and
+// recording if the iteration was stopped
+func countRows(count, failAt int, errRow error, stopped *bool) iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		defer func() {
+			*stopped = true
+		}()
+		for i := 1; i <= count; i++ {
+			if i == failAt {
+				yield(nil, errRow)
+				return
+			}
+			if !yield([]any{i}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// drainChunk reads the rows of the chunk as pgx does, returning their values
+func drainChunk(source *chunkSource) ([]int, error) {
+	var values []int
+	for source.Next() {
+		row, err := source.Values()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, row[0].(int))
+	}
+	return values, source.Err()
+}
+
+func TestUpsertQuery(t *testing.T) {
+	staging := pgx.Identifier{"copy_staging_1"}
+
+	tests := []struct {
+		name    string
+		table   pgx.Identifier
+		columns []string
+		opts    *CopyOptions
+		want    string
+	}{
+		{
+			name:    "updates the remaining columns",
+			table:   pgx.Identifier{"users"},
+			columns: []string{"id", "name", "email"},
+			opts:    &CopyOptions{ConflictColumns: []string{"id"}},
+			want: `INSERT INTO "users" ("id", "name", "email") SELECT "id", "name", "email" ` +
+				`FROM "copy_staging_1" ON CONFLICT ("id") ` +
+				`DO UPDATE SET "name" = EXCLUDED."name", "email" = EXCLUDED."email"`,
+		},
+		{
+			name:    "schema-qualified table",
+			table:   pgx.Identifier{"app", "users"},
+			columns: []string{"id", "name"},
+			opts:    &CopyOptions{ConflictColumns: []string{"id"}},
+			want: `INSERT INTO "app"."users" ("id", "name") SELECT "id", "name" ` +
+				`FROM "copy_staging_1" ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`,
+		},
+		{
+			name:    "quoted identifiers",
+			table:   pgx.Identifier{"Users"},
+			columns: []string{`tenant"id`, "Name"},
+			opts:    &CopyOptions{ConflictColumns: []string{`tenant"id`}},
+			want: `INSERT INTO "Users" ("tenant""id", "Name") SELECT "tenant""id", "Name" ` +
+				`FROM "copy_staging_1" ON CONFLICT ("tenant""id") DO UPDATE SET "Name" = EXCLUDED."Name"`,
+		},
+		{
+			name:    "composite conflict target",
+			table:   pgx.Identifier{"memberships"},
+			columns: []string{"user_id", "group_id", "role"},
+			opts:    &CopyOptions{ConflictColumns: []string{"user_id", "group_id"}},
+			want: `INSERT INTO "memberships" ("user_id", "group_id", "role") SELECT "user_id", "group_id", "role" ` +
+				`FROM "copy_staging_1" ON CONFLICT ("user_id", "group_id") DO UPDATE SET "role" = EXCLUDED."role"`,
+		},
+		{
+			name:    "empty update set",
+			table:   pgx.Identifier{"tags"},
+			columns: []string{"name"},
+			opts:    &CopyOptions{ConflictColumns: []string{"name"}},
+			want:    `INSERT INTO "tags" ("name") SELECT "name" FROM "copy_staging_1" ON CONFLICT ("name") DO NOTHING`,
+		},
+		{
+			name:    "do nothing on conflict",
+			table:   pgx.Identifier{"users"},
+			columns: []string{"id", "name"},
+			opts:    &CopyOptions{ConflictColumns: []string{"id"}, DoNothingOnConflict: true},
+			want: `INSERT INTO "users" ("id", "name") SELECT "id", "name" ` +
+				`FROM "copy_staging_1" ON CONFLICT ("id") DO NOTHING`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := upsertQuery(tt.table, staging, tt.columns, tt.opts); got != tt.want {
+					t.Errorf("upsertQuery() =\n%s\nwant\n%s", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestStructRows(t *testing.T) {
+	users := []*copyUser{
+		{ID: 1, Name: "alice", Ignored: "x", copyAudit: copyAudit{CreatedBy: "admin"}},
+		{ID: 2, Name: "bob"},
+	}
+
+	tests := []struct {
+		name        string
+		columns     []string
+		values      []*copyUser
+		wantColumns []string
+		wantRows    [][]any
+		wantErr     bool
+		wantRowErr  error
+	}{
+		{
+			name:        "mapped columns in field order",
+			values:      users,
+			wantColumns: []string{"id", "name", "created_by"},
+			wantRows:    [][]any{{int64(1), "alice", "admin"}, {int64(2), "bob", ""}},
+		},
+		{
+			name:        "selected columns",
+			columns:     []string{"name", "id"},
+			values:      users,
+			wantColumns: []string{"name", "id"},
+			wantRows:    [][]any{{"alice", int64(1)}, {"bob", int64(2)}},
+		},
+		{
+			name:    "ignored column",
+			columns: []string{"id", "Ignored"},
+			values:  users,
+			wantErr: true,
+		},
+		{
+			name:        "nil value",
+			values:      []*copyUser{users[0], nil, users[1]},
+			wantColumns: []string{"id", "name", "created_by"},
+			wantRows:    [][]any{{int64(1), "alice", "admin"}},
+			wantRowErr:  ErrNilCopyValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				columns, rows, err := StructRows(slices.Values(tt.values), tt.columns)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("StructRows() error = %v, want error %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				if !slices.Equal(columns, tt.wantColumns) {
+					t.Errorf("StructRows() columns = %v, want %v", columns, tt.wantColumns)
+				}
+
+				// Collect the rows until the first error
+				var gotRows [][]any
+				var rowErr error
+				for row, err := range rows {
+					if err != nil {
+						rowErr = err
+						break
+					}
+					gotRows = append(gotRows, row)
+				}
+				if !errors.Is(rowErr, tt.wantRowErr) {
+					t.Errorf("row error = %v, want %v", rowErr, tt.wantRowErr)
+				}
+				if !slices.EqualFunc(gotRows, tt.wantRows, slices.Equal) {
+					t.Errorf("rows = %v, want %v", gotRows, tt.wantRows)
+				}
+			},
+		)
+	}
+
+	// Check that the values can be structs, and that the types that are not structs are rejected
+	if _, _, err := StructRows(slices.Values([]copyUser{*users[0]}), nil); err != nil {
+		t.Errorf("StructRows() with struct values error = %v", err)
+	}
+	if _, _, err := StructRows(slices.Values([]int{1}), nil); err == nil {
+		t.Errorf("StructRows() with int values error = nil, want error")
+	}
+}
+
+func TestCopyChunks(t *testing.T) {
+	errRow := errors.New("invalid row")
+	errCopy := errors.New("copy failed")
+
+	tests := []struct {
+		name         string
+		count        int
+		failAt       int
+		chunkSize    int
+		failChunk    int
+		wantChunks   [][]int
+		wantProgress []int64
+		wantCopied   int64
+		wantErr      error
+	}{
+		{name: "no rows", count: 0, chunkSize: 2},
+		{
+			name:         "partial last chunk",
+			count:        5,
+			chunkSize:    2,
+			wantChunks:   [][]int{{1, 2}, {3, 4}, {5}},
+			wantProgress: []int64{2, 4, 5},
+			wantCopied:   5,
+		},
+		{
+			name:         "full last chunk",
+			count:        4,
+			chunkSize:    2,
+			wantChunks:   [][]int{{1, 2}, {3, 4}},
+			wantProgress: []int64{2, 4},
+			wantCopied:   4,
+		},
+		{
+			name:         "single chunk",
+			count:        3,
+			chunkSize:    10,
+			wantChunks:   [][]int{{1, 2, 3}},
+			wantProgress: []int64{3},
+			wantCopied:   3,
+		},
+		{
+			name:         "row error within a chunk",
+			count:        5,
+			failAt:       4,
+			chunkSize:    2,
+			wantChunks:   [][]int{{1, 2}, {3}},
+			wantProgress: []int64{2},
+			wantCopied:   2,
+			wantErr:      errRow,
+		},
+		{
+			name:         "row error at the first row of a chunk",
+			count:        5,
+			failAt:       3,
+			chunkSize:    2,
+			wantChunks:   [][]int{{1, 2}},
+			wantProgress: []int64{2},
+			wantCopied:   2,
+			wantErr:      errRow,
+		},
+		{
+			name:         "copy error stops the iterator",
+			count:        10,
+			chunkSize:    2,
+			failChunk:    2,
+			wantChunks:   [][]int{{1, 2}, {3, 4}},
+			wantProgress: []int64{2},
+			wantCopied:   2,
+			wantErr:      errCopy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var stopped bool
+				var chunks [][]int
+				var progress []int64
+				copied, err := copyChunks(
+					countRows(tt.count, tt.failAt, errRow, &stopped),
+					tt.chunkSize,
+					func(source *chunkSource) (int64, error) {
+						values, err := drainChunk(source)
+						chunks = append(chunks, values)
+						if err != nil {
+							return 0, err
+						}
+						if len(chunks) == tt.failChunk {
+							return 0, errCopy
+						}
+						return int64(len(values)), nil
+					},
+					func(copied int64) {
+						progress = append(progress, copied)
+					},
+				)
+				if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+					t.Errorf("copyChunks() error = %v, want %v", err, tt.wantErr)
+				}
+				if copied != tt.wantCopied {
+					t.Errorf("copyChunks() copied = %d, want %d", copied, tt.wantCopied)
+				}
+				if !slices.EqualFunc(chunks, tt.wantChunks, slices.Equal) {
+					t.Errorf("chunks = %v, want %v", chunks, tt.wantChunks)
+				}
+				if !slices.Equal(progress, tt.wantProgress) {
+					t.Errorf("progress = %v, want %v", progress, tt.wantProgress)
+				}
+
+				// Check that the iterator was stopped, even if it had rows left
+				if !stopped {
+					t.Errorf("iterator stopped = false, want true")
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,27 @@
+package pgxpool
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilTransactionFn           = errors.New("transaction function cannot be nil")
+	ErrNilOpenRowsFn              = errors.New("open rows function cannot be nil")
+	ErrNilBatch                   = errors.New("batch cannot be nil")
+	ErrEmptyTableName             = errors.New("table name cannot be empty")
+	ErrEmptyCopyColumns           = errors.New("copy columns cannot be empty")
+	ErrNilCopyRows                = errors.New("copy rows cannot be nil")
+	ErrNilCopyValue               = errors.New("copy value cannot be nil")
+	ErrNilTransaction             = errors.New("transaction cannot be nil")
+	ErrNilListener                = errors.New("listener cannot be nil")
+	ErrNilNotification            = errors.New("notification cannot be nil")
+	ErrListenerAlreadyStarted     = errors.New("listener is already started")
+	ErrEmptyNotificationChannel   = errors.New("notification channel cannot be empty")
+	ErrInvalidNotificationPayload = errors.New("invalid notification payload")
+)
+
+const (
+	ErrBatchStatementFailed = "batch statement at index %d failed: %w"
+	ErrUnmappedCopyColumn   = "column '%s' is not mapped to any field of '%v'"
+	ErrUnmappedColumn       = "column '%s' is not mapped to any field of '%v'"
+)
@@ -0,0 +1,89 @@
+package pgxpool
+
+import (
+	"context"
+	"iter"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+type (
+	// Executor is the interface implemented by both *pgxpool.Pool and pgx.Tx
+	Executor interface {
+		Exec(
+			ctx context.Context,
+			sql string,
+			arguments ...any,
+		) (pgconn.CommandTag, error)
+		Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	}
+
+	// Handler interface
+	Handler interface {
+		Connect() (*pgxpool.Pool, error)
+		IsConnected() bool
+		DB() (*pgxpool.Pool, error)
+		Disconnect() error
+	}
+
+	// StatsHandler is the interface for the handlers that report the statistics of their pool
+	StatsHandler interface {
+		Stats() (*pgxpool.Stat, error)
+	}
+
+	// Service is the interface for the service
+	Service interface {
+		Handler
+		CreateTransaction(ctx context.Context, fn TransactionFn) error
+		CreateTransactionWithOptions(
+			ctx context.Context,
+			fn TransactionFn,
+			opts pgx.TxOptions,
+		) error
+		CreateTransactionWithRetry(
+			ctx context.Context,
+			fn TransactionFn,
+			opts pgx.TxOptions,
+			policy *gosql.RetryPolicy,
+		) error
+		ReadOnlyTransaction(ctx context.Context, fn TransactionFn) error
+		SerializableTransaction(
+			ctx context.Context,
+			fn TransactionFn,
+			policy *gosql.RetryPolicy,
+		) error
+		Exec(query *string, params ...any) (pgconn.CommandTag, error)
+		ExecWithCtx(
+			ctx context.Context,
+			query *string,
+			params ...any,
+		) (pgconn.CommandTag, error)
+		QueryRow(query *string, params ...any) (pgx.Row, error)
+		QueryRowWithCtx(
+			ctx context.Context,
+			query *string,
+			params ...any,
+		) (pgx.Row, error)
+		Query(query *string, params ...any) (iter.Seq2[pgx.Rows, error], error)
+		QueryWithCtx(
+			ctx context.Context,
+			query *string,
+			params ...any,
+		) (iter.Seq2[pgx.Rows, error], error)
+		ScanRow(row pgx.Row, destinations ...any) error
+		SendBatch(ctx context.Context, b *Batch) error
+		SendBatchInTransaction(ctx context.Context, b *Batch) error
+		CopyFrom(
+			ctx context.Context,
+			table pgx.Identifier,
+			columns []string,
+			rows iter.Seq2[[]any, error],
+			opts *CopyOptions,
+		) (int64, error)
+	}
+)
@@ -0,0 +1,410 @@
+package pgxpool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+const (
+	// DefaultNotificationBufferSize is the default buffer size of the subscription channels
+	DefaultNotificationBufferSize = 16
+)
+
+type (
+	// ListenerErrorFn is the function type called when the listener connection fails, before reconnecting
+	ListenerErrorFn func(err error)
+
+	// Listener listens on PostgreSQL notification channels through a dedicated connection, dispatching the
+	// notifications to the subscribed Go channels
+	//
+	// The connection is taken out of the pool, so its LISTEN state never leaks to other users of the pool. If
+	// the connection is lost, the listener reconnects waiting as configured by the reconnect policy, and LISTENs
+	// again on every subscribed channel. Notifications sent while disconnected are lost.
+	Listener struct {
+		pool            *pgxpool.Pool
+		reconnectPolicy *gosql.RetryPolicy
+		onError         ListenerErrorFn
+		subscriptions   map[string][]chan *pgconn.Notification
+		listening       map[string]bool
+		waitCancel      context.CancelFunc
+		subscriptionsMu sync.Mutex
+		cancel          context.CancelFunc
+		done            chan struct{}
+		mutex           sync.Mutex
+	}
+)
+
+// NewListener creates a new listener
+//
+// Parameters:
+//
+//   - pool: the pool to take the dedicated connection from
+//   - reconnectPolicy: the policy whose backoff is waited before each reconnection, the default retry policy
+//     is used if nil. The listener never gives up reconnecting, so its maximum attempts are ignored
+//   - onError: the function called when the connection fails, it can be nil
+//
+// Returns:
+//
+//   - *Listener: the listener
+//   - error: if the pool is nil
+func NewListener(
+	pool *pgxpool.Pool,
+	reconnectPolicy *gosql.RetryPolicy,
+	onError ListenerErrorFn,
+) (*Listener, error) {
+	// Check if the pool is nil
+	if pool == nil {
+		return nil, godatabases.ErrNilPool
+	}
+
+	// Set the default reconnect policy
+	if reconnectPolicy == nil {
+		reconnectPolicy = gosql.NewDefaultRetryPolicy()
+	}
+
+	return &Listener{
+		pool:            pool,
+		reconnectPolicy: reconnectPolicy,
+		onError:         onError,
+		subscriptions:   make(map[string][]chan *pgconn.Notification),
+	}, nil
+}
+
+// Subscribe subscribes to a notification channel, which is LISTENed on as soon as the listener is connected
+//
+// Each notification is sent to every subscription of its channel, blocking until it is received, so slow
+// subscribers delay the others. The returned channel is closed when the listener is stopped.
+//
+// Parameters:
+//
+//   - channel: the notification channel
+//   - bufferSize: the buffer size of the returned channel, DefaultNotificationBufferSize is used if it is not
+//     positive
+//
+// Returns:
+//
+//   - <-chan *pgconn.Notification: the channel the notifications are sent to
+//   - error: if the listener is nil or the channel is empty
+func (l *Listener) Subscribe(
+	channel string,
+	bufferSize int,
+) (<-chan *pgconn.Notification, error) {
+	if l == nil {
+		return nil, ErrNilListener
+	}
+
+	// Check if the channel is empty
+	if channel == "" {
+		return nil, ErrEmptyNotificationChannel
+	}
+
+	// Set the default buffer size
+	if bufferSize <= 0 {
+		bufferSize = DefaultNotificationBufferSize
+	}
+
+	// Add the subscription
+	notifications := make(chan *pgconn.Notification, bufferSize)
+	l.subscriptionsMu.Lock()
+	l.subscriptions[channel] = append(l.subscriptions[channel], notifications)
+
+	// Wake up the listener to LISTEN on the new channel
+	if l.waitCancel != nil && !l.listening[channel] {
+		l.waitCancel()
+	}
+	l.subscriptionsMu.Unlock()
+	return notifications, nil
+}
+
+// Start starts listening in the background
+//
+// Parameters:
+//
+//   - ctx: the context of the listener, which stops it when done
+//
+// Returns:
+//
+//   - error: if the listener is nil or already started
+func (l *Listener) Start(ctx context.Context) error {
+	if l == nil {
+		return ErrNilListener
+	}
+
+	// Lock the mutex to ensure thread safety
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Check if the listener is already started
+	if l.cancel != nil {
+		return ErrListenerAlreadyStarted
+	}
+
+	// Create the listener context
+	listenCtx, cancel := context.WithCancel(ctx)
+	l.cancel = cancel
+	l.done = make(chan struct{})
+
+	// Run the listener
+	go func() {
+		defer close(l.done)
+		l.run(listenCtx)
+	}()
+	return nil
+}
+
+// Stop stops the listener, closing the dedicated connection and the subscription channels
+//
+// The subscriptions are removed, so the listener must be subscribed to again before being restarted.
+func (l *Listener) Stop() {
+	if l == nil {
+		return
+	}
+
+	// Lock the mutex to ensure thread safety
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Check if the listener is started
+	if l.cancel == nil {
+		return
+	}
+
+	// Stop the listener
+	l.cancel()
+	<-l.done
+	l.cancel = nil
+	l.done = nil
+
+	// Close the subscription channels
+	l.subscriptionsMu.Lock()
+	defer l.subscriptionsMu.Unlock()
+	for _, subscriptions := range l.subscriptions {
+		for _, notifications := range subscriptions {
+			close(notifications)
+		}
+	}
+	l.subscriptions = make(map[string][]chan *pgconn.Notification)
+}
+
+// run listens until the context is done, reconnecting after each connection failure
+//
+// Parameters:
+//
+//   - ctx: the listener context
+func (l *Listener) run(ctx context.Context) {
+	retry := 0
+	for {
+		// Listen until the connection fails
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			retry = 0
+		}
+		retry++
+
+		// Report the error
+		if l.onError != nil && err != nil {
+			l.onError(err)
+		}
+
+		// Wait before reconnecting
+		timer := time.NewTimer(l.reconnectPolicy.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// listen takes a dedicated connection, LISTENs on the subscribed channels and dispatches the notifications
+// until the connection fails or the context is done
+//
+// Parameters:
+//
+//   - ctx: the listener context
+//
+// Returns:
+//
+//   - bool: true if the connection was established, false otherwise
+//   - error: the connection error
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	// Take a connection out of the pool
+	poolConn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	conn := poolConn.Hijack()
+	defer func() {
+		//nolint:contextcheck // The connection must be closed even if the context is done
+		_ = conn.Close(context.Background())
+	}()
+
+	// Reset the channels LISTENed on, since they belonged to the previous connection
+	l.subscriptionsMu.Lock()
+	l.listening = make(map[string]bool)
+	l.subscriptionsMu.Unlock()
+
+	for {
+		// Get the channels not LISTENed on yet, and set the function to wake up the wait
+		l.subscriptionsMu.Lock()
+		var pending []string
+		for channel := range l.subscriptions {
+			if !l.listening[channel] {
+				pending = append(pending, channel)
+			}
+		}
+		waitCtx, waitCancel := context.WithCancel(ctx)
+		l.waitCancel = waitCancel
+		l.subscriptionsMu.Unlock()
+
+		// LISTEN on the pending channels
+		for _, channel := range pending {
+			if _, err = conn.Exec(
+				ctx,
+				"LISTEN "+pgx.Identifier{channel}.Sanitize(),
+			); err != nil {
+				waitCancel()
+				l.clearWaitCancel()
+				return true, err
+			}
+			l.subscriptionsMu.Lock()
+			l.listening[channel] = true
+			l.subscriptionsMu.Unlock()
+		}
+
+		// Wait for a notification
+		notification, waitErr := conn.WaitForNotification(waitCtx)
+		waitCancel()
+		if waitErr != nil {
+			// Check if the wait was woken up to LISTEN on a new channel
+			if ctx.Err() == nil && waitCtx.Err() != nil && !conn.IsClosed() {
+				continue
+			}
+			l.clearWaitCancel()
+			return true, waitErr
+		}
+
+		// Dispatch the notification
+		l.dispatch(ctx, notification)
+	}
+}
+
+// clearWaitCancel removes the function to wake up the wait
+func (l *Listener) clearWaitCancel() {
+	l.subscriptionsMu.Lock()
+	l.waitCancel = nil
+	l.subscriptionsMu.Unlock()
+}
+
+// dispatch sends the notification to the subscriptions of its channel
+//
+// Parameters:
+//
+//   - ctx: the listener context
+//   - notification: the notification to dispatch
+func (l *Listener) dispatch(
+	ctx context.Context,
+	notification *pgconn.Notification,
+) {
+	// Get the subscriptions of the channel
+	l.subscriptionsMu.Lock()
+	subscriptions := append(
+		[]chan *pgconn.Notification(nil),
+		l.subscriptions[notification.Channel]...,
+	)
+	l.subscriptionsMu.Unlock()
+
+	// Send the notification
+	for _, notifications := range subscriptions {
+		select {
+		case notifications <- notification:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Notify sends a notification within the transaction, so it is only delivered if the transaction commits
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - tx: the transaction, the active transaction in the context is used if nil
+//   - channel: the notification channel
+//   - payload: the payload, encoded as JSON
+//
+// Returns:
+//
+//   - error: if any error occurs
+func Notify(
+	ctx context.Context,
+	tx pgx.Tx,
+	channel string,
+	payload any,
+) error {
+	// Get the active transaction in the context if the transaction is nil
+	if tx == nil {
+		var ok bool
+		if tx, ok = GetTxFromContext(ctx); !ok {
+			return ErrNilTransaction
+		}
+	}
+
+	// Check if the channel is empty
+	if channel == "" {
+		return ErrEmptyNotificationChannel
+	}
+
+	// Encode the payload
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// Send the notification
+	_, err = tx.Exec(
+		ctx,
+		"SELECT pg_notify($1, $2)",
+		channel,
+		string(encodedPayload),
+	)
+	return err
+}
+
+// DecodePayload decodes the JSON payload of a notification sent through Notify
+//
+// Parameters:
+//
+//   - notification: the notification
+//
+// Returns:
+//
+//   - *T: the decoded payload
+//   - error: if the notification is nil or its payload could not be decoded
+func DecodePayload[T any](notification *pgconn.Notification) (*T, error) {
+	// Check if the notification is nil
+	if notification == nil {
+		return nil, ErrNilNotification
+	}
+
+	// Decode the payload
+	payload := new(T)
+	if err := json.Unmarshal([]byte(notification.Payload), payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNotificationPayload, err)
+	}
+	return payload, nil
+}
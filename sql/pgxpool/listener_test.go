@@ -0,0 +1,174 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+)
+
+type (
+	// testPayload is the payload of the test notifications
+	testPayload struct {
+		ID     int64    `json:"id"`
+		Status string   `json:"status"`
+		Tags   []string `json:"tags,omitempty"`
+	}
+)
+
+// notifyTx is a transaction that records the notifications sent through it
+func notifyTx(notifications *[]*pgconn.Notification) *fakeTx {
+	return &fakeTx{
+		execFn: func(query string, args []any) error {
+			if query != "SELECT pg_notify($1, $2)" || len(args) != 2 {
+				return errors.New("unexpected query: " + query)
+			}
+			*notifications = append(
+				*notifications,
+				&pgconn.Notification{Channel: args[0].(string), Payload: args[1].(string)},
+			)
+			return nil
+		},
+	}
+}
+
+func TestNotifyDecodePayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload testPayload
+		useCtx  bool
+	}{
+		{name: "payload", payload: testPayload{ID: 1, Status: "created"}},
+		{name: "nested values", payload: testPayload{ID: 2, Status: "tagged", Tags: []string{"a", "b"}}},
+		{name: "zero payload"},
+		{name: "transaction in the context", payload: testPayload{ID: 3, Status: "shipped"}, useCtx: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var notifications []*pgconn.Notification
+				var tx pgx.Tx = notifyTx(&notifications)
+
+				// Send the notification, through the context transaction if set
+				ctx := context.Background()
+				if tt.useCtx {
+					ctx = WithTx(ctx, tx)
+					tx = nil
+				}
+				if err := Notify(ctx, tx, "orders", tt.payload); err != nil {
+					t.Fatalf("Notify() error = %v", err)
+				}
+				if len(notifications) != 1 || notifications[0].Channel != "orders" {
+					t.Fatalf("notifications = %+v, want one on the orders channel", notifications)
+				}
+
+				// Check that the payload is decoded back
+				got, err := DecodePayload[testPayload](notifications[0])
+				if err != nil {
+					t.Fatalf("DecodePayload() error = %v", err)
+				}
+				if !reflect.DeepEqual(*got, tt.payload) {
+					t.Errorf("DecodePayload() = %+v, want %+v", *got, tt.payload)
+				}
+			},
+		)
+	}
+}
+
+func TestNotifyErrors(t *testing.T) {
+	var notifications []*pgconn.Notification
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		tx      pgx.Tx
+		channel string
+		payload any
+		wantErr error
+	}{
+		{
+			name:    "no transaction",
+			ctx:     context.Background(),
+			channel: "orders",
+			wantErr: ErrNilTransaction,
+		},
+		{
+			name:    "empty channel",
+			ctx:     context.Background(),
+			tx:      notifyTx(&notifications),
+			wantErr: ErrEmptyNotificationChannel,
+		},
+		{
+			name:    "payload not encodable",
+			ctx:     context.Background(),
+			tx:      notifyTx(&notifications),
+			channel: "orders",
+			payload: make(chan int),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := Notify(tt.ctx, tt.tx, tt.channel, tt.payload)
+				if err == nil {
+					t.Fatalf("Notify() error = nil, want error")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Notify() error = %v, want %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+	if len(notifications) != 0 {
+		t.Errorf("notifications = %+v, want none", notifications)
+	}
+}
+
+func TestDecodePayloadErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification *pgconn.Notification
+		wantErr      error
+	}{
+		{name: "nil notification", wantErr: ErrNilNotification},
+		{
+			name:         "invalid payload",
+			notification: &pgconn.Notification{Channel: "orders", Payload: "not json"},
+			wantErr:      ErrInvalidNotificationPayload,
+		},
+		{
+			name:         "mismatched payload",
+			notification: &pgconn.Notification{Channel: "orders", Payload: `{"id":"one"}`},
+			wantErr:      ErrInvalidNotificationPayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if _, err := DecodePayload[testPayload](tt.notification); !errors.Is(err, tt.wantErr) {
+					t.Errorf("DecodePayload() error = %v, want %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestListenerNilArguments(t *testing.T) {
+	if _, err := NewListener(nil, nil, nil); !errors.Is(err, godatabases.ErrNilPool) {
+		t.Errorf("NewListener() error = %v, want %v", err, godatabases.ErrNilPool)
+	}
+
+	var nilListener *Listener
+	if _, err := nilListener.Subscribe("orders", 0); !errors.Is(err, ErrNilListener) {
+		t.Errorf("Subscribe() error = %v, want %v", err, ErrNilListener)
+	}
+	if err := nilListener.Start(context.Background()); !errors.Is(err, ErrNilListener) {
+		t.Errorf("Start() error = %v, want %v", err, ErrNilListener)
+	}
+	nilListener.Stop()
+}
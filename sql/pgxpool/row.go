@@ -0,0 +1,42 @@
+package pgxpool
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+type (
+	// spanRow is a row that ends the span of its query once it is scanned
+	//
+	// As pgx defers the query error until the row is scanned, the span is ended by Scan with the scan error.
+	// The row must be scanned, as pgx requires to release its connection.
+	spanRow struct {
+		row  pgx.Row
+		span trace.Span
+	}
+)
+
+// Scan scans the row, ending the span of the query with the error if any
+//
+// Parameters:
+//
+//   - dest: the destinations to scan into
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (s *spanRow) Scan(dest ...any) error {
+	err := s.row.Scan(dest...)
+
+	// End the span, a missing row is not a query failure
+	spanErr := err
+	if errors.Is(err, pgx.ErrNoRows) {
+		spanErr = nil
+	}
+	telemetry.EndSpan(s.span, spanErr)
+	return err
+}
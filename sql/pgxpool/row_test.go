@@ -0,0 +1,69 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type (
+	// errRow is a row whose scan returns the given error
+	errRow struct {
+		err error
+	}
+)
+
+func (e errRow) Scan(...any) error {
+	return e.err
+}
+
+func TestSpanRowScan(t *testing.T) {
+	errQuery := errors.New("relation does not exist")
+
+	tests := []struct {
+		name          string
+		err           error
+		wantException bool
+	}{
+		{name: "scanned row"},
+		{name: "missing row", err: pgx.ErrNoRows},
+		{name: "query error", err: errQuery, wantException: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				recorder := tracetest.NewSpanRecorder()
+				tracer := sdktrace.NewTracerProvider(
+					sdktrace.WithSpanProcessor(recorder),
+				).Tracer("test")
+				_, parent := tracer.Start(context.Background(), "caller")
+				_, span := tracer.Start(context.Background(), "query")
+
+				row := &spanRow{row: errRow{err: tt.err}, span: span}
+				if err := row.Scan(); !errors.Is(err, tt.err) {
+					t.Fatalf("Scan() error = %v, want %v", err, tt.err)
+				}
+
+				// Check that only the query span is ended, with the exception event of the error
+				spans := recorder.Ended()
+				if len(spans) != 1 || spans[0].Name() != "query" {
+					t.Fatalf("ended spans = %v, want the query span", spans)
+				}
+				gotException := false
+				for _, event := range spans[0].Events() {
+					if event.Name == "exception" {
+						gotException = true
+					}
+				}
+				if gotException != tt.wantException {
+					t.Errorf("exception event = %v, want %v", gotException, tt.wantException)
+				}
+				parent.End()
+			},
+		)
+	}
+}
@@ -0,0 +1,101 @@
+package pgxpool
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ralvarezdev/go-databases/internal/structs"
+)
+
+type (
+	// OpenRowsFn is the function type used to open the rows lazily
+	OpenRowsFn func() (pgx.Rows, error)
+)
+
+// IterateRows returns an iterator over the rows opened by the given function
+//
+// The rows are only opened when the iterator is ranged over, and they are
+// always closed once the iteration finishes, even if it is stopped early.
+// Any error returned while opening or iterating the rows is yielded as the
+// last element with a nil row.
+//
+// Parameters:
+//
+//   - openFn: the function that opens the rows
+//
+// Returns:
+//
+//   - iter.Seq2[pgx.Rows, error]: the rows iterator
+func IterateRows(openFn OpenRowsFn) iter.Seq2[pgx.Rows, error] {
+	return func(yield func(pgx.Rows, error) bool) {
+		// Check if the open function is nil
+		if openFn == nil {
+			yield(nil, ErrNilOpenRowsFn)
+			return
+		}
+
+		// Open the rows
+		rows, err := openFn()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer rows.Close()
+
+		// Iterate over the rows
+		for rows.Next() {
+			if !yield(rows, nil) {
+				return
+			}
+		}
+
+		// Check if there was an error during the iteration
+		if err = rows.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// ScanStruct scans the current row into a new struct, mapping the columns to the fields through their 'db' tag
+//
+// It can be passed to pgx.CollectRows and pgx.CollectOneRow. Unlike pgx.RowToAddrOfStructByName, which also
+// maps the untagged fields by their name, only the tagged fields are mapped, as done by the sql package
+// ScanStruct and by StructRows. Untagged embedded structs are flattened.
+//
+// Parameters:
+//
+//   - row: the row to scan
+//
+// Returns:
+//
+//   - *T: the scanned struct
+//   - error: if any error occurs, or if a column is not mapped to any field
+func ScanStruct[T any](row pgx.CollectableRow) (*T, error) {
+	// Get the struct mapping
+	instance := new(T)
+	value := reflect.ValueOf(instance).Elem()
+	mapping, err := structs.GetMapping(value.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the destinations for each column
+	fields := row.FieldDescriptions()
+	destinations := make([]any, len(fields))
+	for i, field := range fields {
+		structField, ok := mapping.FieldByColumn(value, field.Name)
+		if !ok {
+			return nil, fmt.Errorf(ErrUnmappedColumn, field.Name, value.Type())
+		}
+		destinations[i] = structField.Addr().Interface()
+	}
+
+	// Scan the row
+	if err = row.Scan(destinations...); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
@@ -0,0 +1,566 @@
+package pgxpool
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+type (
+	// DefaultService is the default service struct
+	//
+	// A span is recorded for each query run through the service if the telemetry providers are set.
+	DefaultService struct {
+		Handler
+	}
+)
+
+// NewDefaultService creates a new default service
+//
+// Parameters:
+//
+//   - config: the configuration for the pool
+//
+// Returns:
+//
+//   - *DefaultService: the default service
+//   - error: if there was an error creating the service
+func NewDefaultService(config *Config) (
+	instance *DefaultService,
+	err error,
+) {
+	// Create the handler
+	handler, err := NewDefaultHandler(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DefaultService{
+		Handler: handler,
+	}, nil
+}
+
+// CreateTransaction creates a transaction for the database
+//
+// The transaction is stored in the context passed to the function, so the service methods called with that
+// context are executed within the transaction.
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - fn: The function to execute within the transaction
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func (d *DefaultService) CreateTransaction(
+	ctx context.Context,
+	fn TransactionFn,
+) error {
+	if d == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Get the pool
+	pool, err := d.DB()
+	if err != nil {
+		return err
+	}
+
+	// Create the transaction
+	return CreateTransaction(ctx, pool, fn)
+}
+
+// CreateTransactionWithOptions creates a transaction for the database with the given options
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func (d *DefaultService) CreateTransactionWithOptions(
+	ctx context.Context,
+	fn TransactionFn,
+	opts pgx.TxOptions,
+) error {
+	if d == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Get the pool
+	pool, err := d.DB()
+	if err != nil {
+		return err
+	}
+
+	// Create the transaction
+	return CreateTransactionWithOptions(ctx, pool, fn, opts)
+}
+
+// CreateTransactionWithRetry creates a transaction for the database with the given options, running it again
+// while it fails with a retryable error
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//   - policy: The retry policy, the default retry policy is used if nil
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func (d *DefaultService) CreateTransactionWithRetry(
+	ctx context.Context,
+	fn TransactionFn,
+	opts pgx.TxOptions,
+	policy *gosql.RetryPolicy,
+) error {
+	if d == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Get the pool
+	pool, err := d.DB()
+	if err != nil {
+		return err
+	}
+
+	// Create the transaction with retries
+	return CreateTransactionWithRetry(ctx, pool, fn, opts, policy)
+}
+
+// ReadOnlyTransaction creates a read-only transaction for the database
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - fn: The function to execute within the transaction
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func (d *DefaultService) ReadOnlyTransaction(
+	ctx context.Context,
+	fn TransactionFn,
+) error {
+	return d.CreateTransactionWithOptions(
+		ctx,
+		fn,
+		readOnlyTxOptions,
+	)
+}
+
+// SerializableTransaction creates a serializable transaction for the database, running it again while it
+// fails with a serialization failure or a detected deadlock
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - fn: The function to execute within the transaction, which must be safe to run more than once
+//   - policy: The retry policy, the default retry policy is used if nil
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func (d *DefaultService) SerializableTransaction(
+	ctx context.Context,
+	fn TransactionFn,
+	policy *gosql.RetryPolicy,
+) error {
+	return d.CreateTransactionWithRetry(
+		ctx,
+		fn,
+		serializableTxOptions,
+		policy,
+	)
+}
+
+// SendBatch sends the queued statements in a single round trip
+//
+// If the context carries an active transaction, the batch is sent within it.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - b: the batch to send
+//
+// Returns:
+//
+//   - error: the joined *BatchError of the failed statements, nil if every statement succeeded
+func (d *DefaultService) SendBatch(ctx context.Context, b *Batch) error {
+	if d == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Get the pool
+	pool, err := d.DB()
+	if err != nil {
+		return err
+	}
+
+	// Send the batch
+	return SendBatch(ctx, pool, b)
+}
+
+// SendBatchInTransaction sends the queued statements in a single round trip within a transaction
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - b: the batch to send
+//
+// Returns:
+//
+//   - error: the joined *BatchError of the failed statements, or the transaction error
+func (d *DefaultService) SendBatchInTransaction(
+	ctx context.Context,
+	b *Batch,
+) error {
+	if d == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Get the pool
+	pool, err := d.DB()
+	if err != nil {
+		return err
+	}
+
+	// Send the batch within a transaction
+	return SendBatchInTransaction(ctx, pool, b)
+}
+
+// CopyFrom copies the rows yielded by the iterator into the table with the COPY protocol, in chunks
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - table: the target table
+//   - columns: the target columns, in the order of the values of each row
+//   - rows: the iterator over the values of each row
+//   - opts: the copy options, it can be nil
+//
+// Returns:
+//
+//   - int64: the number of rows copied
+//   - error: if any error occurs
+func (d *DefaultService) CopyFrom(
+	ctx context.Context,
+	table pgx.Identifier,
+	columns []string,
+	rows iter.Seq2[[]any, error],
+	opts *CopyOptions,
+) (int64, error) {
+	if d == nil {
+		return 0, godatabases.ErrNilService
+	}
+
+	// Get the pool
+	pool, err := d.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	// Copy the rows
+	return CopyFrom(ctx, pool, table, columns, rows, opts)
+}
+
+// executor returns the active transaction in the context, or the pool if there is none
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - Executor: the executor to run the queries with
+//   - error: if the pool is not created
+func (d *DefaultService) executor(ctx context.Context) (Executor, error) {
+	// Check if there is an active transaction in the context
+	if tx, ok := GetTxFromContext(ctx); ok {
+		return tx, nil
+	}
+
+	// Get the pool
+	return d.DB()
+}
+
+// startSpan starts the span of a query
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - query: the query to run
+//
+// Returns:
+//
+//   - context.Context: the context carrying the span
+//   - trace.Span: the span
+func startSpan(ctx context.Context, query string) (
+	context.Context,
+	trace.Span,
+) {
+	return telemetry.StartSpan(
+		ctx,
+		telemetry.SystemPostgreSQL,
+		telemetry.OperationName(query),
+		query,
+	)
+}
+
+// Exec executes a query with parameters and returns the command tag
+//
+// Parameters:
+//
+//   - query: the query to execute
+//   - params: the parameters for the query
+//
+// Returns:
+//
+//   - pgconn.CommandTag: the command tag of the execution
+//   - error: if any error occurs
+func (d *DefaultService) Exec(query *string, params ...any) (
+	pgconn.CommandTag,
+	error,
+) {
+	if d == nil {
+		return pgconn.CommandTag{}, godatabases.ErrNilService
+	}
+	return d.ExecWithCtx(context.Background(), query, params...)
+}
+
+// ExecWithCtx executes a query with parameters and returns the command tag with a context
+//
+// If the context carries an active transaction, the query is executed within it.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - query: the query to execute
+//   - params: the parameters for the query
+//
+// Returns:
+//
+//   - pgconn.CommandTag: the command tag of the execution
+//   - error: if any error occurs
+func (d *DefaultService) ExecWithCtx(
+	ctx context.Context,
+	query *string,
+	params ...any,
+) (
+	pgconn.CommandTag,
+	error,
+) {
+	if d == nil {
+		return pgconn.CommandTag{}, godatabases.ErrNilService
+	}
+
+	// Check if the query is nil
+	if query == nil {
+		return pgconn.CommandTag{}, godatabases.ErrNilQuery
+	}
+
+	// Get the executor, using the active transaction in the context if any
+	executor, err := d.executor(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	// Execute the query
+	ctx, span := startSpan(ctx, *query)
+	commandTag, err := executor.Exec(ctx, *query, params...)
+	telemetry.EndSpan(span, err)
+	return commandTag, err
+}
+
+// QueryRow runs a query row with parameters and returns the result row
+//
+// Parameters:
+//
+//   - query: the query to execute
+//   - params: the parameters for the query
+//
+// Returns:
+//
+//   - pgx.Row: the result row
+//   - error: if any error occurs
+func (d *DefaultService) QueryRow(
+	query *string,
+	params ...any,
+) (pgx.Row, error) {
+	if d == nil {
+		return nil, godatabases.ErrNilService
+	}
+	return d.QueryRowWithCtx(context.Background(), query, params...)
+}
+
+// QueryRowWithCtx runs a query row with parameters and returns the result row with a context
+//
+// If the context carries an active transaction, the query is executed within it. As with pgx, the query
+// error is deferred until the row is scanned, so the query span is ended once the row is scanned, with the
+// scan error if any. The row must be scanned, as pgx requires to release its connection.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - query: the query to execute
+//   - params: the parameters for the query
+//
+// Returns:
+//
+//   - pgx.Row: the result row
+//   - error: if any error occurs
+func (d *DefaultService) QueryRowWithCtx(
+	ctx context.Context,
+	query *string,
+	params ...any,
+) (pgx.Row, error) {
+	if d == nil {
+		return nil, godatabases.ErrNilService
+	}
+
+	// Check if the query is nil
+	if query == nil {
+		return nil, godatabases.ErrNilQuery
+	}
+
+	// Get the executor, using the active transaction in the context if any
+	executor, err := d.executor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the query row, the span is ended once the row is scanned
+	spanCtx, span := startSpan(ctx, *query)
+	row := executor.QueryRow(spanCtx, *query, params...)
+	return &spanRow{row: row, span: span}, nil
+}
+
+// Query runs a query with parameters and returns an iterator over the result rows
+//
+// Parameters:
+//
+//   - query: the query to execute
+//   - params: the parameters for the query
+//
+// Returns:
+//
+//   - iter.Seq2[pgx.Rows, error]: the result rows iterator
+//   - error: if any error occurs
+func (d *DefaultService) Query(
+	query *string,
+	params ...any,
+) (iter.Seq2[pgx.Rows, error], error) {
+	if d == nil {
+		return nil, godatabases.ErrNilService
+	}
+	return d.QueryWithCtx(context.Background(), query, params...)
+}
+
+// QueryWithCtx runs a query with parameters and returns an iterator over the result rows with a context
+//
+// The query is executed lazily when the iterator is ranged over, and the rows are always closed once the
+// iteration finishes. Any error returned by the query or by the rows is yielded with a nil row. If the
+// context carries an active transaction, the query is executed within it. The query span is ended once the
+// iteration finishes, even if the consumer panics, at the time of the first row or error, so the time spent
+// by the consumer is not reported.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - query: the query to execute
+//   - params: the parameters for the query
+//
+// Returns:
+//
+//   - iter.Seq2[pgx.Rows, error]: the result rows iterator
+//   - error: if any error occurs
+func (d *DefaultService) QueryWithCtx(
+	ctx context.Context,
+	query *string,
+	params ...any,
+) (iter.Seq2[pgx.Rows, error], error) {
+	if d == nil {
+		return nil, godatabases.ErrNilService
+	}
+
+	// Check if the query is nil
+	if query == nil {
+		return nil, godatabases.ErrNilQuery
+	}
+
+	// Get the executor, using the active transaction in the context if any
+	executor, err := d.executor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the rows iterator, recording the span around the whole iteration
+	return func(yield func(pgx.Rows, error) bool) {
+		spanCtx, span := startSpan(ctx, *query)
+
+		// End the span once the iteration finishes, even if the consumer panics
+		var iterErr error
+		var endedAt time.Time
+		defer func() {
+			if endedAt.IsZero() {
+				endedAt = time.Now()
+			}
+			telemetry.EndSpan(span, iterErr, trace.WithTimestamp(endedAt))
+		}()
+
+		for row, rowErr := range IterateRows(
+			func() (pgx.Rows, error) {
+				return executor.Query(spanCtx, *query, params...)
+			},
+		) {
+			// Measure the query up to its first row or error, before the consumer handles it
+			if endedAt.IsZero() {
+				endedAt = time.Now()
+			}
+
+			if rowErr != nil {
+				iterErr = rowErr
+			}
+			if !yield(row, rowErr) {
+				return
+			}
+		}
+	}, nil
+}
+
+// ScanRow scans a row
+//
+// Parameters:
+//
+//   - row: the row to scan
+//   - destinations: the destinations to scan into
+//
+// Returns:
+//
+//   - error: if any error occurs
+func (d *DefaultService) ScanRow(
+	row pgx.Row,
+	destinations ...any,
+) error {
+	// Check if the row is nil
+	if row == nil {
+		return godatabases.ErrNilRow
+	}
+
+	// Scan the row
+	return row.Scan(destinations...)
+}
@@ -0,0 +1,218 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+type (
+	// fakeTx is a transaction whose queries are answered by the given functions, recording how it ended
+	fakeTx struct {
+		pgx.Tx
+		execFn     func(query string, args []any) error
+		queryFn    func(query string) (pgx.Rows, error)
+		queryRowFn func(query string) pgx.Row
+		commitErr  error
+		savepoints []*fakeTx
+		committed  bool
+		rolledBack bool
+	}
+
+	// fakeRows are rows that yield the given number of rows, and then the given error
+	fakeRows struct {
+		pgx.Rows
+		count  int
+		err    error
+		closed bool
+	}
+)
+
+func (f *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	savepoint := &fakeTx{}
+	f.savepoints = append(f.savepoints, savepoint)
+	return savepoint, nil
+}
+
+func (f *fakeTx) Commit(context.Context) error {
+	f.committed = true
+	return f.commitErr
+}
+
+func (f *fakeTx) Rollback(context.Context) error {
+	f.rolledBack = true
+	return nil
+}
+
+func (f *fakeTx) Exec(_ context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, f.execFn(query, args)
+}
+
+func (f *fakeTx) Query(_ context.Context, query string, _ ...any) (pgx.Rows, error) {
+	return f.queryFn(query)
+}
+
+func (f *fakeTx) QueryRow(_ context.Context, query string, _ ...any) pgx.Row {
+	return f.queryRowFn(query)
+}
+
+func (f *fakeRows) Next() bool {
+	if f.closed || f.count == 0 {
+		return false
+	}
+	f.count--
+	return true
+}
+
+func (f *fakeRows) Err() error {
+	return f.err
+}
+
+func (f *fakeRows) Close() {
+	f.closed = true
+}
+
+// setTestProviders sets the telemetry providers to an in-memory span recorder for the test
+func setTestProviders(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	telemetry.SetProviders(
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		sdkmetric.NewMeterProvider(),
+	)
+	t.Cleanup(func() { telemetry.SetProviders(nil, nil) })
+	return recorder
+}
+
+func TestDefaultServiceQueryRowWithCtxSpan(t *testing.T) {
+	errQuery := errors.New("relation does not exist")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{name: "scanned row", wantStatus: codes.Unset},
+		{name: "missing row", err: pgx.ErrNoRows, wantStatus: codes.Unset},
+		{name: "query error", err: errQuery, wantStatus: codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				recorder := setTestProviders(t)
+				ctx := WithTx(
+					context.Background(),
+					&fakeTx{queryRowFn: func(string) pgx.Row { return errRow{err: tt.err} }},
+				)
+
+				query := "SELECT name FROM users"
+				row, err := (&DefaultService{}).QueryRowWithCtx(ctx, &query)
+				if err != nil {
+					t.Fatalf("QueryRowWithCtx() error = %v", err)
+				}
+
+				// Check that the span is only ended once the row is scanned
+				if ended := recorder.Ended(); len(ended) != 0 {
+					t.Fatalf("ended spans before Scan() = %d, want 0", len(ended))
+				}
+				if err = row.Scan(); !errors.Is(err, tt.err) {
+					t.Fatalf("Scan() error = %v, want %v", err, tt.err)
+				}
+				ended := recorder.Ended()
+				if len(ended) != 1 {
+					t.Fatalf("ended spans = %d, want 1", len(ended))
+				}
+				if got := ended[0].Status().Code; got != tt.wantStatus {
+					t.Errorf("span status = %v, want %v", got, tt.wantStatus)
+				}
+			},
+		)
+	}
+}
+
+func TestDefaultServiceQueryWithCtxSpan(t *testing.T) {
+	errQuery := errors.New("relation does not exist")
+	consumerDelay := 50 * time.Millisecond
+
+	tests := []struct {
+		name       string
+		rows       *fakeRows
+		queryErr   error
+		stopEarly  bool
+		panics     bool
+		wantStatus codes.Code
+	}{
+		{name: "every row", rows: &fakeRows{count: 3}, wantStatus: codes.Unset},
+		{name: "stopped early", rows: &fakeRows{count: 3}, stopEarly: true, wantStatus: codes.Unset},
+		{name: "query error", queryErr: errQuery, wantStatus: codes.Error},
+		{name: "rows error", rows: &fakeRows{count: 1, err: errQuery}, wantStatus: codes.Error},
+		{name: "consumer panic", rows: &fakeRows{count: 3}, panics: true, wantStatus: codes.Unset},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				recorder := setTestProviders(t)
+				ctx := WithTx(
+					context.Background(),
+					&fakeTx{
+						queryFn: func(string) (pgx.Rows, error) {
+							if tt.queryErr != nil {
+								return nil, tt.queryErr
+							}
+							return tt.rows, nil
+						},
+					},
+				)
+
+				query := "SELECT name FROM users"
+				rows, err := (&DefaultService{}).QueryWithCtx(ctx, &query)
+				if err != nil {
+					t.Fatalf("QueryWithCtx() error = %v", err)
+				}
+
+				// Consume the rows slowly, so the time spent by the consumer would be noticed
+				func() {
+					defer func() {
+						if recovered := recover(); (recovered != nil) != tt.panics {
+							t.Errorf("recovered = %v, want panic %v", recovered, tt.panics)
+						}
+					}()
+					for range rows {
+						time.Sleep(consumerDelay)
+						if tt.panics {
+							panic("consumer failed")
+						}
+						if tt.stopEarly {
+							break
+						}
+					}
+				}()
+
+				// Check that the span is ended at the first row, with the query error
+				ended := recorder.Ended()
+				if len(ended) != 1 {
+					t.Fatalf("ended spans = %d, want 1", len(ended))
+				}
+				if got := ended[0].Status().Code; got != tt.wantStatus {
+					t.Errorf("span status = %v, want %v", got, tt.wantStatus)
+				}
+				if duration := ended[0].EndTime().Sub(ended[0].StartTime()); duration >= consumerDelay {
+					t.Errorf("span duration = %v, want less than %v", duration, consumerDelay)
+				}
+				if tt.rows != nil && !tt.rows.closed {
+					t.Errorf("rows closed = false, want true")
+				}
+			},
+		)
+	}
+}
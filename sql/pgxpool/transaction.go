@@ -7,15 +7,38 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+var (
+	// readOnlyTxOptions are the options of the read-only transactions
+	readOnlyTxOptions = pgx.TxOptions{AccessMode: pgx.ReadOnly}
+
+	// serializableTxOptions are the options of the serializable transactions
+	serializableTxOptions = pgx.TxOptions{IsoLevel: pgx.Serializable}
 )
 
 type (
 	// TransactionFn is the function type for transactions with context
+	//
+	// The context carries the active transaction, so nested calls to CreateTransaction create a savepoint
+	// instead of starting a new transaction.
 	TransactionFn func(ctx context.Context, tx pgx.Tx) error
+
+	// txBeginner is the interface implemented by *pgxpool.Pool to begin the transactions
+	txBeginner interface {
+		BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	}
 )
 
 // CreateTransaction creates a transaction for the database with context
 //
+// If the context already carries an active transaction, a savepoint is created within it instead, which is
+// released if the function succeeds or rolled back to if it fails. If the function panics, the transaction
+// or the savepoint is rolled back and the panic is propagated. A span is recorded for the transaction with its
+// outcome if the telemetry providers are set.
+//
 // Parameters:
 //
 //   - ctx: The context for the transaction
@@ -29,21 +52,261 @@ func CreateTransaction(
 	ctx context.Context,
 	pool *pgxpool.Pool,
 	fn TransactionFn,
+) error {
+	return CreateTransactionWithOptions(ctx, pool, fn, pgx.TxOptions{})
+}
+
+// CreateTransactionWithOptions creates a transaction for the database with context and the given options
+//
+// It behaves like CreateTransaction, starting the transaction with the given isolation level, access mode,
+// deferrable mode and begin query. The options are ignored if the context already carries an active
+// transaction, since the savepoint inherits the ones of the outer transaction.
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - pool: The pgxpool.Pool instance
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//
+// Returns:
+//
+//   - error: An error if the transaction fails, otherwise nil
+func CreateTransactionWithOptions(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	fn TransactionFn,
+	opts pgx.TxOptions,
 ) error {
 	// Check if the pool is nil
 	if pool == nil {
 		return godatabases.ErrNilPool
 	}
 
-	// Start a transaction
-	tx, err := pool.Begin(ctx)
+	return createTransactionAttempt(ctx, pool, fn, opts, 1, nil)
+}
+
+// createTransactionAttempt creates a transaction for the database as an attempt of the retry policy
+//
+// The outcome recorded for the attempt is the retry one if it failed and the policy runs it again, so each
+// attempt is counted once.
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - beginner: The pool to begin the transaction with
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//   - attempt: The attempt number, starting at 1
+//   - policy: The retry policy used to tell the retried attempts apart, it can be nil
+//
+// Returns:
+//
+//   - error: An error if the transaction fails, otherwise nil
+func createTransactionAttempt(
+	ctx context.Context,
+	beginner txBeginner,
+	fn TransactionFn,
+	opts pgx.TxOptions,
+	attempt int,
+	policy *gosql.RetryPolicy,
+) (err error) {
+	// Check if the transaction function is nil
+	if fn == nil {
+		return ErrNilTransactionFn
+	}
+
+	// Start the transaction span
+	parentTx, nested := GetTxFromContext(ctx)
+	ctx, span := telemetry.StartSpan(
+		ctx,
+		telemetry.SystemPostgreSQL,
+		telemetry.OperationTransaction,
+		"",
+		telemetry.AttributeTransactionNested.Bool(nested),
+	)
+	outcome := telemetry.OutcomeRollback
+	defer func() {
+		telemetry.EndTransactionSpan(
+			ctx,
+			span,
+			telemetry.SystemPostgreSQL,
+			outcome,
+			err,
+		)
+	}()
+
+	// Start a transaction, or a savepoint if there is an active transaction in the context
+	var tx pgx.Tx
+	if nested {
+		tx, err = parentTx.Begin(ctx)
+	} else {
+		tx, err = beginner.BeginTx(ctx, opts)
+	}
 	if err != nil {
 		return err
 	}
 
+	err = runTransaction(ctx, tx, fn)
+	switch {
+	case err == nil:
+		outcome = telemetry.OutcomeCommit
+	case policy.ShouldRetry(attempt, err):
+		outcome = telemetry.OutcomeRetry
+	}
+	return err
+}
+
+// CreateTransactionWithRetry creates a transaction for the database with the given options, running it again
+// while it fails with a retryable error
+//
+// If the context already carries an active transaction, the function is run once within a savepoint.
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - pool: The pgxpool.Pool instance
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//   - policy: The retry policy, the default retry policy is used if nil
+//
+// Returns:
+//
+//   - error: An error if the transaction fails, otherwise nil
+func CreateTransactionWithRetry(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	fn TransactionFn,
+	opts pgx.TxOptions,
+	policy *gosql.RetryPolicy,
+) error {
+	// Check if the pool is nil
+	if pool == nil {
+		return godatabases.ErrNilPool
+	}
+
+	return createTransactionWithRetry(ctx, pool, fn, opts, policy)
+}
+
+// createTransactionWithRetry creates a transaction for the database with the given options, running it again
+// while it fails with a retryable error
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - beginner: The pool to begin the transactions with
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//   - policy: The retry policy, the default retry policy is used if nil
+//
+// Returns:
+//
+//   - error: An error if the transaction fails, otherwise nil
+func createTransactionWithRetry(
+	ctx context.Context,
+	beginner txBeginner,
+	fn TransactionFn,
+	opts pgx.TxOptions,
+	policy *gosql.RetryPolicy,
+) error {
+	// Check if there is an active transaction in the context
+	if _, ok := GetTxFromContext(ctx); ok {
+		return createTransactionAttempt(ctx, beginner, fn, opts, 1, nil)
+	}
+
+	// Set the default retry policy
+	if policy == nil {
+		policy = gosql.NewDefaultRetryPolicy()
+	}
+
+	// Run the transaction with retries
+	attempt := 0
+	return policy.Run(
+		ctx,
+		func(ctx context.Context) error {
+			attempt++
+			return createTransactionAttempt(ctx, beginner, fn, opts, attempt, policy)
+		},
+	)
+}
+
+// ReadOnlyTransaction creates a read-only transaction for the database
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - pool: The pgxpool.Pool instance
+//   - fn: The function to execute within the transaction
+//
+// Returns:
+//
+//   - error: An error if the transaction fails, otherwise nil
+func ReadOnlyTransaction(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	fn TransactionFn,
+) error {
+	return CreateTransactionWithOptions(
+		ctx,
+		pool,
+		fn,
+		readOnlyTxOptions,
+	)
+}
+
+// SerializableTransaction creates a serializable transaction for the database, running it again while it
+// fails with a serialization failure or a detected deadlock
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - pool: The pgxpool.Pool instance
+//   - fn: The function to execute within the transaction, which must be safe to run more than once
+//   - policy: The retry policy, the default retry policy is used if nil
+//
+// Returns:
+//
+//   - error: An error if the transaction fails, otherwise nil
+func SerializableTransaction(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	fn TransactionFn,
+	policy *gosql.RetryPolicy,
+) error {
+	return CreateTransactionWithRetry(
+		ctx,
+		pool,
+		fn,
+		serializableTxOptions,
+		policy,
+	)
+}
+
+// runTransaction executes the function within the given transaction, committing it if the function succeeds
+// and rolling it back if it fails or panics
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - tx: The started transaction
+//   - fn: The function to execute within the transaction
+//
+// Returns:
+//
+//   - error: An error if the transaction fails, otherwise nil
+func runTransaction(ctx context.Context, tx pgx.Tx, fn TransactionFn) error {
+	// Rollback the transaction if the function panics
+	defer func() {
+		if p := recover(); p != nil {
+			//nolint:contextcheck // The rollback must run even if the context is done
+			_ = tx.Rollback(context.Background())
+			panic(p)
+		}
+	}()
+
 	// Execute the transaction function
-	if fnErr := fn(ctx, tx); fnErr != nil {
-		err = tx.Rollback(ctx)
+	if fnErr := fn(WithTx(ctx, tx), tx); fnErr != nil {
+		err := tx.Rollback(ctx)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,225 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+	gopgx "github.com/ralvarezdev/go-databases/sql/pgx"
+)
+
+type (
+	// fakeBeginner begins fake transactions, recording their options
+	fakeBeginner struct {
+		options []pgx.TxOptions
+		txs     []*fakeTx
+	}
+)
+
+func (f *fakeBeginner) BeginTx(_ context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	f.options = append(f.options, txOptions)
+	tx := &fakeTx{}
+	f.txs = append(f.txs, tx)
+	return tx, nil
+}
+
+func TestCreateTransactionAttemptOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        pgx.TxOptions
+		nested      bool
+		wantOptions []pgx.TxOptions
+	}{
+		{name: "default options", wantOptions: []pgx.TxOptions{{}}},
+		{name: "read-only", opts: readOnlyTxOptions, wantOptions: []pgx.TxOptions{{AccessMode: pgx.ReadOnly}}},
+		{
+			name:        "serializable",
+			opts:        serializableTxOptions,
+			wantOptions: []pgx.TxOptions{{IsoLevel: pgx.Serializable}},
+		},
+		{
+			name: "every option",
+			opts: pgx.TxOptions{
+				IsoLevel:       pgx.RepeatableRead,
+				AccessMode:     pgx.ReadOnly,
+				DeferrableMode: pgx.Deferrable,
+				BeginQuery:     "BEGIN",
+			},
+			wantOptions: []pgx.TxOptions{
+				{
+					IsoLevel:       pgx.RepeatableRead,
+					AccessMode:     pgx.ReadOnly,
+					DeferrableMode: pgx.Deferrable,
+					BeginQuery:     "BEGIN",
+				},
+			},
+		},
+		{name: "ignored by the savepoints", opts: serializableTxOptions, nested: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				beginner := &fakeBeginner{}
+				ctx := context.Background()
+				parentTx := &fakeTx{}
+				if tt.nested {
+					ctx = WithTx(ctx, parentTx)
+				}
+
+				// Check that the function runs within the started transaction
+				var gotTx pgx.Tx
+				if err := createTransactionAttempt(
+					ctx,
+					beginner,
+					func(ctx context.Context, tx pgx.Tx) error {
+						gotTx = tx
+						if ctxTx, ok := GetTxFromContext(ctx); !ok || ctxTx != tx {
+							t.Errorf("context transaction = %v, want %v", ctxTx, tx)
+						}
+						return nil
+					},
+					tt.opts,
+					1,
+					nil,
+				); err != nil {
+					t.Fatalf("createTransactionAttempt() error = %v", err)
+				}
+				if !slices.Equal(beginner.options, tt.wantOptions) {
+					t.Errorf("options = %+v, want %+v", beginner.options, tt.wantOptions)
+				}
+
+				// Check that the transaction or the savepoint is committed
+				want := parentTx.savepoints
+				if !tt.nested {
+					want = beginner.txs
+				}
+				if len(want) != 1 || gotTx != want[0] || !want[0].committed {
+					t.Errorf("committed transactions = %+v, want the one passed to the function", want)
+				}
+			},
+		)
+	}
+}
+
+func TestCreateTransactionWithRetry(t *testing.T) {
+	errSerialization := &pgconn.PgError{Code: gopgx.SerializationFailureCode}
+	errDeadlock := &pgconn.PgError{Code: gopgx.DeadlockDetectedCode}
+	errUnique := &pgconn.PgError{Code: gopgx.UniqueViolationCode}
+	policy := &gosql.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Multiplier:     1,
+	}
+
+	tests := []struct {
+		name         string
+		errs         []error
+		commitErr    error
+		nested       bool
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "succeeded", wantAttempts: 1},
+		{name: "serialization failure retried", errs: []error{errSerialization}, wantAttempts: 2},
+		{name: "deadlock retried", errs: []error{errDeadlock, errDeadlock}, wantAttempts: 3},
+		{
+			name:         "retries exhausted",
+			errs:         []error{errSerialization, errSerialization, errSerialization},
+			wantAttempts: 3,
+			wantErr:      errSerialization,
+		},
+		{name: "not retryable", errs: []error{errUnique}, wantAttempts: 1, wantErr: errUnique},
+		{name: "commit failure retried", commitErr: errSerialization, wantAttempts: 3, wantErr: errSerialization},
+		{
+			name:         "nested not retried",
+			errs:         []error{errSerialization},
+			nested:       true,
+			wantAttempts: 1,
+			wantErr:      errSerialization,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				beginner := &fakeBeginner{}
+				ctx := context.Background()
+				if tt.nested {
+					ctx = WithTx(ctx, &fakeTx{})
+				}
+
+				// Fail the attempts with the given errors
+				attempts := 0
+				err := createTransactionWithRetry(
+					ctx,
+					beginner,
+					func(_ context.Context, tx pgx.Tx) error {
+						tx.(*fakeTx).commitErr = tt.commitErr
+						attempts++
+						if attempts <= len(tt.errs) {
+							return tt.errs[attempts-1]
+						}
+						return nil
+					},
+					serializableTxOptions,
+					policy,
+				)
+				if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+					t.Errorf("createTransactionWithRetry() error = %v, want %v", err, tt.wantErr)
+				}
+				if attempts != tt.wantAttempts {
+					t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+				}
+
+				// Check that the failed attempts are rolled back
+				for i, tx := range beginner.txs {
+					if failed := i < len(tt.errs); tx.rolledBack != failed {
+						t.Errorf("attempt %d rolled back = %v, want %v", i+1, tx.rolledBack, failed)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestCreateTransactionNilArguments(t *testing.T) {
+	fn := func(context.Context, pgx.Tx) error { return nil }
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{
+			name:    "nil pool",
+			err:     CreateTransactionWithOptions(context.Background(), nil, fn, pgx.TxOptions{}),
+			wantErr: godatabases.ErrNilPool,
+		},
+		{
+			name:    "nil pool with retries",
+			err:     CreateTransactionWithRetry(context.Background(), nil, fn, pgx.TxOptions{}, nil),
+			wantErr: godatabases.ErrNilPool,
+		},
+		{
+			name:    "nil function",
+			err:     createTransactionAttempt(context.Background(), &fakeBeginner{}, nil, pgx.TxOptions{}, 1, nil),
+			wantErr: ErrNilTransactionFn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if !errors.Is(tt.err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", tt.err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
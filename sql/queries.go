@@ -3,11 +3,26 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"sync"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+)
+
+type (
+	// QueryFn is the function type for queries run concurrently
+	QueryFn func(ctx context.Context, db *sql.DB) error
+
+	// QueryWithResultFn is the function type for queries run concurrently that return a result
+	QueryWithResultFn[T any] func(ctx context.Context, db *sql.DB) (T, error)
 )
 
 // RunQueriesConcurrently runs multiple queries concurrently
 //
+// Deprecated: use RunQueriesConcurrentlyWithCtx, which accepts a parent context, bounds the parallelism and
+// returns the errors indexed by query position.
+//
 // Parameters:
 //
 //	db: The database connection
@@ -20,46 +35,33 @@ func RunQueriesConcurrently(
 	db *sql.DB,
 	queries ...func(db *sql.DB) error,
 ) []error {
-	// Create a wait group
-	var wg sync.WaitGroup
-	wg.Add(len(queries))
-
-	// Create a channel to handle errors
-	errCh := make(chan error)
-
-	// Execute the queries concurrently
-	for _, query := range queries {
-		go func(query func(db *sql.DB) error) {
-			defer wg.Done()
-			if err := query(db); err != nil {
-				// Send the error to the channel
-				errCh <- err
-			}
-		}(query)
-	}
-
-	// Wait for all queries to complete
-	wg.Wait()
-
-	// Disconnect the error channel
-	close(errCh)
-
-	// Return the errors if any
-	var errors []error
-	for err := range errCh {
-		errors = append(errors, err)
-	}
-
-	// Check if there are any errors
-	if len(errors) == 0 {
-		return nil
+	// Adapt the queries
+	queryFns := make([]QueryFn, len(queries))
+	for i, query := range queries {
+		if query == nil {
+			continue
+		}
+		queryFns[i] = func(_ context.Context, db *sql.DB) error {
+			return query(db)
+		}
 	}
 
-	return errors
+	return compactErrors(
+		RunQueriesConcurrentlyWithCtx(
+			context.Background(),
+			db,
+			len(queries),
+			false,
+			queryFns...,
+		),
+	)
 }
 
 // RunQueriesConcurrentlyWithCancel runs multiple queries concurrently with a cancel context
 //
+// Deprecated: use RunQueriesConcurrentlyWithCtx, which accepts a parent context, bounds the parallelism and
+// returns the errors indexed by query position.
+//
 // Parameters:
 //
 //	db: The database connection
@@ -72,47 +74,208 @@ func RunQueriesConcurrentlyWithCancel(
 	db *sql.DB,
 	queries ...func(db *sql.DB, ctx context.Context) error,
 ) []error {
+	// Adapt the queries
+	queryFns := make([]QueryFn, len(queries))
+	for i, query := range queries {
+		if query == nil {
+			continue
+		}
+		queryFns[i] = func(ctx context.Context, db *sql.DB) error {
+			return query(db, ctx)
+		}
+	}
+
+	return compactErrors(
+		RunQueriesConcurrentlyWithCtx(
+			context.Background(),
+			db,
+			len(queries),
+			true,
+			queryFns...,
+		),
+	)
+}
+
+// RunQueriesConcurrentlyWithCtx runs multiple queries concurrently with a parent context
+//
+// Parameters:
+//
+//	ctx: The parent context, the queries that did not start before it is done are not run
+//	db: The database connection
+//	maxParallelism: The maximum number of queries running at the same time, defaults to the maximum number
+//	of open connections of the database if it is not positive
+//	cancelOnError: Whether to cancel the other queries once a query fails
+//	queries: The queries to run
+//
+// Returns:
+//
+//	[]error: A slice of errors indexed by query position, or nil if no errors occurred
+func RunQueriesConcurrentlyWithCtx(
+	ctx context.Context,
+	db *sql.DB,
+	maxParallelism int,
+	cancelOnError bool,
+	queries ...QueryFn,
+) []error {
+	// Adapt the queries
+	queryFns := make([]QueryWithResultFn[struct{}], len(queries))
+	for i, query := range queries {
+		if query == nil {
+			continue
+		}
+		queryFns[i] = func(ctx context.Context, db *sql.DB) (struct{}, error) {
+			return struct{}{}, query(ctx, db)
+		}
+	}
+
+	_, errs := RunQueriesConcurrentlyWithResults(
+		ctx,
+		db,
+		maxParallelism,
+		cancelOnError,
+		queryFns...,
+	)
+	return errs
+}
+
+// RunQueriesConcurrentlyWithResults runs multiple queries concurrently with a parent context, collecting their
+// results
+//
+// Parameters:
+//
+//	ctx: The parent context, the queries that did not start before it is done are not run
+//	db: The database connection
+//	maxParallelism: The maximum number of queries running at the same time, defaults to the maximum number
+//	of open connections of the database if it is not positive
+//	cancelOnError: Whether to cancel the other queries once a query fails
+//	queries: The queries to run
+//
+// Returns:
+//
+//	[]T: A slice of results indexed by query position
+//	[]error: A slice of errors indexed by query position, or nil if no errors occurred
+func RunQueriesConcurrentlyWithResults[T any](
+	ctx context.Context,
+	db *sql.DB,
+	maxParallelism int,
+	cancelOnError bool,
+	queries ...QueryWithResultFn[T],
+) ([]T, []error) {
+	results := make([]T, len(queries))
+	errs := make([]error, len(queries))
+
+	// Check if the connection is nil
+	if db == nil {
+		for i := range errs {
+			errs[i] = godatabases.ErrNilConnection
+		}
+		return results, errs
+	}
+
+	// Set the default maximum parallelism
+	if maxParallelism <= 0 {
+		maxParallelism = db.Stats().MaxOpenConnections
+	}
+	if maxParallelism <= 0 || maxParallelism > len(queries) {
+		maxParallelism = len(queries)
+	}
+
 	// Create a context with a cancellation function
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Create a wait group
+	// Create a semaphore to bound the parallelism
+	semaphore := make(chan struct{}, maxParallelism)
+
+	// Execute the queries concurrently
 	var wg sync.WaitGroup
-	wg.Add(len(queries))
+	for i, query := range queries {
+		// Check if the query is nil
+		if query == nil {
+			errs[i] = ErrNilQueryFn
+			continue
+		}
 
-	// Create a channel to handle errors
-	errCh := make(chan error)
+		// Wait for a free slot, or stop if the context is done
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
 
-	// Execute the queries concurrently
-	for _, query := range queries {
-		go func(query func(db *sql.DB, ctx context.Context) error) {
+		// Check if the context was done while the slot was freed, since both cases can be ready at once
+		if err := ctx.Err(); err != nil {
+			<-semaphore
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, query QueryWithResultFn[T]) {
 			defer wg.Done()
-			if err := query(db, ctx); err != nil {
-				// Send the error to the channel
-				errCh <- err
+			defer func() { <-semaphore }()
+
+			result, err := query(ctx, db)
+			if err != nil {
+				errs[i] = err
 
-				// cancel the other queries
-				cancel()
+				// Cancel the other queries
+				if cancelOnError {
+					cancel()
+				}
+				return
 			}
-		}(query)
+			results[i] = result
+		}(i, query)
 	}
 
 	// Wait for all queries to complete
 	wg.Wait()
 
-	// Disconnect the error channel
-	close(errCh)
-
-	// Return the errors if any
-	var errors []error
-	for err := range errCh {
-		errors = append(errors, err)
+	// Check if there are any errors
+	for _, err := range errs {
+		if err != nil {
+			return results, errs
+		}
 	}
+	return results, nil
+}
 
-	// Check if there are any errors
-	if len(errors) == 0 {
-		return nil
+// JoinQueriesErrors joins the errors indexed by query position into a single error
+//
+// Parameters:
+//
+//	errs: The errors indexed by query position
+//
+// Returns:
+//
+//	error: The joined error annotated with the query positions, or nil if no errors occurred
+func JoinQueriesErrors(errs []error) error {
+	var joinedErrs []error
+	for i, err := range errs {
+		if err != nil {
+			joinedErrs = append(joinedErrs, fmt.Errorf(ErrQueryFailed, i, err))
+		}
 	}
+	return errors.Join(joinedErrs...)
+}
 
-	return errors
+// compactErrors removes the nil errors from the given slice
+//
+// Parameters:
+//
+//	errs: The errors to compact
+//
+// Returns:
+//
+//	[]error: A slice of errors, or nil if no errors occurred
+func compactErrors(errs []error) []error {
+	var compacted []error
+	for _, err := range errs {
+		if err != nil {
+			compacted = append(compacted, err)
+		}
+	}
+	return compacted
 }
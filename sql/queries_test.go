@@ -0,0 +1,344 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+// execQuery is a query that runs a statement through the database
+func execQuery(statement string, err error) QueryFn {
+	return func(ctx context.Context, db *sql.DB) error {
+		if _, execErr := db.ExecContext(ctx, statement); execErr != nil {
+			return execErr
+		}
+		return err
+	}
+}
+
+// runWithTimeout runs the function, failing the test if it does not return in time
+func runWithTimeout(t *testing.T, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the queries did not complete")
+	}
+}
+
+func TestRunQueriesConcurrentlyWithCtx(t *testing.T) {
+	errQuery := errors.New("query failed")
+
+	tests := []struct {
+		name     string
+		nilDB    bool
+		queries  []QueryFn
+		wantErrs []error
+	}{
+		{
+			name:    "no errors",
+			queries: []QueryFn{execQuery("SELECT 1", nil), execQuery("SELECT 2", nil)},
+		},
+		{
+			name: "errors at their positions",
+			queries: []QueryFn{
+				execQuery("SELECT 1", nil),
+				execQuery("SELECT 2", errQuery),
+				execQuery("SELECT 3", nil),
+				execQuery("SELECT 4", errQuery),
+			},
+			wantErrs: []error{nil, errQuery, nil, errQuery},
+		},
+		{
+			name:     "nil query",
+			queries:  []QueryFn{execQuery("SELECT 1", nil), nil},
+			wantErrs: []error{nil, ErrNilQueryFn},
+		},
+		{
+			name:     "nil database",
+			nilDB:    true,
+			queries:  []QueryFn{execQuery("SELECT 1", nil)},
+			wantErrs: []error{godatabases.ErrNilConnection},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, _ := sqltest.Open(nil)
+				defer db.Close()
+				if tt.nilDB {
+					db = nil
+				}
+
+				var errs []error
+				runWithTimeout(
+					t, func() {
+						errs = RunQueriesConcurrentlyWithCtx(context.Background(), db, 2, false, tt.queries...)
+					},
+				)
+				if tt.wantErrs == nil {
+					if errs != nil {
+						t.Fatalf("RunQueriesConcurrentlyWithCtx() = %v, want nil", errs)
+					}
+					return
+				}
+				if len(errs) != len(tt.wantErrs) {
+					t.Fatalf("RunQueriesConcurrentlyWithCtx() = %d errors, want %d", len(errs), len(tt.wantErrs))
+				}
+				for i, err := range errs {
+					if !errors.Is(err, tt.wantErrs[i]) || (err == nil) != (tt.wantErrs[i] == nil) {
+						t.Errorf("error at index %d = %v, want %v", i, err, tt.wantErrs[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestRunQueriesConcurrentlyWithCtxParallelism(t *testing.T) {
+	tests := []struct {
+		name               string
+		maxParallelism     int
+		maxOpenConnections int
+		wantMax            int64
+	}{
+		{name: "single query at a time", maxParallelism: 1, wantMax: 1},
+		{name: "bounded parallelism", maxParallelism: 3, wantMax: 3},
+		{name: "pool size by default", maxOpenConnections: 2, wantMax: 2},
+		{name: "every query by default", wantMax: 8},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, _ := sqltest.Open(nil)
+				defer db.Close()
+				db.SetMaxOpenConns(tt.maxOpenConnections)
+
+				// Track the number of queries running at the same time
+				var running, maxRunning atomic.Int64
+				queries := make([]QueryFn, 8)
+				for i := range queries {
+					queries[i] = func(ctx context.Context, db *sql.DB) error {
+						current := running.Add(1)
+						defer running.Add(-1)
+						for {
+							peak := maxRunning.Load()
+							if current <= peak || maxRunning.CompareAndSwap(peak, current) {
+								break
+							}
+						}
+
+						// Keep the query running, so the other allowed queries start meanwhile
+						time.Sleep(5 * time.Millisecond)
+						_, err := db.ExecContext(ctx, "SELECT 1")
+						return err
+					}
+				}
+
+				var errs []error
+				runWithTimeout(
+					t, func() {
+						errs = RunQueriesConcurrentlyWithCtx(
+							context.Background(),
+							db,
+							tt.maxParallelism,
+							false,
+							queries...,
+						)
+					},
+				)
+				if errs != nil {
+					t.Fatalf("RunQueriesConcurrentlyWithCtx() = %v, want nil", errs)
+				}
+				if got := maxRunning.Load(); got > tt.wantMax {
+					t.Errorf("running queries = %d, want at most %d", got, tt.wantMax)
+				}
+			},
+		)
+	}
+}
+
+func TestRunQueriesConcurrentlyWithCtxCancel(t *testing.T) {
+	errQuery := errors.New("query failed")
+
+	tests := []struct {
+		name          string
+		cancelParent  bool
+		cancelOnError bool
+		wantRan       []bool
+		wantErrs      []error
+	}{
+		{
+			name:          "cancels the pending queries once a query fails",
+			cancelOnError: true,
+			wantRan:       []bool{true, false, false},
+			wantErrs:      []error{errQuery, context.Canceled, context.Canceled},
+		},
+		{
+			name:     "runs every query",
+			wantRan:  []bool{true, true, true},
+			wantErrs: []error{errQuery, nil, nil},
+		},
+		{
+			name:         "parent context done",
+			cancelParent: true,
+			wantRan:      []bool{false, false, false},
+			wantErrs:     []error{context.Canceled, context.Canceled, context.Canceled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, _ := sqltest.Open(nil)
+				defer db.Close()
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				if tt.cancelParent {
+					cancel()
+				}
+
+				// Run the queries one at a time, so the pending ones start after the first one fails
+				ran := make([]bool, len(tt.wantRan))
+				queries := make([]QueryFn, len(tt.wantRan))
+				for i := range queries {
+					queries[i] = func(context.Context, *sql.DB) error {
+						ran[i] = true
+						if i == 0 {
+							return errQuery
+						}
+						return nil
+					}
+				}
+
+				var errs []error
+				runWithTimeout(
+					t, func() {
+						errs = RunQueriesConcurrentlyWithCtx(ctx, db, 1, tt.cancelOnError, queries...)
+					},
+				)
+				if !slices.Equal(ran, tt.wantRan) {
+					t.Errorf("ran queries = %v, want %v", ran, tt.wantRan)
+				}
+				for i, err := range errs {
+					if !errors.Is(err, tt.wantErrs[i]) || (err == nil) != (tt.wantErrs[i] == nil) {
+						t.Errorf("error at index %d = %v, want %v", i, err, tt.wantErrs[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestRunQueriesConcurrentlyWithResults(t *testing.T) {
+	errQuery := errors.New("query failed")
+	db, _ := sqltest.Open(nil)
+	defer db.Close()
+
+	// Return the position of each query as its result, failing the odd ones
+	queries := make([]QueryWithResultFn[int], 5)
+	for i := range queries {
+		queries[i] = func(ctx context.Context, db *sql.DB) (int, error) {
+			if _, err := db.ExecContext(ctx, "SELECT 1"); err != nil {
+				return 0, err
+			}
+			if i%2 == 1 {
+				return 0, errQuery
+			}
+			return i, nil
+		}
+	}
+
+	var results []int
+	var errs []error
+	runWithTimeout(
+		t, func() {
+			results, errs = RunQueriesConcurrentlyWithResults(context.Background(), db, 2, false, queries...)
+		},
+	)
+	if want := []int{0, 0, 2, 0, 4}; !slices.Equal(results, want) {
+		t.Errorf("results = %v, want %v", results, want)
+	}
+	for i, err := range errs {
+		if wantErr := i%2 == 1; (err != nil) != wantErr {
+			t.Errorf("error at index %d = %v, want error %v", i, err, wantErr)
+		}
+	}
+
+	// Check that the joined error is annotated with the query positions
+	joinedErr := JoinQueriesErrors(errs)
+	if !errors.Is(joinedErr, errQuery) {
+		t.Fatalf("JoinQueriesErrors() = %v, want %v", joinedErr, errQuery)
+	}
+	for _, position := range []string{"index 1", "index 3"} {
+		if !strings.Contains(joinedErr.Error(), position) {
+			t.Errorf("JoinQueriesErrors() = %v, want the query at %s", joinedErr, position)
+		}
+	}
+}
+
+func TestRunQueriesConcurrentlyFailing(t *testing.T) {
+	errQuery := errors.New("query failed")
+	db, _ := sqltest.Open(nil)
+	defer db.Close()
+
+	tests := []struct {
+		name  string
+		runFn func() []error
+	}{
+		{
+			name: "without cancel",
+			runFn: func() []error {
+				return RunQueriesConcurrently(
+					db,
+					func(*sql.DB) error { return errQuery },
+					func(*sql.DB) error { return nil },
+					func(*sql.DB) error { return errQuery },
+				)
+			},
+		},
+		{
+			name: "with cancel",
+			runFn: func() []error {
+				return RunQueriesConcurrentlyWithCancel(
+					db,
+					func(*sql.DB, context.Context) error { return errQuery },
+					func(*sql.DB, context.Context) error { return errQuery },
+				)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Check that the failing queries no longer deadlock the caller
+				var errs []error
+				runWithTimeout(
+					t, func() {
+						errs = tt.runFn()
+					},
+				)
+				if len(errs) == 0 {
+					t.Fatalf("errors = %v, want the failed queries", errs)
+				}
+				for _, err := range errs {
+					if !errors.Is(err, errQuery) && !errors.Is(err, context.Canceled) {
+						t.Errorf("error = %v, want %v", err, errQuery)
+					}
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,465 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+)
+
+const (
+	// RoundRobin routes each read to the next replica
+	RoundRobin LoadBalancingStrategy = iota
+
+	// LeastConnections routes each read to the replica with the fewest connections in use
+	LeastConnections
+)
+
+type (
+	// LoadBalancingStrategy represents the strategy used to pick the replica to read from
+	LoadBalancingStrategy int
+
+	// ReplicaStateChangeFn is the function type called when the health state of a replica changes
+	ReplicaStateChangeFn func(index int, previous, current HealthStatus)
+
+	// replicaConnection represents a read replica, its health checker and its last connection error
+	//
+	// The health checker is stored atomically since it is read on each read routed to the replicas while it
+	// can be replaced by StartHealthChecks and StopHealthChecks.
+	replicaConnection struct {
+		handler       *DefaultHandler
+		healthChecker atomic.Pointer[HealthChecker]
+		connectErr    error
+	}
+
+	// ReplicatedHandler is the handler for a primary database and its read replicas
+	ReplicatedHandler struct {
+		primary  *DefaultHandler
+		replicas []*replicaConnection
+		strategy LoadBalancingStrategy
+		counter  atomic.Uint64
+		mutex    sync.Mutex
+	}
+
+	// ReplicatedService is the service for a primary database and its read replicas
+	//
+	// The reads done through QueryRowWithCtx and QueryWithCtx are routed to the replicas, while the writes
+	// and the transactions are routed to the primary.
+	ReplicatedService struct {
+		*ReplicatedHandler
+		*DefaultService
+	}
+)
+
+// NewReplicatedHandler creates a new replicated handler
+//
+// Parameters:
+//
+//   - primaryConfig: the configuration for the primary connection
+//   - replicaConfigs: the configurations for the replica connections
+//   - strategy: the strategy used to pick the replica to read from
+//
+// Returns:
+//
+//   - *ReplicatedHandler: the replicated handler
+//   - error: if any error occurs
+func NewReplicatedHandler(
+	primaryConfig *Config,
+	replicaConfigs []*Config,
+	strategy LoadBalancingStrategy,
+) (*ReplicatedHandler, error) {
+	// Create the primary handler
+	primary, err := NewDefaultHandler(primaryConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the replica handlers
+	replicas := make([]*replicaConnection, 0, len(replicaConfigs))
+	for _, replicaConfig := range replicaConfigs {
+		handler, handlerErr := NewDefaultHandler(replicaConfig)
+		if handlerErr != nil {
+			return nil, handlerErr
+		}
+		replicas = append(replicas, &replicaConnection{handler: handler})
+	}
+
+	return &ReplicatedHandler{
+		primary:  primary,
+		replicas: replicas,
+		strategy: strategy,
+	}, nil
+}
+
+// Connect connects to the primary and to the replicas
+//
+// A replica that fails to connect is skipped by the reads until a later call to Connect succeeds, and its
+// error is reported by ReplicaErrors. Only a failure to connect to the primary is returned.
+//
+// Returns:
+//
+//   - *sql.DB: the primary SQL connection
+//   - error: if the primary connection failed
+func (r *ReplicatedHandler) Connect() (*sql.DB, error) {
+	if r == nil {
+		return nil, godatabases.ErrNilHandler
+	}
+
+	// Connect to the primary
+	db, err := r.primary.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock the mutex to ensure thread safety
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Connect to the replicas, recording the ones that failed
+	for _, replica := range r.replicas {
+		_, replica.connectErr = replica.handler.Connect()
+	}
+	return db, nil
+}
+
+// ReplicaErrors returns the errors of the replicas that failed to connect on the last call to Connect
+//
+// Returns:
+//
+//   - map[int]error: the connection errors by replica index, empty if every replica is connected
+func (r *ReplicatedHandler) ReplicaErrors() map[int]error {
+	if r == nil {
+		return nil
+	}
+
+	// Lock the mutex to ensure thread safety
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	errs := make(map[int]error)
+	for index, replica := range r.replicas {
+		if replica.connectErr != nil {
+			errs[index] = replica.connectErr
+		}
+	}
+	return errs
+}
+
+// Stats returns the statistics of the primary SQL connection pool
+//
+// Returns:
+//
+//   - sql.DBStats: the connection pool statistics
+//   - error: if any error occurred
+func (r *ReplicatedHandler) Stats() (sql.DBStats, error) {
+	if r == nil {
+		return sql.DBStats{}, godatabases.ErrNilHandler
+	}
+
+	// Get the database connection
+	db, err := r.primary.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return db.Stats(), nil
+}
+
+// ReplicaStats returns the statistics of the connected replica SQL connection pools
+//
+// Returns:
+//
+//   - map[int]sql.DBStats: the connection pool statistics by replica index, without the disconnected replicas
+func (r *ReplicatedHandler) ReplicaStats() map[int]sql.DBStats {
+	if r == nil {
+		return nil
+	}
+
+	stats := make(map[int]sql.DBStats, len(r.replicas))
+	for index, replica := range r.replicas {
+		replicaStats, err := replica.handler.Stats()
+		if err != nil {
+			continue
+		}
+		stats[index] = replicaStats
+	}
+	return stats
+}
+
+// IsConnected checks if the primary connection is established
+//
+// Returns:
+//
+//   - bool: true if the connection is established, false otherwise
+func (r *ReplicatedHandler) IsConnected() bool {
+	if r == nil {
+		return false
+	}
+	return r.primary.IsConnected()
+}
+
+// DB returns the primary SQL connection
+//
+// Returns:
+//
+//   - *sql.DB: the primary SQL connection
+//   - error: if any error occurred
+func (r *ReplicatedHandler) DB() (*sql.DB, error) {
+	if r == nil {
+		return nil, godatabases.ErrNilHandler
+	}
+	return r.primary.DB()
+}
+
+// Ping pings the primary SQL connection
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - time.Duration: the ping latency
+//   - error: if any error occurred
+func (r *ReplicatedHandler) Ping(ctx context.Context) (time.Duration, error) {
+	if r == nil {
+		return 0, godatabases.ErrNilHandler
+	}
+	return r.primary.Ping(ctx)
+}
+
+// ReadDB returns the SQL connection to read from
+//
+// The replicas are picked according to the load balancing strategy, skipping the ones that are not connected
+// or whose last health check failed. The primary is returned if the context forces the reads to the primary
+// or if there is no available replica.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//
+// Returns:
+//
+//   - *sql.DB: the SQL connection to read from
+//   - error: if any error occurred
+func (r *ReplicatedHandler) ReadDB(ctx context.Context) (*sql.DB, error) {
+	if r == nil {
+		return nil, godatabases.ErrNilHandler
+	}
+
+	// Check if the reads are forced to the primary
+	if IsPrimaryForced(ctx) || len(r.replicas) == 0 {
+		return r.primary.DB()
+	}
+
+	// Pick the replica
+	var db *sql.DB
+	switch r.strategy {
+	case LeastConnections:
+		db = r.leastConnectionsReplica()
+	default:
+		db = r.roundRobinReplica()
+	}
+	if db != nil {
+		return db, nil
+	}
+
+	// Fallback to the primary
+	return r.primary.DB()
+}
+
+// availableReplica returns the SQL connection of the replica if it is connected and healthy
+//
+// Parameters:
+//
+//   - replica: the replica to check
+//
+// Returns:
+//
+//   - *sql.DB: the replica SQL connection, or nil if it is not available
+func availableReplica(replica *replicaConnection) *sql.DB {
+	// Check if the last health check failed
+	if healthChecker := replica.healthChecker.Load(); healthChecker != nil &&
+		healthChecker.Status().State == HealthStateUnhealthy {
+		return nil
+	}
+
+	// Get the replica connection
+	db, err := replica.handler.DB()
+	if err != nil {
+		return nil
+	}
+	return db
+}
+
+// roundRobinReplica returns the next available replica SQL connection
+//
+// Returns:
+//
+//   - *sql.DB: the replica SQL connection, or nil if there is no available replica
+func (r *ReplicatedHandler) roundRobinReplica() *sql.DB {
+	start := r.counter.Add(1) - 1
+	for i := range uint64(len(r.replicas)) {
+		index := (start + i) % uint64(len(r.replicas))
+		if db := availableReplica(r.replicas[index]); db != nil {
+			return db
+		}
+	}
+	return nil
+}
+
+// leastConnectionsReplica returns the available replica SQL connection with the fewest connections in use
+//
+// Returns:
+//
+//   - *sql.DB: the replica SQL connection, or nil if there is no available replica
+func (r *ReplicatedHandler) leastConnectionsReplica() *sql.DB {
+	var picked *sql.DB
+	pickedInUse := 0
+	for _, replica := range r.replicas {
+		db := availableReplica(replica)
+		if db == nil {
+			continue
+		}
+		if inUse := db.Stats().InUse; picked == nil || inUse < pickedInUse {
+			picked = db
+			pickedInUse = inUse
+		}
+	}
+	return picked
+}
+
+// StartHealthChecks starts the background health checks of the replicas
+//
+// Parameters:
+//
+//   - ctx: the context, the health checks stop once it is done
+//   - interval: the interval between health checks
+//   - timeout: the timeout for each health check
+//   - onStateChange: the function called when the health state of a replica changes, it can be nil
+//
+// Returns:
+//
+//   - error: if any error occurred
+func (r *ReplicatedHandler) StartHealthChecks(
+	ctx context.Context,
+	interval,
+	timeout time.Duration,
+	onStateChange ReplicaStateChangeFn,
+) error {
+	if r == nil {
+		return godatabases.ErrNilHandler
+	}
+
+	// Lock the mutex to ensure thread safety
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for index, replica := range r.replicas {
+		// Check if the health checker is already started
+		if replica.healthChecker.Load() != nil {
+			return ErrHealthCheckerAlreadyStarted
+		}
+
+		// Create the replica state change function
+		var replicaOnStateChange HealthStateChangeFn
+		if onStateChange != nil {
+			replicaOnStateChange = func(previous, current HealthStatus) {
+				onStateChange(index, previous, current)
+			}
+		}
+
+		// Create and start the health checker
+		healthChecker, err := NewHealthChecker(
+			replica.handler,
+			interval,
+			timeout,
+			replicaOnStateChange,
+		)
+		if err != nil {
+			return err
+		}
+		if err = healthChecker.Start(ctx); err != nil {
+			return err
+		}
+		replica.healthChecker.Store(healthChecker)
+	}
+	return nil
+}
+
+// StopHealthChecks stops the background health checks of the replicas
+func (r *ReplicatedHandler) StopHealthChecks() {
+	if r == nil {
+		return
+	}
+
+	// Lock the mutex to ensure thread safety
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, replica := range r.replicas {
+		if healthChecker := replica.healthChecker.Swap(nil); healthChecker != nil {
+			healthChecker.Stop()
+		}
+	}
+}
+
+// Disconnect stops the health checks and closes the primary and the replica connections
+//
+// Returns:
+//
+//   - error: if any error occurred
+func (r *ReplicatedHandler) Disconnect() error {
+	if r == nil {
+		return godatabases.ErrNilHandler
+	}
+
+	// Stop the health checks
+	r.StopHealthChecks()
+
+	// Close the connections
+	errs := make([]error, 0, len(r.replicas)+1)
+	errs = append(errs, r.primary.Disconnect())
+	for _, replica := range r.replicas {
+		errs = append(errs, replica.handler.Disconnect())
+	}
+	return errors.Join(errs...)
+}
+
+// NewReplicatedService creates a new replicated service
+//
+// Parameters:
+//
+//   - primaryConfig: the configuration for the primary connection
+//   - replicaConfigs: the configurations for the replica connections
+//   - strategy: the strategy used to pick the replica to read from
+//
+// Returns:
+//
+//   - *ReplicatedService: the replicated service
+//   - error: if there was an error creating the service
+func NewReplicatedService(
+	primaryConfig *Config,
+	replicaConfigs []*Config,
+	strategy LoadBalancingStrategy,
+) (*ReplicatedService, error) {
+	// Create the handler
+	handler, err := NewReplicatedHandler(
+		primaryConfig,
+		replicaConfigs,
+		strategy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplicatedService{
+		ReplicatedHandler: handler,
+		DefaultService: &DefaultService{
+			Handler: handler,
+		},
+	}, nil
+}
@@ -0,0 +1,131 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+const (
+	// testDriverName is the name of the fake driver registered for the tests
+	testDriverName = "sqltest"
+)
+
+func init() {
+	sql.Register(testDriverName, sqltest.NewConnector(nil).Driver())
+}
+
+// newConnectedHandler creates a handler over an already opened fake database
+func newConnectedHandler(t *testing.T) *DefaultHandler {
+	t.Helper()
+	db, _ := sqltest.Open(nil)
+	t.Cleanup(func() { _ = db.Close() })
+	return &DefaultHandler{config: &Config{}, db: db}
+}
+
+func TestReplicatedHandlerConnectSkipsFailedReplicas(t *testing.T) {
+	handler, err := NewReplicatedHandler(
+		&Config{DriverName: testDriverName},
+		[]*Config{
+			{DriverName: testDriverName},
+			{DriverName: "unknown"},
+		},
+		RoundRobin,
+	)
+	if err != nil {
+		t.Fatalf("NewReplicatedHandler() error = %v", err)
+	}
+	defer handler.Disconnect()
+
+	// Connect, skipping the replica with the unknown driver
+	primary, err := handler.Connect()
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	errs := handler.ReplicaErrors()
+	if len(errs) != 1 || errs[1] == nil {
+		t.Fatalf("ReplicaErrors() = %v, want an error for the replica 1", errs)
+	}
+
+	// Check that the reads are only routed to the connected replica
+	replica, err := handler.replicas[0].handler.DB()
+	if err != nil {
+		t.Fatalf("DB() error = %v", err)
+	}
+	for range 4 {
+		db, readErr := handler.ReadDB(context.Background())
+		if readErr != nil {
+			t.Fatalf("ReadDB() error = %v", readErr)
+		}
+		if db != replica {
+			t.Fatalf("ReadDB() did not return the connected replica")
+		}
+	}
+
+	// Check that the primary is used when the reads are forced to it
+	db, err := handler.ReadDB(WithPrimary(context.Background()))
+	if err != nil || db != primary {
+		t.Errorf("ReadDB() with the primary forced = %v, %v, want the primary", db, err)
+	}
+}
+
+func TestReplicatedHandlerConnectFailsOnPrimary(t *testing.T) {
+	handler, err := NewReplicatedHandler(
+		&Config{DriverName: "unknown"},
+		[]*Config{{DriverName: testDriverName}},
+		RoundRobin,
+	)
+	if err != nil {
+		t.Fatalf("NewReplicatedHandler() error = %v", err)
+	}
+	if _, err = handler.Connect(); err == nil {
+		t.Errorf("Connect() error = nil, want error")
+	}
+}
+
+func TestReplicatedHandlerHealthChecksRace(t *testing.T) {
+	handler := &ReplicatedHandler{
+		primary: newConnectedHandler(t),
+		replicas: []*replicaConnection{
+			{handler: newConnectedHandler(t)},
+			{handler: newConnectedHandler(t)},
+		},
+		strategy: LeastConnections,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Read while the health checks are started and stopped
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if _, err := handler.ReadDB(ctx); err != nil {
+					t.Errorf("ReadDB() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	for range 20 {
+		if err := handler.StartHealthChecks(
+			ctx,
+			time.Millisecond,
+			time.Second,
+			nil,
+		); err != nil {
+			t.Fatalf("StartHealthChecks() error = %v", err)
+		}
+		handler.StopHealthChecks()
+	}
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,236 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	gopgx "github.com/ralvarezdev/go-databases/sql/pgx"
+)
+
+const (
+	// DefaultMaxAttempts is the default maximum number of attempts
+	DefaultMaxAttempts = 5
+
+	// DefaultInitialBackoff is the default backoff before the first retry
+	DefaultInitialBackoff = 50 * time.Millisecond
+
+	// DefaultMaxBackoff is the default maximum backoff between retries
+	DefaultMaxBackoff = 2 * time.Second
+
+	// DefaultBackoffMultiplier is the default backoff multiplier
+	DefaultBackoffMultiplier = 2.0
+)
+
+type (
+	// IsRetryableFn is the function type used to check if an error is retryable
+	IsRetryableFn func(err error) bool
+
+	// RetryFn is the function type retried by the retry policy
+	RetryFn func(ctx context.Context) error
+
+	// RetryPolicy struct
+	RetryPolicy struct {
+		MaxAttempts    int
+		InitialBackoff time.Duration
+		MaxBackoff     time.Duration
+		Multiplier     float64
+		IsRetryable    IsRetryableFn
+	}
+)
+
+// NewRetryPolicy creates a new retry policy
+//
+// Parameters:
+//
+//   - maxAttempts: the maximum number of attempts, including the first one
+//   - initialBackoff: the backoff before the first retry
+//   - maxBackoff: the maximum backoff between retries
+//   - multiplier: the multiplier applied to the backoff after each retry
+//   - isRetryable: the function used to check if an error is retryable, defaults to the pgx retryable codes
+//
+// Returns:
+//
+//   - *RetryPolicy: the retry policy
+//   - error: if any error occurs
+func NewRetryPolicy(
+	maxAttempts int,
+	initialBackoff,
+	maxBackoff time.Duration,
+	multiplier float64,
+	isRetryable IsRetryableFn,
+) (*RetryPolicy, error) {
+	// Check the retry policy values
+	if maxAttempts < 1 {
+		return nil, ErrInvalidMaxAttempts
+	}
+	if initialBackoff < 0 || maxBackoff < 0 {
+		return nil, ErrInvalidBackoff
+	}
+	if multiplier < 1 {
+		return nil, ErrInvalidBackoffMultiplier
+	}
+
+	// Set the default retryable classifier
+	if isRetryable == nil {
+		isRetryable = gopgx.IsRetryableError
+	}
+
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+		Multiplier:     multiplier,
+		IsRetryable:    isRetryable,
+	}, nil
+}
+
+// NewDefaultRetryPolicy creates a new retry policy with the default values
+//
+// Returns:
+//
+//   - *RetryPolicy: the retry policy
+func NewDefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    DefaultMaxAttempts,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		Multiplier:     DefaultBackoffMultiplier,
+		IsRetryable:    gopgx.IsRetryableError,
+	}
+}
+
+// isAnyError checks if the error is not nil, used to retry every error
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is not nil, false otherwise
+func isAnyError(err error) bool {
+	return err != nil
+}
+
+// Backoff returns the backoff to wait before the given retry, with jitter applied
+//
+// A multiplier lower than one is treated as one, so a policy built by hand without it waits the initial
+// backoff before every retry instead of not waiting at all.
+//
+// Parameters:
+//
+//   - retry: the retry number, starting at 1
+//
+// Returns:
+//
+//   - time.Duration: the backoff to wait
+func (r *RetryPolicy) Backoff(retry int) time.Duration {
+	if r == nil || r.InitialBackoff <= 0 {
+		return 0
+	}
+
+	// Get the multiplier
+	multiplier := r.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	// Compute the exponential backoff
+	backoff := float64(r.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		backoff *= multiplier
+		if r.MaxBackoff > 0 && backoff >= float64(r.MaxBackoff) {
+			backoff = float64(r.MaxBackoff)
+			break
+		}
+	}
+	if r.MaxBackoff > 0 && backoff > float64(r.MaxBackoff) {
+		backoff = float64(r.MaxBackoff)
+	}
+
+	// Apply the jitter, waiting between half and the whole backoff
+	half := int64(backoff / 2)
+	if half <= 0 {
+		return time.Duration(backoff)
+	}
+	//nolint:gosec // The jitter does not need a cryptographically secure random number
+	return time.Duration(half + rand.Int64N(half+1))
+}
+
+// isRetryable checks if the error is retryable according to the policy classifier
+//
+// Parameters:
+//
+//   - err: the error to check
+//
+// Returns:
+//
+//   - bool: true if the error is retryable, false otherwise
+func (r *RetryPolicy) isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if r == nil || r.IsRetryable == nil {
+		return gopgx.IsRetryableError(err)
+	}
+	return r.IsRetryable(err)
+}
+
+// ShouldRetry checks if the function must be run again after the given failed attempt
+//
+// Parameters:
+//
+//   - attempt: the attempt number, starting at 1
+//   - err: the error returned by the attempt
+//
+// Returns:
+//
+//   - bool: true if the error is retryable and the attempts are not exhausted, false otherwise
+func (r *RetryPolicy) ShouldRetry(attempt int, err error) bool {
+	maxAttempts := 1
+	if r != nil {
+		maxAttempts = max(r.MaxAttempts, 1)
+	}
+	return attempt < maxAttempts && r.isRetryable(err)
+}
+
+// Run runs the function, retrying it while it returns a retryable error and the attempts are not exhausted
+//
+// Parameters:
+//
+//   - ctx: the context, the retries stop once it is done
+//   - fn: the function to run
+//
+// Returns:
+//
+//   - error: the last error returned by the function, or nil if it succeeded
+func (r *RetryPolicy) Run(ctx context.Context, fn RetryFn) error {
+	// Check if the function is nil
+	if fn == nil {
+		return ErrNilRetryFn
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Run the function
+		err := fn(ctx)
+		if !r.ShouldRetry(attempt, err) {
+			// Check if the attempts were exhausted
+			if attempt > 1 && r.isRetryable(err) {
+				return fmt.Errorf(ErrRetryAttemptsExhausted, attempt, err)
+			}
+			return err
+		}
+
+		// Wait before the next attempt
+		timer := time.NewTimer(r.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
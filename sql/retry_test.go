@@ -0,0 +1,262 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	gopgx "github.com/ralvarezdev/go-databases/sql/pgx"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxAttempts    int
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		multiplier     float64
+		wantErr        error
+	}{
+		{
+			name:           "valid policy",
+			maxAttempts:    3,
+			initialBackoff: time.Millisecond,
+			maxBackoff:     time.Second,
+			multiplier:     2,
+		},
+		{
+			name:        "zero attempts",
+			maxAttempts: 0,
+			multiplier:  2,
+			wantErr:     ErrInvalidMaxAttempts,
+		},
+		{
+			name:           "negative backoff",
+			maxAttempts:    1,
+			initialBackoff: -time.Millisecond,
+			multiplier:     2,
+			wantErr:        ErrInvalidBackoff,
+		},
+		{
+			name:        "multiplier lower than one",
+			maxAttempts: 1,
+			multiplier:  0.5,
+			wantErr:     ErrInvalidBackoffMultiplier,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				policy, err := NewRetryPolicy(
+					tt.maxAttempts,
+					tt.initialBackoff,
+					tt.maxBackoff,
+					tt.multiplier,
+					nil,
+				)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewRetryPolicy() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && policy.IsRetryable == nil {
+					t.Errorf("NewRetryPolicy() did not set the default classifier")
+				}
+			},
+		)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		retry  int
+		min    time.Duration
+		max    time.Duration
+	}{
+		{
+			name:   "nil policy",
+			policy: nil,
+			retry:  1,
+		},
+		{
+			name:   "zero initial backoff",
+			policy: &RetryPolicy{Multiplier: 2},
+			retry:  3,
+		},
+		{
+			name: "first retry",
+			policy: &RetryPolicy{
+				InitialBackoff: 100 * time.Millisecond,
+				Multiplier:     2,
+			},
+			retry: 1,
+			min:   50 * time.Millisecond,
+			max:   100 * time.Millisecond,
+		},
+		{
+			name: "exponential growth",
+			policy: &RetryPolicy{
+				InitialBackoff: 100 * time.Millisecond,
+				Multiplier:     2,
+			},
+			retry: 3,
+			min:   200 * time.Millisecond,
+			max:   400 * time.Millisecond,
+		},
+		{
+			name: "capped at the max backoff",
+			policy: &RetryPolicy{
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     150 * time.Millisecond,
+				Multiplier:     2,
+			},
+			retry: 10,
+			min:   75 * time.Millisecond,
+			max:   150 * time.Millisecond,
+		},
+		{
+			name: "zero multiplier keeps the initial backoff",
+			policy: &RetryPolicy{
+				InitialBackoff: 100 * time.Millisecond,
+			},
+			retry: 4,
+			min:   50 * time.Millisecond,
+			max:   100 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				for range 20 {
+					got := tt.policy.Backoff(tt.retry)
+					if got < tt.min || got > tt.max {
+						t.Fatalf(
+							"Backoff(%d) = %v, want between %v and %v",
+							tt.retry,
+							got,
+							tt.min,
+							tt.max,
+						)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	retryable := &pgconn.PgError{Code: gopgx.SerializationFailureCode}
+	permanent := errors.New("permanent")
+	policy := &RetryPolicy{MaxAttempts: 3}
+
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		attempt int
+		err     error
+		want    bool
+	}{
+		{name: "retryable error", policy: policy, attempt: 1, err: retryable, want: true},
+		{name: "attempts exhausted", policy: policy, attempt: 3, err: retryable},
+		{name: "permanent error", policy: policy, attempt: 1, err: permanent},
+		{name: "nil error", policy: policy, attempt: 1},
+		{name: "nil policy", attempt: 1, err: retryable},
+		{
+			name: "custom classifier",
+			policy: &RetryPolicy{
+				MaxAttempts: 2,
+				IsRetryable: isAnyError,
+			},
+			attempt: 1,
+			err:     permanent,
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := tt.policy.ShouldRetry(tt.attempt, tt.err); got != tt.want {
+					t.Errorf("ShouldRetry() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestRetryPolicyRun(t *testing.T) {
+	retryable := &pgconn.PgError{Code: gopgx.DeadlockDetectedCode}
+	permanent := errors.New("permanent")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "succeeds at once", errs: []error{nil}, wantCalls: 1},
+		{
+			name:      "succeeds after retries",
+			errs:      []error{retryable, retryable, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "stops on a permanent error",
+			errs:      []error{retryable, permanent},
+			wantCalls: 2,
+			wantErr:   permanent,
+		},
+		{
+			name:      "exhausts the attempts",
+			errs:      []error{retryable, retryable, retryable},
+			wantCalls: 3,
+			wantErr:   retryable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				policy := &RetryPolicy{
+					MaxAttempts:    3,
+					InitialBackoff: time.Microsecond,
+					Multiplier:     1,
+				}
+				calls := 0
+				err := policy.Run(
+					context.Background(), func(context.Context) error {
+						err := tt.errs[calls]
+						calls++
+						return err
+					},
+				)
+				if calls != tt.wantCalls {
+					t.Errorf("Run() called the function %d times, want %d", calls, tt.wantCalls)
+				}
+				if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+					t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestRetryPolicyRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+
+	retryable := &pgconn.PgError{Code: gopgx.SerializationFailureCode}
+	err := policy.Run(
+		ctx, func(context.Context) error {
+			cancel()
+			return retryable
+		},
+	)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, retryable) {
+		t.Errorf("Run() error = %v, want the attempt and context errors", err)
+	}
+
+	if err = policy.Run(ctx, nil); !errors.Is(err, ErrNilRetryFn) {
+		t.Errorf("Run() error = %v, want %v", err, ErrNilRetryFn)
+	}
+}
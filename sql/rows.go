@@ -0,0 +1,65 @@
+package sql
+
+import (
+	"database/sql"
+	"iter"
+)
+
+type (
+	// OpenRowsFn is the function type used to open the rows lazily
+	OpenRowsFn func() (*sql.Rows, error)
+)
+
+// IterateRows returns an iterator over the rows opened by the given function
+//
+// The rows are only opened when the iterator is ranged over, and they are
+// always closed once the iteration finishes, even if it is stopped early.
+// Any error returned while opening, iterating or closing the rows is yielded
+// as the last element with a nil row.
+//
+// Parameters:
+//
+//   - openFn: the function that opens the rows
+//
+// Returns:
+//
+//   - iter.Seq2[*sql.Rows, error]: the rows iterator
+func IterateRows(openFn OpenRowsFn) iter.Seq2[*sql.Rows, error] {
+	return func(yield func(*sql.Rows, error) bool) {
+		// Check if the open function is nil
+		if openFn == nil {
+			yield(nil, ErrNilOpenRowsFn)
+			return
+		}
+
+		// Open the rows
+		rows, err := openFn()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// Close the rows even if the consumer panics, closing them twice is a no-op
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		// Iterate over the rows
+		for rows.Next() {
+			if !yield(rows, nil) {
+				return
+			}
+		}
+
+		// Check if there was an error during the iteration
+		if err = rows.Err(); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// Close the rows to report the close error
+		if err = rows.Close(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
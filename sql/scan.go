@@ -0,0 +1,147 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/internal/structs"
+)
+
+// ScanStruct scans the current row into a new struct, mapping the columns to the fields through their 'db' tag
+//
+// Untagged embedded structs are flattened, so their tagged fields are mapped as if they were declared in the
+// parent struct. Nullable columns can be scanned into sql.Null* or pointer fields.
+//
+// Parameters:
+//
+//   - rows: the rows positioned at the row to scan
+//
+// Returns:
+//
+//   - *T: the scanned struct
+//   - error: if any error occurs, or if a column is not mapped to any field
+func ScanStruct[T any](rows *sql.Rows) (*T, error) {
+	// Check if the rows are nil
+	if rows == nil {
+		return nil, ErrNilRows
+	}
+
+	// Get the struct mapping
+	instance := new(T)
+	value := reflect.ValueOf(instance).Elem()
+	mapping, err := structs.GetMapping(value.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the columns
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the destinations for each column
+	destinations := make([]any, len(columns))
+	for i, column := range columns {
+		field, ok := mapping.FieldByColumn(value, column)
+		if !ok {
+			return nil, fmt.Errorf(ErrUnmappedColumn, column, value.Type())
+		}
+		destinations[i] = field.Addr().Interface()
+	}
+
+	// Scan the row
+	if err = rows.Scan(destinations...); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// QueryOne runs a query with parameters and scans the first result row into a new struct
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - service: the database service
+//   - query: the query to execute
+//   - params: the parameters for the query
+//
+// Returns:
+//
+//   - *T: the scanned struct
+//   - error: if any error occurs, or sql.ErrNoRows if the query returned no rows
+func QueryOne[T any](
+	ctx context.Context,
+	service Service,
+	query *string,
+	params ...any,
+) (*T, error) {
+	// Check if the service is nil
+	if service == nil {
+		return nil, godatabases.ErrNilService
+	}
+
+	// Run the query
+	rows, err := service.QueryWithCtx(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scan the first row
+	for row, rowErr := range rows {
+		if rowErr != nil {
+			return nil, rowErr
+		}
+		return ScanStruct[T](row)
+	}
+	return nil, sql.ErrNoRows
+}
+
+// QueryAll runs a query with parameters and scans every result row into a new struct
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - service: the database service
+//   - query: the query to execute
+//   - params: the parameters for the query
+//
+// Returns:
+//
+//   - []*T: the scanned structs
+//   - error: if any error occurs
+func QueryAll[T any](
+	ctx context.Context,
+	service Service,
+	query *string,
+	params ...any,
+) ([]*T, error) {
+	// Check if the service is nil
+	if service == nil {
+		return nil, godatabases.ErrNilService
+	}
+
+	// Run the query
+	rows, err := service.QueryWithCtx(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scan each row
+	var instances []*T
+	for row, rowErr := range rows {
+		if rowErr != nil {
+			return nil, rowErr
+		}
+
+		instance, scanErr := ScanStruct[T](row)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
@@ -0,0 +1,208 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+type (
+	// testHandler is a Handler over an already opened database
+	testHandler struct {
+		db *sql.DB
+	}
+
+	scannedUser struct {
+		ID    int64          `db:"id"`
+		Name  string         `db:"name"`
+		Email sql.NullString `db:"email"`
+	}
+)
+
+func (h *testHandler) Connect() (*sql.DB, error) { return h.db, nil }
+
+func (h *testHandler) IsConnected() bool { return h.db != nil }
+
+func (h *testHandler) DB() (*sql.DB, error) { return h.db, nil }
+
+func (h *testHandler) Disconnect() error { return h.db.Close() }
+
+// newTestService creates a service over a fake database answered by the given function
+func newTestService(
+	t *testing.T,
+	handlerFn sqltest.HandlerFn,
+) (*DefaultService, *sqltest.Connector) {
+	t.Helper()
+	db, connector := sqltest.Open(handlerFn)
+	t.Cleanup(func() { _ = db.Close() })
+	return &DefaultService{Handler: &testHandler{db: db}}, connector
+}
+
+// rowsResult returns a handler function answering every statement with the given result
+func rowsResult(res *sqltest.Result) sqltest.HandlerFn {
+	return func(context.Context, string, []driver.NamedValue) (
+		*sqltest.Result,
+		error,
+	) {
+		return res, nil
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	errIteration := errors.New("iteration failed")
+	query := "SELECT id, name, email FROM users"
+
+	tests := []struct {
+		name    string
+		result  *sqltest.Result
+		want    []scannedUser
+		wantErr bool
+		errIs   error
+	}{
+		{
+			name: "scans every row",
+			result: &sqltest.Result{
+				Columns: []string{"id", "name", "email"},
+				Rows: [][]driver.Value{
+					{int64(1), "alice", "alice@example.com"},
+					{int64(2), "bob", nil},
+				},
+			},
+			want: []scannedUser{
+				{
+					ID:    1,
+					Name:  "alice",
+					Email: sql.NullString{String: "alice@example.com", Valid: true},
+				},
+				{ID: 2, Name: "bob"},
+			},
+		},
+		{
+			name:   "returns no rows",
+			result: &sqltest.Result{Columns: []string{"id"}},
+		},
+		{
+			name: "rejects unmapped columns",
+			result: &sqltest.Result{
+				Columns: []string{"id", "unknown"},
+				Rows:    [][]driver.Value{{int64(1), "x"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns the iteration error",
+			result: &sqltest.Result{
+				Columns: []string{"id"},
+				Err:     errIteration,
+			},
+			wantErr: true,
+			errIs:   errIteration,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				service, _ := newTestService(t, rowsResult(tt.result))
+				users, err := QueryAll[scannedUser](
+					context.Background(),
+					service,
+					&query,
+				)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("QueryAll() error = nil, want error")
+					}
+					if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+						t.Fatalf("QueryAll() error = %v, want %v", err, tt.errIs)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("QueryAll() error = %v", err)
+				}
+				if len(users) != len(tt.want) {
+					t.Fatalf("QueryAll() returned %d rows, want %d", len(users), len(tt.want))
+				}
+				for i, user := range users {
+					if *user != tt.want[i] {
+						t.Errorf("QueryAll()[%d] = %+v, want %+v", i, *user, tt.want[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestQueryOne(t *testing.T) {
+	query := "SELECT id, name FROM users"
+
+	tests := []struct {
+		name    string
+		result  *sqltest.Result
+		want    *scannedUser
+		wantErr error
+	}{
+		{
+			name: "scans the first row",
+			result: &sqltest.Result{
+				Columns: []string{"id", "name"},
+				Rows: [][]driver.Value{
+					{int64(1), "alice"},
+					{int64(2), "bob"},
+				},
+			},
+			want: &scannedUser{ID: 1, Name: "alice"},
+		},
+		{
+			name:    "returns sql.ErrNoRows",
+			result:  &sqltest.Result{Columns: []string{"id", "name"}},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				service, _ := newTestService(t, rowsResult(tt.result))
+				user, err := QueryOne[scannedUser](
+					context.Background(),
+					service,
+					&query,
+				)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("QueryOne() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.want != nil && (user == nil || *user != *tt.want) {
+					t.Errorf("QueryOne() = %+v, want %+v", user, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestQueryNilService(t *testing.T) {
+	query := "SELECT 1"
+	if _, err := QueryOne[scannedUser](
+		context.Background(),
+		nil,
+		&query,
+	); err == nil {
+		t.Errorf("QueryOne() error = nil, want error")
+	}
+	if _, err := QueryAll[scannedUser](
+		context.Background(),
+		nil,
+		&query,
+	); err == nil {
+		t.Errorf("QueryAll() error = nil, want error")
+	}
+}
+
+func TestScanStructNilRows(t *testing.T) {
+	if _, err := ScanStruct[scannedUser](nil); !errors.Is(err, ErrNilRows) {
+		t.Errorf("ScanStruct() error = %v, want %v", err, ErrNilRows)
+	}
+}
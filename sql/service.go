@@ -3,14 +3,20 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"iter"
+	"time"
 
 	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/telemetry"
 )
 
 type (
 	// DefaultService is the default service struct
+	//
+	// The hooks are called around each query and transaction run through the service.
 	DefaultService struct {
 		Handler
+		hooks []Hook
 	}
 )
 
@@ -39,6 +45,33 @@ func NewDefaultService(config *Config) (
 	}, nil
 }
 
+// AddHooks adds hooks to be called around each query and transaction run through the service
+//
+// The hooks must be added before the service is used concurrently.
+//
+// Parameters:
+//
+// - hooks: the hooks to add
+//
+// Returns:
+//
+// - error: if the service or any hook is nil
+func (d *DefaultService) AddHooks(hooks ...Hook) error {
+	if d == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Check if any hook is nil
+	for _, hook := range hooks {
+		if hook == nil {
+			return ErrNilHook
+		}
+	}
+
+	d.hooks = append(d.hooks, hooks...)
+	return nil
+}
+
 // CreateTransaction creates a transaction for the database
 //
 // Parameters:
@@ -59,6 +92,44 @@ func (d *DefaultService) CreateTransaction(
 		return godatabases.ErrNilService
 	}
 
+	// Check if the transaction function is nil
+	if fn == nil {
+		return ErrNilTransactionFn
+	}
+
+	return d.CreateTransactionWithCtx(
+		ctx,
+		func(_ context.Context, tx *sql.Tx) error {
+			return fn(tx)
+		},
+		opts,
+	)
+}
+
+// CreateTransactionWithCtx creates a transaction for the database, passing the context carrying it to the
+// function
+//
+// The transaction is stored in the context passed to the function, so the service methods called with that
+// context are executed within the transaction.
+//
+// Parameters:
+//
+// - ctx: The context for the transaction
+// - fn: The function to execute within the transaction
+// - opts: The transaction options
+//
+// Returns:
+//
+// - error: An error if the transaction fails
+func (d *DefaultService) CreateTransactionWithCtx(
+	ctx context.Context,
+	fn TransactionWithCtxFn,
+	opts *sql.TxOptions,
+) error {
+	if d == nil {
+		return godatabases.ErrNilService
+	}
+
 	// Get the database connection
 	db, err := d.DB()
 	if err != nil {
@@ -66,7 +137,99 @@ func (d *DefaultService) CreateTransaction(
 	}
 
 	// Create the transaction
-	return CreateTransaction(ctx, db, fn, opts)
+	return createTransactionWithHooks(ctx, db, fn, opts, d.hooks, 1, nil)
+}
+
+// CreateTransactionWithRetry creates a transaction for the database, running it again while it fails with a
+// retryable error
+//
+// If the context already carries an active transaction, the function is run once within a savepoint.
+//
+// Parameters:
+//
+// - ctx: The context for the transaction
+// - fn: The function to execute within the transaction
+// - opts: The transaction options
+// - policy: The retry policy, the default retry policy is used if nil
+//
+// Returns:
+//
+// - error: An error if the transaction fails
+func (d *DefaultService) CreateTransactionWithRetry(
+	ctx context.Context,
+	fn TransactionWithCtxFn,
+	opts *sql.TxOptions,
+	policy *RetryPolicy,
+) error {
+	if d == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Get the database connection
+	db, err := d.DB()
+	if err != nil {
+		return err
+	}
+
+	// Create the transaction with retries between the hooks
+	return createTransactionWithRetry(ctx, db, fn, opts, policy, d.hooks)
+}
+
+// executor returns the active transaction in the context, or the database connection if there is none
+//
+// Parameters:
+//
+// - ctx: the context to use
+//
+// Returns:
+//
+// - Executor: the executor to run the queries with
+// - error: if the database connection is not established
+func (d *DefaultService) executor(ctx context.Context) (Executor, error) {
+	// Get the database connection
+	db, err := d.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the active transaction in the context if any
+	return GetExecutorFromContext(ctx, db), nil
+}
+
+// system returns the database system of the connection
+//
+// Returns:
+//
+// - string: the database system, such as postgresql
+func (d *DefaultService) system() string {
+	db, err := d.DB()
+	if err != nil {
+		return telemetry.SystemOtherSQL
+	}
+	return telemetry.SQLSystem(db.Driver())
+}
+
+// readExecutor returns the active transaction in the context, or the connection to read from if there is none
+//
+// Parameters:
+//
+// - ctx: the context to use
+//
+// Returns:
+//
+// - Executor: the executor to run the read queries with
+// - error: if the database connection is not established
+func (d *DefaultService) readExecutor(ctx context.Context) (Executor, error) {
+	// Check if the handler routes the reads to a different connection, unless there is an active transaction
+	// in the context
+	if _, inTx := GetTxFromContext(ctx); !inTx {
+		if readHandler, ok := d.Handler.(ReadHandler); ok {
+			return readHandler.ReadDB(ctx)
+		}
+	}
+
+	// Get the executor, using the active transaction in the context if any
+	return d.executor(ctx)
 }
 
 // Exec executes a query with parameters and returns the result
@@ -92,6 +255,8 @@ func (d *DefaultService) Exec(query *string, params ...any) (
 
 // ExecWithCtx executes a query with parameters and returns the result with a context
 //
+// If the context carries an active transaction, the query is executed within it.
+//
 // Parameters:
 //
 // - ctx: the context to use
@@ -119,14 +284,31 @@ func (d *DefaultService) ExecWithCtx(
 		return nil, godatabases.ErrNilQuery
 	}
 
-	// Get the database connection
-	db, err := d.DB()
+	// Get the executor, using the active transaction in the context if any
+	executor, err := d.executor(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Run the exec
-	return db.ExecContext(ctx, *query, params...)
+	// Run the exec between the hooks
+	var result sql.Result
+	_, inTx := GetTxFromContext(ctx)
+	err = runHooks(
+		ctx,
+		d.hooks,
+		&QueryEvent{
+			Operation: OperationExec,
+			System:    d.system(),
+			Query:     *query,
+			Args:      params,
+			InTx:      inTx,
+		},
+		func(ctx context.Context) (execErr error) {
+			result, execErr = executor.ExecContext(ctx, *query, params...)
+			return execErr
+		},
+	)
+	return result, err
 }
 
 // QueryRow runs a query row with parameters and returns the result row
@@ -152,6 +334,9 @@ func (d *DefaultService) QueryRow(
 
 // QueryRowWithCtx runs a query row with parameters and returns the result row with a context
 //
+// If the context carries an active transaction, the query is executed within it. Otherwise, if the handler
+// is a ReadHandler, the query is executed on the connection it routes the reads to.
+//
 // Parameters:
 //
 // - ctx: the context to use
@@ -176,14 +361,129 @@ func (d *DefaultService) QueryRowWithCtx(
 		return nil, godatabases.ErrNilQuery
 	}
 
-	// Get the database connection
-	db, err := d.DB()
+	// Get the read executor, using the active transaction in the context if any
+	executor, err := d.readExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the query row between the hooks
+	var row *sql.Row
+	_, inTx := GetTxFromContext(ctx)
+	_ = runHooks(
+		ctx,
+		d.hooks,
+		&QueryEvent{
+			Operation: OperationQueryRow,
+			System:    d.system(),
+			Query:     *query,
+			Args:      params,
+			InTx:      inTx,
+		},
+		func(ctx context.Context) error {
+			row = executor.QueryRowContext(ctx, *query, params...)
+			return row.Err()
+		},
+	)
+	return row, nil
+}
+
+// Query runs a query with parameters and returns an iterator over the result rows
+//
+// Parameters:
+//
+// - query: the query to execute
+// - params: the parameters for the query
+//
+// Returns:
+//
+// - iter.Seq2[*sql.Rows, error]: the result rows iterator
+// - error: if any error occurs
+func (d *DefaultService) Query(
+	query *string,
+	params ...any,
+) (iter.Seq2[*sql.Rows, error], error) {
+	if d == nil {
+		return nil, godatabases.ErrNilService
+	}
+	return d.QueryWithCtx(context.Background(), query, params...)
+}
+
+// QueryWithCtx runs a query with parameters and returns an iterator over the result rows with a context
+//
+// The query is executed lazily when the iterator is ranged over, and the rows are always closed once the
+// iteration finishes. Any error returned by the query or by the rows is yielded with a nil row. If the
+// context carries an active transaction, the query is executed within it. Otherwise, if the handler is a
+// ReadHandler, the query is executed on the connection it routes the reads to. The after hooks are called
+// once the iteration finishes, even if the consumer panics, with the duration of the query up to its first
+// row, so the time spent by the consumer is not reported.
+//
+// Parameters:
+//
+// - ctx: the context to use
+// - query: the query to execute
+// - params: the parameters for the query
+//
+// Returns:
+//
+// - iter.Seq2[*sql.Rows, error]: the result rows iterator
+// - error: if any error occurs
+func (d *DefaultService) QueryWithCtx(
+	ctx context.Context,
+	query *string,
+	params ...any,
+) (iter.Seq2[*sql.Rows, error], error) {
+	if d == nil {
+		return nil, godatabases.ErrNilService
+	}
+
+	// Check if the query is nil
+	if query == nil {
+		return nil, godatabases.ErrNilQuery
+	}
+
+	// Get the read executor, using the active transaction in the context if any
+	executor, err := d.readExecutor(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Run the query row
-	return db.QueryRowContext(ctx, *query, params...), nil
+	// Create the rows iterator, calling the hooks around the whole iteration
+	_, inTx := GetTxFromContext(ctx)
+	return func(yield func(*sql.Rows, error) bool) {
+		event := &QueryEvent{
+			Operation: OperationQuery,
+			System:    d.system(),
+			Query:     *query,
+			Args:      params,
+			InTx:      inTx,
+		}
+		hooksCtx := beforeHooks(ctx, d.hooks, event)
+
+		// Call the after hooks once the iteration finishes, even if the consumer panics
+		var iterErr error
+		defer func() {
+			afterHooks(hooksCtx, d.hooks, event, iterErr)
+		}()
+
+		for row, rowErr := range IterateRows(
+			func() (*sql.Rows, error) {
+				return executor.QueryContext(hooksCtx, *query, params...)
+			},
+		) {
+			// Measure the query up to its first row, before the consumer handles it
+			if event.Duration == 0 {
+				event.Duration = time.Since(event.StartedAt)
+			}
+
+			if rowErr != nil {
+				iterErr = rowErr
+			}
+			if !yield(row, rowErr) {
+				return
+			}
+		}
+	}, nil
 }
 
 // ScanRow scans a row
@@ -0,0 +1,121 @@
+package sql
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// RedactedArg is the value logged in place of each query argument
+	RedactedArg = "[REDACTED]"
+)
+
+type (
+	// SlogHook is the hook that logs the queries and transactions with a slog logger
+	//
+	// The query arguments are redacted. The operations are logged at debug level, the failed ones at error
+	// level, and the ones that took at least the slow threshold at warn level with the caller location.
+	SlogHook struct {
+		logger        *slog.Logger
+		slowThreshold time.Duration
+	}
+)
+
+// NewSlogHook creates a new slog hook
+//
+// Parameters:
+//
+//   - logger: the logger to use
+//   - slowThreshold: the duration from which an operation is logged as slow, disabled if it is not positive
+//
+// Returns:
+//
+//   - *SlogHook: the slog hook
+//   - error: if the logger is nil
+func NewSlogHook(logger *slog.Logger, slowThreshold time.Duration) (
+	*SlogHook,
+	error,
+) {
+	// Check if the logger is nil
+	if logger == nil {
+		return nil, ErrNilLogger
+	}
+
+	return &SlogHook{
+		logger:        logger,
+		slowThreshold: slowThreshold,
+	}, nil
+}
+
+// BeforeQuery does nothing, the operations are logged once they finished
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - event: the query event
+//
+// Returns:
+//
+//   - context.Context: the same context
+func (s *SlogHook) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+// AfterQuery logs the finished operation
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - event: the query event
+func (s *SlogHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	if s == nil || event == nil {
+		return
+	}
+
+	// Build the attributes
+	attrs := []slog.Attr{
+		slog.String("operation", string(event.Operation)),
+		slog.Duration("duration", event.Duration),
+		slog.Bool("in_tx", event.InTx),
+	}
+	if event.Query != "" {
+		args := make([]string, len(event.Args))
+		for i := range args {
+			args[i] = RedactedArg
+		}
+		attrs = append(
+			attrs,
+			slog.String("query", event.Query),
+			slog.Any("args", args),
+		)
+	}
+	if event.Outcome != "" {
+		attrs = append(
+			attrs,
+			slog.String("outcome", string(event.Outcome)),
+			slog.Int("attempt", event.Attempt),
+		)
+	}
+	if event.Err != nil {
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	}
+
+	// Log the slow operations with the caller location
+	if s.slowThreshold > 0 && event.Duration >= s.slowThreshold {
+		attrs = append(
+			attrs,
+			slog.Duration("slow_threshold", s.slowThreshold),
+			slog.String("caller", CallerLocation()),
+		)
+		s.logger.LogAttrs(ctx, slog.LevelWarn, "slow sql operation", attrs...)
+		return
+	}
+
+	// Log the failed operations
+	if event.Err != nil {
+		s.logger.LogAttrs(ctx, slog.LevelError, "sql operation failed", attrs...)
+		return
+	}
+	s.logger.LogAttrs(ctx, slog.LevelDebug, "sql operation", attrs...)
+}
@@ -0,0 +1,197 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+	gopgx "github.com/ralvarezdev/go-databases/sql/pgx"
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+// setTestProviders sets in-memory telemetry providers until the test ends
+func setTestProviders(t *testing.T) (
+	*tracetest.SpanRecorder,
+	*sdkmetric.ManualReader,
+) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	telemetry.SetProviders(
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	)
+	t.Cleanup(func() { telemetry.SetProviders(nil, nil) })
+	return recorder, reader
+}
+
+// transactionOutcomes collects the transactions counter by outcome
+func transactionOutcomes(
+	t *testing.T,
+	reader *sdkmetric.ManualReader,
+) map[string]int64 {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	outcomes := make(map[string]int64)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != "db.client.transactions" {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				t.Fatalf("transactions data = %T, want an int64 sum", m.Data)
+			}
+			for _, point := range sum.DataPoints {
+				outcome, _ := point.Attributes.Value(telemetry.AttributeTransactionOutcome)
+				outcomes[outcome.AsString()] += point.Value
+			}
+		}
+	}
+	return outcomes
+}
+
+// spanOutcome returns the outcome attribute of an ended span
+func spanOutcome(span sdktrace.ReadOnlySpan) string {
+	for _, attr := range span.Attributes() {
+		if attr.Key == telemetry.AttributeTransactionOutcome {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestCreateTransactionTelemetry(t *testing.T) {
+	errFn := errors.New("function failed")
+
+	tests := []struct {
+		name         string
+		fn           TransactionFn
+		wantErr      error
+		wantOutcomes map[string]int64
+	}{
+		{
+			name: "commit",
+			fn: func(*sql.Tx) error {
+				return nil
+			},
+			wantOutcomes: map[string]int64{telemetry.OutcomeCommit: 1},
+		},
+		{
+			name: "rollback",
+			fn: func(*sql.Tx) error {
+				return errFn
+			},
+			wantErr:      errFn,
+			wantOutcomes: map[string]int64{telemetry.OutcomeRollback: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				recorder, reader := setTestProviders(t)
+				db, _ := sqltest.Open(nil)
+				defer db.Close()
+
+				err := CreateTransaction(context.Background(), db, tt.fn, nil)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateTransaction() error = %v, want %v", err, tt.wantErr)
+				}
+
+				// Check the transaction span
+				spans := recorder.Ended()
+				if len(spans) != 1 {
+					t.Fatalf("ended spans = %d, want 1", len(spans))
+				}
+				wantOutcome := telemetry.OutcomeCommit
+				if tt.wantErr != nil {
+					wantOutcome = telemetry.OutcomeRollback
+				}
+				if got := spanOutcome(spans[0]); got != wantOutcome {
+					t.Errorf("span outcome = %q, want %q", got, wantOutcome)
+				}
+
+				// Check the transactions counter
+				if got := transactionOutcomes(t, reader); !maps.Equal(
+					got,
+					tt.wantOutcomes,
+				) {
+					t.Errorf("outcomes = %v, want %v", got, tt.wantOutcomes)
+				}
+			},
+		)
+	}
+}
+
+func TestCreateTransactionWithRetryTelemetry(t *testing.T) {
+	retryable := &pgconn.PgError{Code: gopgx.SerializationFailureCode}
+	recorder, reader := setTestProviders(t)
+	db, _ := sqltest.Open(nil)
+	defer db.Close()
+
+	// Fail the first attempt with a retryable error
+	attempts := 0
+	err := CreateTransactionWithRetry(
+		context.Background(),
+		db,
+		func(context.Context, *sql.Tx) error {
+			attempts++
+			if attempts == 1 {
+				return retryable
+			}
+			return nil
+		},
+		nil,
+		&RetryPolicy{MaxAttempts: 3, Multiplier: 1},
+	)
+	if err != nil {
+		t.Fatalf("CreateTransactionWithRetry() error = %v", err)
+	}
+
+	// Check that each attempt is counted once
+	want := map[string]int64{
+		telemetry.OutcomeRetry:  1,
+		telemetry.OutcomeCommit: 1,
+	}
+	if got := transactionOutcomes(t, reader); !maps.Equal(got, want) {
+		t.Errorf("outcomes = %v, want %v", got, want)
+	}
+
+	// Check the attempt spans, the retried one carrying the retry event
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d, want 2", len(spans))
+	}
+	if got := spanOutcome(spans[0]); got != telemetry.OutcomeRetry {
+		t.Errorf("first span outcome = %q, want %q", got, telemetry.OutcomeRetry)
+	}
+	if events := spans[0].Events(); !hasEvent(events, "transaction retry") {
+		t.Errorf("first span events = %v, want a retry event", events)
+	}
+	if got := spanOutcome(spans[1]); got != telemetry.OutcomeCommit {
+		t.Errorf("second span outcome = %q, want %q", got, telemetry.OutcomeCommit)
+	}
+}
+
+// hasEvent checks if the span events contain the given event name
+func hasEvent(events []sdktrace.Event, name string) bool {
+	for _, event := range events {
+		if event.Name == name {
+			return true
+		}
+	}
+	return false
+}
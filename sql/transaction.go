@@ -3,23 +3,39 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	godatabases "github.com/ralvarezdev/go-databases"
+	"github.com/ralvarezdev/go-databases/telemetry"
+)
+
+const (
+	// SavepointPrefix is the prefix of the savepoints created for nested transactions
+	SavepointPrefix = "sp_"
 )
 
 type (
 	// TransactionFn is the function type for transactions
 	TransactionFn func(tx *sql.Tx) error
+
+	// TransactionWithCtxFn is the function type for transactions with context
+	//
+	// The context carries the active transaction, so nested calls to CreateTransactionWithCtx create a
+	// savepoint instead of starting a new transaction.
+	TransactionWithCtxFn func(ctx context.Context, tx *sql.Tx) error
 )
 
 // CreateTransaction creates a transaction for the database
 //
+// It behaves like CreateTransactionWithCtx, for the functions that do not need the context carrying the
+// transaction.
+//
 // Parameters:
 //
 //   - ctx: The context for the transaction
 //   - db: The database connection
 //   - fn: The function to execute within the transaction
-//   - opts: The transaction options
+//   - opts: The transaction options, ignored for nested transactions
 //
 // Returns:
 //
@@ -30,19 +46,146 @@ func CreateTransaction(
 	fn TransactionFn,
 	opts *sql.TxOptions,
 ) error {
-	// Check if the connection is nil
+	// Check if the transaction function is nil
+	if fn == nil {
+		return ErrNilTransactionFn
+	}
+
+	return CreateTransactionWithCtx(
+		ctx,
+		db,
+		func(_ context.Context, tx *sql.Tx) error {
+			return fn(tx)
+		},
+		opts,
+	)
+}
+
+// CreateTransactionWithCtx creates a transaction for the database, passing the context carrying it to the
+// function
+//
+// If the context already carries an active transaction, a savepoint is created within it instead, which is
+// released if the function succeeds or rolled back to if it fails. If the function panics, the transaction
+// or the savepoint is rolled back and the panic is propagated. A span is recorded for the transaction with its
+// outcome if the telemetry providers are set.
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - db: The database connection
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options, ignored for nested transactions
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func CreateTransactionWithCtx(
+	ctx context.Context,
+	db *sql.DB,
+	fn TransactionWithCtxFn,
+	opts *sql.TxOptions,
+) error {
+	return createTransactionAttempt(ctx, db, fn, opts, 1, nil)
+}
+
+// createTransactionAttempt creates a transaction for the database as an attempt of the retry policy
+//
+// The outcome recorded for the attempt is the retry one if it failed and the policy runs it again, so each
+// attempt is counted once.
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - db: The database connection
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options, ignored for nested transactions
+//   - attempt: The attempt number, starting at 1
+//   - policy: The retry policy used to tell the retried attempts apart, it can be nil
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func createTransactionAttempt(
+	ctx context.Context,
+	db *sql.DB,
+	fn TransactionWithCtxFn,
+	opts *sql.TxOptions,
+	attempt int,
+	policy *RetryPolicy,
+) (err error) {
+	// Check if the connection or the transaction function is nil
 	if db == nil {
 		return godatabases.ErrNilConnection
 	}
+	if fn == nil {
+		return ErrNilTransactionFn
+	}
+
+	// Start the transaction span
+	value, nested := getTxContextValue(ctx)
+	system := telemetry.SQLSystem(db.Driver())
+	ctx, span := telemetry.StartSpan(
+		ctx,
+		system,
+		telemetry.OperationTransaction,
+		"",
+		telemetry.AttributeTransactionNested.Bool(nested),
+	)
+	outcome := telemetry.OutcomeRollback
+	defer func() {
+		telemetry.EndTransactionSpan(ctx, span, system, outcome, err)
+	}()
+
+	// Check if there is an active transaction in the context
+	if nested {
+		err = createSavepoint(ctx, value, fn)
+	} else {
+		err = createTransaction(ctx, db, fn, opts)
+	}
+	switch {
+	case err == nil:
+		outcome = telemetry.OutcomeCommit
+	case policy.ShouldRetry(attempt, err):
+		outcome = telemetry.OutcomeRetry
+	}
+	return err
+}
 
+// createTransaction starts a transaction and executes the function within it
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction
+//   - db: The database connection
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func createTransaction(
+	ctx context.Context,
+	db *sql.DB,
+	fn TransactionWithCtxFn,
+	opts *sql.TxOptions,
+) error {
 	// Start a transaction
 	tx, err := db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
 
+	// Rollback the transaction if the function panics
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
 	// Execute the transaction function
-	if fnErr := fn(tx); fnErr != nil {
+	txCtx := withTxContextValue(ctx, &txContextValue{tx: tx})
+	if fnErr := fn(txCtx, tx); fnErr != nil {
 		err = tx.Rollback()
 		if err != nil {
 			return err
@@ -53,3 +196,141 @@ func CreateTransaction(
 	// Commit the transaction
 	return tx.Commit()
 }
+
+// createSavepoint creates a savepoint within the active transaction and executes the function within it
+//
+// Parameters:
+//
+//   - ctx: The context carrying the active transaction
+//   - value: The active transaction context value
+//   - fn: The function to execute within the savepoint
+//
+// Returns:
+//
+//   - error: An error if the savepoint fails
+func createSavepoint(
+	ctx context.Context,
+	value *txContextValue,
+	fn TransactionWithCtxFn,
+) error {
+	// Create the savepoint
+	depth := value.depth + 1
+	name := fmt.Sprintf("%s%d", SavepointPrefix, depth)
+	if _, err := value.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	// Rollback to the savepoint if the function panics, even if the context is done
+	rollbackCtx := context.WithoutCancel(ctx)
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = value.tx.ExecContext(rollbackCtx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	// Execute the function within the savepoint
+	savepointCtx := withTxContextValue(
+		ctx,
+		&txContextValue{tx: value.tx, depth: depth},
+	)
+	if fnErr := fn(savepointCtx, value.tx); fnErr != nil {
+		// Rollback to the savepoint, even if the context is done
+		if _, err := value.tx.ExecContext(
+			rollbackCtx,
+			"ROLLBACK TO SAVEPOINT "+name,
+		); err != nil {
+			return err
+		}
+		return fnErr
+	}
+
+	// Release the savepoint
+	_, err := value.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// CreateTransactionWithRetry creates a transaction for the database, running it again while it fails with a
+// retryable error
+//
+// The whole transaction function is run again on each attempt, so it must not have side effects outside the
+// transaction. If the context already carries an active transaction, the function is run once within a
+// savepoint, since retryable errors must be handled by the outermost transaction.
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction, the retries stop once it is done
+//   - db: The database connection
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//   - policy: The retry policy, the default retry policy is used if nil
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func CreateTransactionWithRetry(
+	ctx context.Context,
+	db *sql.DB,
+	fn TransactionWithCtxFn,
+	opts *sql.TxOptions,
+	policy *RetryPolicy,
+) error {
+	// Check if the connection is nil
+	if db == nil {
+		return godatabases.ErrNilConnection
+	}
+
+	return createTransactionWithRetry(ctx, db, fn, opts, policy, nil)
+}
+
+// createTransactionWithRetry creates a transaction for the database between the hooks, running it again while
+// it fails with a retryable error
+//
+// Parameters:
+//
+//   - ctx: The context for the transaction, the retries stop once it is done
+//   - db: The database connection
+//   - fn: The function to execute within the transaction
+//   - opts: The transaction options
+//   - policy: The retry policy, the default retry policy is used if nil
+//   - hooks: The hooks called around each attempt, it can be empty
+//
+// Returns:
+//
+//   - error: An error if the transaction fails
+func createTransactionWithRetry(
+	ctx context.Context,
+	db *sql.DB,
+	fn TransactionWithCtxFn,
+	opts *sql.TxOptions,
+	policy *RetryPolicy,
+	hooks []Hook,
+) error {
+	// Check if there is an active transaction in the context
+	if _, ok := getTxContextValue(ctx); ok {
+		return createTransactionWithHooks(ctx, db, fn, opts, hooks, 1, nil)
+	}
+
+	// Set the default retry policy
+	if policy == nil {
+		policy = NewDefaultRetryPolicy()
+	}
+
+	// Run the transaction with retries
+	attempt := 0
+	return policy.Run(
+		ctx,
+		func(ctx context.Context) error {
+			attempt++
+			return createTransactionWithHooks(
+				ctx,
+				db,
+				fn,
+				opts,
+				hooks,
+				attempt,
+				policy,
+			)
+		},
+	)
+}
@@ -0,0 +1,178 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+func TestCreateTransaction(t *testing.T) {
+	errFn := errors.New("function failed")
+
+	tests := []struct {
+		name           string
+		fn             TransactionFn
+		wantErr        error
+		wantStatements []string
+	}{
+		{
+			name: "commits",
+			fn: func(tx *sql.Tx) error {
+				_, err := tx.Exec("INSERT 1")
+				return err
+			},
+			wantStatements: []string{
+				sqltest.BeginStatement,
+				"INSERT 1",
+				sqltest.CommitStatement,
+			},
+		},
+		{
+			name: "rolls back",
+			fn: func(*sql.Tx) error {
+				return errFn
+			},
+			wantErr: errFn,
+			wantStatements: []string{
+				sqltest.BeginStatement,
+				sqltest.RollbackStatement,
+			},
+		},
+		{
+			name:    "nil function",
+			wantErr: ErrNilTransactionFn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, connector := sqltest.Open(nil)
+				defer db.Close()
+
+				err := CreateTransaction(context.Background(), db, tt.fn, nil)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateTransaction() error = %v, want %v", err, tt.wantErr)
+				}
+				if got := connector.Statements(); !slices.Equal(
+					got,
+					tt.wantStatements,
+				) {
+					t.Errorf("statements = %v, want %v", got, tt.wantStatements)
+				}
+			},
+		)
+	}
+}
+
+func TestCreateTransactionWithCtxNested(t *testing.T) {
+	errInner := errors.New("inner failed")
+	db, connector := sqltest.Open(nil)
+	defer db.Close()
+
+	err := CreateTransactionWithCtx(
+		context.Background(),
+		db,
+		func(ctx context.Context, tx *sql.Tx) error {
+			// Run a savepoint that is released
+			if err := CreateTransactionWithCtx(
+				ctx, db, func(context.Context, *sql.Tx) error {
+					return nil
+				}, nil,
+			); err != nil {
+				return err
+			}
+
+			// Run a savepoint that is rolled back to
+			if err := CreateTransactionWithCtx(
+				ctx, db, func(context.Context, *sql.Tx) error {
+					return errInner
+				}, nil,
+			); !errors.Is(err, errInner) {
+				t.Errorf("nested CreateTransactionWithCtx() error = %v, want %v", err, errInner)
+			}
+			return nil
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("CreateTransactionWithCtx() error = %v", err)
+	}
+
+	want := []string{
+		sqltest.BeginStatement,
+		"SAVEPOINT sp_1",
+		"RELEASE SAVEPOINT sp_1",
+		"SAVEPOINT sp_1",
+		"ROLLBACK TO SAVEPOINT sp_1",
+		sqltest.CommitStatement,
+	}
+	if got := connector.Statements(); !slices.Equal(got, want) {
+		t.Errorf("statements = %v, want %v", got, want)
+	}
+}
+
+func TestCreateTransactionWithCtxNestedCanceled(t *testing.T) {
+	db, connector := sqltest.Open(nil)
+	defer db.Close()
+
+	err := CreateTransactionWithCtx(
+		context.Background(),
+		db,
+		func(ctx context.Context, tx *sql.Tx) error {
+			// Run a savepoint whose context is canceled before it fails
+			savepointCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			if err := CreateTransactionWithCtx(
+				savepointCtx, db, func(context.Context, *sql.Tx) error {
+					cancel()
+					return context.Canceled
+				}, nil,
+			); !errors.Is(err, context.Canceled) {
+				t.Errorf("nested CreateTransactionWithCtx() error = %v, want %v", err, context.Canceled)
+			}
+			return nil
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("CreateTransactionWithCtx() error = %v", err)
+	}
+
+	want := []string{
+		sqltest.BeginStatement,
+		"SAVEPOINT sp_1",
+		"ROLLBACK TO SAVEPOINT sp_1",
+		sqltest.CommitStatement,
+	}
+	if got := connector.Statements(); !slices.Equal(got, want) {
+		t.Errorf("statements = %v, want %v", got, want)
+	}
+}
+
+func TestCreateTransactionWithCtxPanic(t *testing.T) {
+	db, connector := sqltest.Open(nil)
+	defer db.Close()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("CreateTransactionWithCtx() did not propagate the panic")
+		}
+		want := []string{sqltest.BeginStatement, sqltest.RollbackStatement}
+		if got := connector.Statements(); !slices.Equal(got, want) {
+			t.Errorf("statements = %v, want %v", got, want)
+		}
+	}()
+
+	_ = CreateTransactionWithCtx(
+		context.Background(),
+		db,
+		func(context.Context, *sql.Tx) error {
+			panic("boom")
+		},
+		nil,
+	)
+}
@@ -0,0 +1,520 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+	gopgxpool "github.com/ralvarezdev/go-databases/sql/pgxpool"
+)
+
+const (
+	// DefaultSampleInterval is the default interval between samples
+	DefaultSampleInterval = 15 * time.Second
+
+	// ReplicaNameFormat is the format of the pool names of the read replicas, from the handler name and the
+	// replica index
+	ReplicaNameFormat = "%s/replica/%d"
+)
+
+type (
+	// SampleFn is the function type used to sample the statistics of a connection pool
+	SampleFn func() (Snapshot, error)
+
+	// sampleSourceFn is the function type used to sample the statistics of the connection pools of a source,
+	// such as a replicated handler and its read replicas
+	sampleSourceFn func() ([]Snapshot, error)
+
+	// Collector periodically samples the statistics of the added connection pools
+	Collector struct {
+		interval      time.Duration
+		sources       map[string]sampleSourceFn
+		snapshots     map[string][]Snapshot
+		errs          map[string]error
+		snapshotMutex sync.RWMutex
+		cancel        context.CancelFunc
+		done          chan struct{}
+		mutex         sync.Mutex
+	}
+)
+
+// NewCollector creates a new stats collector
+//
+// Parameters:
+//
+//   - interval: the interval between samples, DefaultSampleInterval is used if it is not positive
+//
+// Returns:
+//
+//   - *Collector: the stats collector
+func NewCollector(interval time.Duration) *Collector {
+	// Set the default interval
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+
+	return &Collector{
+		interval:  interval,
+		sources:   make(map[string]sampleSourceFn),
+		snapshots: make(map[string][]Snapshot),
+		errs:      make(map[string]error),
+	}
+}
+
+// Add adds a named connection pool sampled by the given function
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - sampleFn: the function used to sample the pool statistics
+//
+// Returns:
+//
+//   - error: if the sample function is nil, or the name is empty or already added
+func (c *Collector) Add(name string, sampleFn SampleFn) error {
+	if c == nil {
+		return ErrNilCollector
+	}
+
+	// Check if the sample function is nil
+	if sampleFn == nil {
+		return ErrNilSampleFn
+	}
+
+	return c.add(
+		name,
+		func() ([]Snapshot, error) {
+			snapshot, err := sampleFn()
+			if err != nil {
+				return nil, err
+			}
+			return []Snapshot{snapshot}, nil
+		},
+	)
+}
+
+// add adds a named source of connection pools sampled by the given function
+//
+// Parameters:
+//
+//   - name: the source name
+//   - sampleFn: the function used to sample the statistics of the source pools
+//
+// Returns:
+//
+//   - error: if the sample function is nil, or the name is empty or already added
+func (c *Collector) add(name string, sampleFn sampleSourceFn) error {
+	if c == nil {
+		return ErrNilCollector
+	}
+
+	// Check if the sample function is nil
+	if sampleFn == nil {
+		return ErrNilSampleFn
+	}
+
+	// Check if the name is empty
+	if name == "" {
+		return ErrEmptyName
+	}
+
+	// Lock the mutex to ensure thread safety
+	c.snapshotMutex.Lock()
+	defer c.snapshotMutex.Unlock()
+
+	// Check if the name is already added
+	if _, ok := c.sources[name]; ok {
+		return fmt.Errorf(ErrAlreadyAdded, name)
+	}
+	c.sources[name] = sampleFn
+	return nil
+}
+
+// AddSQLHandler adds a named SQL handler, whose connection pool is sampled while it is connected
+//
+// The statistics are read through the Stats method if the handler implements gosql.StatsHandler. If it also
+// implements gosql.ReplicaStatsHandler, such as the replicated handlers, the connected read replicas are
+// sampled too, named as formatted by ReplicaNameFormat, and removed along with the handler.
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - handler: the SQL handler
+//
+// Returns:
+//
+//   - error: if the handler is nil, or the name is empty or already added
+func (c *Collector) AddSQLHandler(name string, handler gosql.Handler) error {
+	// Check if the handler is nil
+	if handler == nil {
+		return godatabases.ErrNilHandler
+	}
+
+	return c.add(
+		name,
+		func() ([]Snapshot, error) {
+			stats, err := sqlHandlerStats(handler)
+			if err != nil {
+				return nil, err
+			}
+			snapshots := []Snapshot{NewSQLSnapshot(name, stats)}
+
+			// Sample the read replicas
+			replicaHandler, ok := handler.(gosql.ReplicaStatsHandler)
+			if !ok {
+				return snapshots, nil
+			}
+			for index, replicaStats := range replicaHandler.ReplicaStats() {
+				snapshots = append(
+					snapshots,
+					NewSQLSnapshot(
+						fmt.Sprintf(ReplicaNameFormat, name, index),
+						replicaStats,
+					),
+				)
+			}
+			return snapshots, nil
+		},
+	)
+}
+
+// sqlHandlerStats returns the statistics of the connection pool of a SQL handler
+//
+// Parameters:
+//
+//   - handler: the SQL handler
+//
+// Returns:
+//
+//   - sql.DBStats: the connection pool statistics
+//   - error: if the handler is not connected
+func sqlHandlerStats(handler gosql.Handler) (sql.DBStats, error) {
+	// Check if the handler reports its own statistics
+	if statsHandler, ok := handler.(gosql.StatsHandler); ok {
+		return statsHandler.Stats()
+	}
+
+	// Get the database connection
+	db, err := handler.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return db.Stats(), nil
+}
+
+// AddPgxPoolHandler adds a named pgx pool handler, whose pool is sampled while it is connected
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - handler: the pgx pool handler
+//
+// Returns:
+//
+//   - error: if the handler is nil, or the name is empty or already added
+func (c *Collector) AddPgxPoolHandler(
+	name string,
+	handler gopgxpool.Handler,
+) error {
+	// Check if the handler is nil
+	if handler == nil {
+		return godatabases.ErrNilHandler
+	}
+
+	return c.Add(
+		name,
+		func() (Snapshot, error) {
+			// Check if the handler reports its own statistics
+			if statsHandler, ok := handler.(gopgxpool.StatsHandler); ok {
+				stat, err := statsHandler.Stats()
+				if err != nil {
+					return Snapshot{}, err
+				}
+				return NewPgxPoolSnapshot(name, stat), nil
+			}
+
+			// Get the pool
+			pool, err := handler.DB()
+			if err != nil {
+				return Snapshot{}, err
+			}
+			return NewPgxPoolSnapshot(name, pool.Stat()), nil
+		},
+	)
+}
+
+// AddPgxPool adds a named pgx pool
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - pool: the pgx pool
+//
+// Returns:
+//
+//   - error: if the pool is nil, or the name is empty or already added
+func (c *Collector) AddPgxPool(name string, pool *pgxpool.Pool) error {
+	// Check if the pool is nil
+	if pool == nil {
+		return godatabases.ErrNilPool
+	}
+
+	return c.Add(
+		name,
+		func() (Snapshot, error) {
+			return NewPgxPoolSnapshot(name, pool.Stat()), nil
+		},
+	)
+}
+
+// Remove removes a named connection pool, its last snapshots, including the ones of its read replicas, and its
+// last sampling error
+//
+// Parameters:
+//
+//   - name: the pool name
+func (c *Collector) Remove(name string) {
+	if c == nil {
+		return
+	}
+
+	// Lock the mutex to ensure thread safety
+	c.snapshotMutex.Lock()
+	defer c.snapshotMutex.Unlock()
+
+	delete(c.sources, name)
+	delete(c.snapshots, name)
+	delete(c.errs, name)
+}
+
+// Sample samples the statistics of every added connection pool
+//
+// The pools that could not be sampled, such as the disconnected SQL handlers, keep no snapshot, and their
+// sampling error is kept until they are sampled again, see Err. The sample functions are called without holding
+// the lock, so they can read the collector snapshots.
+func (c *Collector) Sample() {
+	if c == nil {
+		return
+	}
+
+	// Copy the sources to sample them without holding the lock
+	c.snapshotMutex.RLock()
+	sources := make(map[string]sampleSourceFn, len(c.sources))
+	for name, sampleFn := range c.sources {
+		sources[name] = sampleFn
+	}
+	c.snapshotMutex.RUnlock()
+
+	// Sample the sources
+	sampled := make(map[string][]Snapshot, len(sources))
+	errs := make(map[string]error, len(sources))
+	for name, sampleFn := range sources {
+		snapshots, err := sampleFn()
+		if err != nil {
+			errs[name] = err
+			snapshots = nil
+		}
+		sampled[name] = snapshots
+	}
+
+	// Lock the mutex to ensure thread safety
+	c.snapshotMutex.Lock()
+	defer c.snapshotMutex.Unlock()
+
+	for name, snapshots := range sampled {
+		// Skip the sources removed while sampling
+		if _, ok := c.sources[name]; !ok {
+			continue
+		}
+
+		// Keep the sampling error, or clear the previous one
+		if err, ok := errs[name]; ok {
+			c.errs[name] = err
+		} else {
+			delete(c.errs, name)
+		}
+
+		if len(snapshots) == 0 {
+			delete(c.snapshots, name)
+			continue
+		}
+		c.snapshots[name] = snapshots
+	}
+}
+
+// Err returns the error of the last sample of a named source, such as the error of a disconnected SQL handler
+//
+// Parameters:
+//
+//   - name: the name the source was added with
+//
+// Returns:
+//
+//   - error: the last sampling error, or nil if the source was sampled successfully or not sampled yet
+func (c *Collector) Err(name string) error {
+	if c == nil {
+		return ErrNilCollector
+	}
+
+	c.snapshotMutex.RLock()
+	defer c.snapshotMutex.RUnlock()
+	return c.errs[name]
+}
+
+// sampledSources returns whether the last sample of each sampled source succeeded
+//
+// Returns:
+//
+//   - map[string]bool: true if the last sample of the source succeeded, false otherwise, by source name
+func (c *Collector) sampledSources() map[string]bool {
+	if c == nil {
+		return nil
+	}
+
+	c.snapshotMutex.RLock()
+	defer c.snapshotMutex.RUnlock()
+	sampled := make(map[string]bool, len(c.snapshots)+len(c.errs))
+	for name := range c.snapshots {
+		sampled[name] = true
+	}
+	for name := range c.errs {
+		sampled[name] = false
+	}
+	return sampled
+}
+
+// Snapshot returns the last snapshot of a named connection pool
+//
+// Parameters:
+//
+//   - name: the pool name
+//
+// Returns:
+//
+//   - Snapshot: the last snapshot
+//   - bool: true if the pool was sampled, false otherwise
+func (c *Collector) Snapshot(name string) (Snapshot, bool) {
+	if c == nil {
+		return Snapshot{}, false
+	}
+
+	c.snapshotMutex.RLock()
+	defer c.snapshotMutex.RUnlock()
+	for _, snapshots := range c.snapshots {
+		for _, snapshot := range snapshots {
+			if snapshot.Name == name {
+				return snapshot, true
+			}
+		}
+	}
+	return Snapshot{}, false
+}
+
+// Snapshots returns the last snapshots of every sampled connection pool, sorted by name
+//
+// Returns:
+//
+//   - []Snapshot: the last snapshots
+func (c *Collector) Snapshots() []Snapshot {
+	if c == nil {
+		return nil
+	}
+
+	c.snapshotMutex.RLock()
+	snapshots := make([]Snapshot, 0, len(c.snapshots))
+	for _, sourceSnapshots := range c.snapshots {
+		snapshots = append(snapshots, sourceSnapshots...)
+	}
+	c.snapshotMutex.RUnlock()
+
+	slices.SortFunc(
+		snapshots, func(a, b Snapshot) int {
+			switch {
+			case a.Name < b.Name:
+				return -1
+			case a.Name > b.Name:
+				return 1
+			default:
+				return 0
+			}
+		},
+	)
+	return snapshots
+}
+
+// Start starts sampling the statistics periodically, taking the first sample immediately
+//
+// Parameters:
+//
+//   - ctx: the context, the sampling stops once it is done
+//
+// Returns:
+//
+//   - error: if the collector is already started
+func (c *Collector) Start(ctx context.Context) error {
+	if c == nil {
+		return ErrNilCollector
+	}
+
+	// Lock the mutex to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Check if the collector is already started
+	if c.cancel != nil {
+		return ErrCollectorAlreadyStarted
+	}
+
+	// Create the sampling context
+	samplingCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	// Sample the statistics
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.Sample()
+
+			select {
+			case <-samplingCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops sampling the statistics and waits for the sampling to finish
+func (c *Collector) Stop() {
+	if c == nil {
+		return
+	}
+
+	// Lock the mutex to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Check if the collector is started
+	if c.cancel == nil {
+		return
+	}
+
+	// Stop the sampling
+	c.cancel()
+	<-c.done
+	c.cancel = nil
+	c.done = nil
+}
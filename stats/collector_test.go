@@ -0,0 +1,294 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+	gosql "github.com/ralvarezdev/go-databases/sql"
+)
+
+const (
+	// testDriverName is the name of the fake driver registered for the tests
+	testDriverName = "sqltest"
+)
+
+func init() {
+	sql.Register(testDriverName, sqltest.NewConnector(nil).Driver())
+}
+
+// snapshotNames returns the names of the given snapshots
+func snapshotNames(snapshots []Snapshot) []string {
+	names := make([]string, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		names = append(names, snapshot.Name)
+	}
+	return names
+}
+
+func TestCollectorAddSQLHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		handlerFn func(t *testing.T) gosql.Handler
+		want      []string
+	}{
+		{
+			name: "connected handler",
+			handlerFn: func(t *testing.T) gosql.Handler {
+				handler, err := gosql.NewDefaultHandler(&gosql.Config{DriverName: testDriverName})
+				if err != nil {
+					t.Fatalf("NewDefaultHandler() error = %v", err)
+				}
+				if _, err = handler.Connect(); err != nil {
+					t.Fatalf("Connect() error = %v", err)
+				}
+				t.Cleanup(func() { _ = handler.Disconnect() })
+				return handler
+			},
+			want: []string{"main"},
+		},
+		{
+			name: "disconnected handler",
+			handlerFn: func(t *testing.T) gosql.Handler {
+				handler, err := gosql.NewDefaultHandler(&gosql.Config{DriverName: testDriverName})
+				if err != nil {
+					t.Fatalf("NewDefaultHandler() error = %v", err)
+				}
+				return handler
+			},
+			want: []string{},
+		},
+		{
+			name: "replicated handler",
+			handlerFn: func(t *testing.T) gosql.Handler {
+				handler, err := gosql.NewReplicatedHandler(
+					&gosql.Config{DriverName: testDriverName},
+					[]*gosql.Config{
+						{DriverName: testDriverName},
+						{DriverName: "unknown"},
+						{DriverName: testDriverName},
+					},
+					gosql.RoundRobin,
+				)
+				if err != nil {
+					t.Fatalf("NewReplicatedHandler() error = %v", err)
+				}
+				if _, err = handler.Connect(); err != nil {
+					t.Fatalf("Connect() error = %v", err)
+				}
+				t.Cleanup(func() { _ = handler.Disconnect() })
+				return handler
+			},
+			want: []string{
+				"main",
+				fmt.Sprintf(ReplicaNameFormat, "main", 0),
+				fmt.Sprintf(ReplicaNameFormat, "main", 2),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				collector := NewCollector(0)
+				if err := collector.AddSQLHandler("main", tt.handlerFn(t)); err != nil {
+					t.Fatalf("AddSQLHandler() error = %v", err)
+				}
+
+				collector.Sample()
+				if got := snapshotNames(collector.Snapshots()); !slices.Equal(got, tt.want) {
+					t.Errorf("Snapshots() names = %v, want %v", got, tt.want)
+				}
+				for _, name := range tt.want {
+					if snapshot, ok := collector.Snapshot(name); !ok || snapshot.Kind != KindSQL {
+						t.Errorf("Snapshot(%q) = %+v, %v, want a SQL snapshot", name, snapshot, ok)
+					}
+				}
+
+				// Check that the replicas are removed along with the handler
+				collector.Remove("main")
+				if got := collector.Snapshots(); len(got) != 0 {
+					t.Errorf("Snapshots() after Remove() = %v, want none", snapshotNames(got))
+				}
+			},
+		)
+	}
+}
+
+func TestCollectorAdd(t *testing.T) {
+	collector := NewCollector(0)
+	if err := collector.Add(
+		"main",
+		func() (Snapshot, error) {
+			return Snapshot{Name: "main"}, nil
+		},
+	); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		poolName string
+		sampleFn SampleFn
+		errIs    error
+	}{
+		{
+			name:     "empty name",
+			sampleFn: func() (Snapshot, error) { return Snapshot{}, nil },
+			errIs:    ErrEmptyName,
+		},
+		{
+			name:     "already added",
+			poolName: "main",
+			sampleFn: func() (Snapshot, error) { return Snapshot{}, nil },
+		},
+		{
+			name:     "nil sample function",
+			poolName: "replica",
+			errIs:    ErrNilSampleFn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := collector.Add(tt.poolName, tt.sampleFn)
+				if err == nil {
+					t.Fatalf("Add() error = nil, want error")
+				}
+				if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+					t.Errorf("Add() error = %v, want %v", err, tt.errIs)
+				}
+			},
+		)
+	}
+
+	// Check that a nil collector reports its error
+	var nilCollector *Collector
+	if err := nilCollector.Add("main", nil); !errors.Is(err, ErrNilCollector) {
+		t.Errorf("Add() on a nil collector error = %v, want %v", err, ErrNilCollector)
+	}
+}
+
+func TestCollectorSampleOutsideTheLock(t *testing.T) {
+	collector := NewCollector(0)
+	errSample := errors.New("sample failed")
+
+	// Add a pool that reads the collector while it is sampled, which would deadlock under the lock
+	if err := collector.Add(
+		"reader",
+		func() (Snapshot, error) {
+			_ = collector.Snapshots()
+			return Snapshot{Name: "reader"}, nil
+		},
+	); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// Add a pool that removes itself while it is sampled
+	if err := collector.Add(
+		"removed",
+		func() (Snapshot, error) {
+			collector.Remove("removed")
+			return Snapshot{Name: "removed"}, nil
+		},
+	); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// Add a pool that fails to be sampled
+	if err := collector.Add(
+		"failed",
+		func() (Snapshot, error) {
+			return Snapshot{}, errSample
+		},
+	); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	collector.Sample()
+	want := []string{"reader"}
+	if got := snapshotNames(collector.Snapshots()); !slices.Equal(got, want) {
+		t.Errorf("Snapshots() names = %v, want %v", got, want)
+	}
+	if err := collector.Err("failed"); !errors.Is(err, errSample) {
+		t.Errorf("Err() = %v, want %v", err, errSample)
+	}
+}
+
+func TestCollectorSampleErrors(t *testing.T) {
+	errSample := errors.New("sample failed")
+	collector := NewCollector(0)
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	// Add a pool whose sampling fails while the flag is set
+	var failing bool
+	if err := collector.Add(
+		"main",
+		func() (Snapshot, error) {
+			if failing {
+				return Snapshot{}, errSample
+			}
+			return Snapshot{Name: "main", Kind: KindSQL}, nil
+		},
+	); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		failing      bool
+		wantErr      error
+		wantSnapshot bool
+		wantUp       float64
+	}{
+		{name: "sampled", wantSnapshot: true, wantUp: 1},
+		{name: "failed", failing: true, wantErr: errSample, wantUp: 0},
+		{name: "sampled again", wantSnapshot: true, wantUp: 1},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				failing = tt.failing
+				collector.Sample()
+
+				if err := collector.Err("main"); !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+					t.Errorf("Err() = %v, want %v", err, tt.wantErr)
+				}
+				if _, ok := collector.Snapshot("main"); ok != tt.wantSnapshot {
+					t.Errorf("Snapshot() found = %v, want %v", ok, tt.wantSnapshot)
+				}
+
+				// Check the up metric of the pool
+				families, err := registry.Gather()
+				if err != nil {
+					t.Fatalf("Gather() error = %v", err)
+				}
+				var up []*dto.Metric
+				for _, family := range families {
+					if family.GetName() == "db_pool_up" {
+						up = family.GetMetric()
+					}
+				}
+				if len(up) != 1 || up[0].GetGauge().GetValue() != tt.wantUp {
+					t.Errorf("db_pool_up = %v, want %v", up, tt.wantUp)
+				}
+			},
+		)
+	}
+
+	// Check that the error is removed along with the pool
+	failing = true
+	collector.Sample()
+	collector.Remove("main")
+	if err := collector.Err("main"); err != nil {
+		t.Errorf("Err() after Remove() = %v, want nil", err)
+	}
+}
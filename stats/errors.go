@@ -0,0 +1,16 @@
+package stats
+
+import (
+	"errors"
+)
+
+const (
+	ErrAlreadyAdded = "'%s' is already added"
+)
+
+var (
+	ErrNilCollector            = errors.New("stats collector cannot be nil")
+	ErrEmptyName               = errors.New("name cannot be empty")
+	ErrNilSampleFn             = errors.New("sample function cannot be nil")
+	ErrCollectorAlreadyStarted = errors.New("stats collector already started")
+)
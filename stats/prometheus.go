@@ -0,0 +1,144 @@
+package stats
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// PrometheusNamespace is the namespace of the Prometheus metrics
+	PrometheusNamespace = "db"
+
+	// PrometheusSubsystem is the subsystem of the Prometheus metrics
+	PrometheusSubsystem = "pool"
+)
+
+var (
+	// prometheusLabels are the labels of the Prometheus metrics
+	prometheusLabels = []string{"name", "kind"}
+
+	// upDesc is the description of the Prometheus metric that reports whether the last sample of a source succeeded
+	upDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(PrometheusNamespace, PrometheusSubsystem, "up"),
+		"Whether the last sample of the pool succeeded, 1 if it did and 0 otherwise.",
+		[]string{"name"},
+		nil,
+	)
+
+	// Prometheus metric descriptions
+	maxOpenConnectionsDesc = newPrometheusDesc(
+		"max_open_connections",
+		"The maximum number of open connections allowed.",
+	)
+	openConnectionsDesc = newPrometheusDesc(
+		"open_connections",
+		"The number of established connections, both in use and idle.",
+	)
+	inUseConnectionsDesc = newPrometheusDesc(
+		"in_use_connections",
+		"The number of connections currently in use.",
+	)
+	idleConnectionsDesc = newPrometheusDesc(
+		"idle_connections",
+		"The number of idle connections.",
+	)
+	waitCountDesc = newPrometheusDesc(
+		"wait_count_total",
+		"The total number of connections waited for.",
+	)
+	waitDurationDesc = newPrometheusDesc(
+		"wait_duration_seconds_total",
+		"The total time blocked waiting for a new connection.",
+	)
+	maxIdleClosedDesc = newPrometheusDesc(
+		"max_idle_closed_total",
+		"The total number of connections closed due to the maximum idle connections.",
+	)
+	maxIdleTimeClosedDesc = newPrometheusDesc(
+		"max_idle_time_closed_total",
+		"The total number of connections closed due to the maximum idle time.",
+	)
+	maxLifetimeClosedDesc = newPrometheusDesc(
+		"max_lifetime_closed_total",
+		"The total number of connections closed due to the maximum lifetime.",
+	)
+)
+
+// newPrometheusDesc creates a new Prometheus metric description
+//
+// Parameters:
+//
+//   - name: the metric name, without the namespace and subsystem
+//   - help: the metric help
+//
+// Returns:
+//
+//   - *prometheus.Desc: the metric description
+func newPrometheusDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		prometheus.BuildFQName(PrometheusNamespace, PrometheusSubsystem, name),
+		help,
+		prometheusLabels,
+		nil,
+	)
+}
+
+// Describe sends the Prometheus metric descriptions, implementing prometheus.Collector
+//
+// Parameters:
+//
+//   - ch: the channel to send the descriptions to
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- maxOpenConnectionsDesc
+	ch <- openConnectionsDesc
+	ch <- inUseConnectionsDesc
+	ch <- idleConnectionsDesc
+	ch <- waitCountDesc
+	ch <- waitDurationDesc
+	ch <- maxIdleClosedDesc
+	ch <- maxIdleTimeClosedDesc
+	ch <- maxLifetimeClosedDesc
+	ch <- upDesc
+}
+
+// Collect sends the Prometheus metrics of the last snapshots, and whether the last sample of each source
+// succeeded, implementing prometheus.Collector
+//
+// Parameters:
+//
+//   - ch: the channel to send the metrics to
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, snapshot := range c.Snapshots() {
+		labels := []string{snapshot.Name, snapshot.Kind}
+		for _, metric := range []struct {
+			desc      *prometheus.Desc
+			valueType prometheus.ValueType
+			value     float64
+		}{
+			{maxOpenConnectionsDesc, prometheus.GaugeValue, float64(snapshot.MaxOpenConnections)},
+			{openConnectionsDesc, prometheus.GaugeValue, float64(snapshot.OpenConnections)},
+			{inUseConnectionsDesc, prometheus.GaugeValue, float64(snapshot.InUse)},
+			{idleConnectionsDesc, prometheus.GaugeValue, float64(snapshot.Idle)},
+			{waitCountDesc, prometheus.CounterValue, float64(snapshot.WaitCount)},
+			{waitDurationDesc, prometheus.CounterValue, snapshot.WaitDuration.Seconds()},
+			{maxIdleClosedDesc, prometheus.CounterValue, float64(snapshot.MaxIdleClosed)},
+			{maxIdleTimeClosedDesc, prometheus.CounterValue, float64(snapshot.MaxIdleTimeClosed)},
+			{maxLifetimeClosedDesc, prometheus.CounterValue, float64(snapshot.MaxLifetimeClosed)},
+		} {
+			ch <- prometheus.MustNewConstMetric(
+				metric.desc,
+				metric.valueType,
+				metric.value,
+				labels...,
+			)
+		}
+	}
+
+	// Report whether the last sample of each source succeeded
+	for name, up := range c.sampledSources() {
+		value := 0.0
+		if up {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, value, name)
+	}
+}
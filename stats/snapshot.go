@@ -0,0 +1,92 @@
+package stats
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// KindSQL is the kind of the database/sql connection pools
+	KindSQL = "sql"
+
+	// KindPgxPool is the kind of the pgx connection pools
+	KindPgxPool = "pgxpool"
+)
+
+type (
+	// Snapshot is a sample of the statistics of a connection pool
+	//
+	// The wait count and duration, and the closed connection counters are cumulative since the pool was opened.
+	Snapshot struct {
+		Name               string
+		Kind               string
+		MaxOpenConnections int64
+		OpenConnections    int64
+		InUse              int64
+		Idle               int64
+		WaitCount          int64
+		WaitDuration       time.Duration
+		MaxIdleClosed      int64
+		MaxIdleTimeClosed  int64
+		MaxLifetimeClosed  int64
+		SampledAt          time.Time
+	}
+)
+
+// NewSQLSnapshot creates a new snapshot from the statistics of a database/sql connection pool
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - stats: the database/sql statistics
+//
+// Returns:
+//
+//   - Snapshot: the snapshot
+func NewSQLSnapshot(name string, stats sql.DBStats) Snapshot {
+	return Snapshot{
+		Name:               name,
+		Kind:               KindSQL,
+		MaxOpenConnections: int64(stats.MaxOpenConnections),
+		OpenConnections:    int64(stats.OpenConnections),
+		InUse:              int64(stats.InUse),
+		Idle:               int64(stats.Idle),
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		SampledAt:          time.Now(),
+	}
+}
+
+// NewPgxPoolSnapshot creates a new snapshot from the statistics of a pgx connection pool
+//
+// The pgx pools do not distinguish between the idle connections closed by count or by idle time, so both are
+// reported as MaxIdleTimeClosed.
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - stat: the pgx pool statistics
+//
+// Returns:
+//
+//   - Snapshot: the snapshot
+func NewPgxPoolSnapshot(name string, stat *pgxpool.Stat) Snapshot {
+	return Snapshot{
+		Name:               name,
+		Kind:               KindPgxPool,
+		MaxOpenConnections: int64(stat.MaxConns()),
+		OpenConnections:    int64(stat.TotalConns()),
+		InUse:              int64(stat.AcquiredConns()),
+		Idle:               int64(stat.IdleConns()),
+		WaitCount:          stat.EmptyAcquireCount(),
+		WaitDuration:       stat.EmptyAcquireWaitTime(),
+		MaxIdleTimeClosed:  stat.MaxIdleDestroyCount(),
+		MaxLifetimeClosed:  stat.MaxLifetimeDestroyCount(),
+		SampledAt:          time.Now(),
+	}
+}
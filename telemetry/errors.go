@@ -0,0 +1,10 @@
+package telemetry
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilSQLDB   = errors.New("sql db cannot be nil")
+	ErrNilPgxPool = errors.New("pgx pool cannot be nil")
+)
@@ -0,0 +1,180 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type (
+	// poolStats is the common representation of the connection pool statistics
+	poolStats struct {
+		max          int64
+		inUse        int64
+		idle         int64
+		waitCount    int64
+		waitDuration float64
+	}
+
+	// poolStatsFn is the function type used to sample the connection pool statistics
+	poolStatsFn func() poolStats
+)
+
+// RegisterSQLPoolMetrics registers the observable gauges of a database/sql connection pool
+//
+// Parameters:
+//
+//   - name: the pool name, recorded as the pool name attribute
+//   - db: the SQL connection
+//
+// Returns:
+//
+//   - metric.Registration: the registration, to unregister the gauges once the pool is closed
+//   - error: if any error occurs
+func RegisterSQLPoolMetrics(name string, db *sql.DB) (
+	metric.Registration,
+	error,
+) {
+	// Check if the connection is nil
+	if db == nil {
+		return nil, ErrNilSQLDB
+	}
+
+	return registerPoolMetrics(
+		name,
+		func() poolStats {
+			stats := db.Stats()
+			return poolStats{
+				max:          int64(stats.MaxOpenConnections),
+				inUse:        int64(stats.InUse),
+				idle:         int64(stats.Idle),
+				waitCount:    stats.WaitCount,
+				waitDuration: stats.WaitDuration.Seconds(),
+			}
+		},
+	)
+}
+
+// RegisterPgxPoolMetrics registers the observable gauges of a pgx connection pool
+//
+// Parameters:
+//
+//   - name: the pool name, recorded as the pool name attribute
+//   - pool: the pgx pool
+//
+// Returns:
+//
+//   - metric.Registration: the registration, to unregister the gauges once the pool is closed
+//   - error: if any error occurs
+func RegisterPgxPoolMetrics(name string, pool *pgxpool.Pool) (
+	metric.Registration,
+	error,
+) {
+	// Check if the pool is nil
+	if pool == nil {
+		return nil, ErrNilPgxPool
+	}
+
+	return registerPoolMetrics(
+		name,
+		func() poolStats {
+			stats := pool.Stat()
+			return poolStats{
+				max:          int64(stats.MaxConns()),
+				inUse:        int64(stats.AcquiredConns()),
+				idle:         int64(stats.IdleConns()),
+				waitCount:    stats.EmptyAcquireCount(),
+				waitDuration: stats.AcquireDuration().Seconds(),
+			}
+		},
+	)
+}
+
+// registerPoolMetrics registers the observable gauges of a connection pool
+//
+// Parameters:
+//
+//   - name: the pool name
+//   - statsFn: the function used to sample the pool statistics
+//
+// Returns:
+//
+//   - metric.Registration: the registration
+//   - error: if any error occurs
+func registerPoolMetrics(name string, statsFn poolStatsFn) (
+	metric.Registration,
+	error,
+) {
+	meter := Meter()
+
+	// Create the gauges
+	maxGauge, err := meter.Int64ObservableGauge(
+		"db.client.connections.max",
+		metric.WithDescription("The maximum number of open connections allowed"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	usageGauge, err := meter.Int64ObservableGauge(
+		"db.client.connections.usage",
+		metric.WithDescription("The number of connections by state"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	waitCountGauge, err := meter.Int64ObservableGauge(
+		"db.client.connections.wait_count",
+		metric.WithDescription("The total number of connections waited for"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	waitDurationGauge, err := meter.Float64ObservableGauge(
+		"db.client.connections.wait_duration",
+		metric.WithDescription("The total time blocked waiting for a new connection"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Register the callback
+	poolAttr := AttributePoolName.String(name)
+	return meter.RegisterCallback(
+		func(_ context.Context, observer metric.Observer) error {
+			stats := statsFn()
+			observer.ObserveInt64(
+				maxGauge,
+				stats.max,
+				metric.WithAttributes(poolAttr),
+			)
+			observer.ObserveInt64(
+				usageGauge,
+				stats.inUse,
+				metric.WithAttributes(poolAttr, attributeState.String("used")),
+			)
+			observer.ObserveInt64(
+				usageGauge,
+				stats.idle,
+				metric.WithAttributes(poolAttr, attributeState.String("idle")),
+			)
+			observer.ObserveInt64(
+				waitCountGauge,
+				stats.waitCount,
+				metric.WithAttributes(poolAttr),
+			)
+			observer.ObserveFloat64(
+				waitDurationGauge,
+				stats.waitDuration,
+				metric.WithAttributes(poolAttr),
+			)
+			return nil
+		},
+		maxGauge,
+		usageGauge,
+		waitCountGauge,
+		waitDurationGauge,
+	)
+}
@@ -0,0 +1,135 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/ralvarezdev/go-databases/internal/sqltest"
+)
+
+// collectGauges collects the int64 gauges by metric name and state attribute
+func collectGauges(
+	t *testing.T,
+	reader *sdkmetric.ManualReader,
+	pool string,
+) map[string]int64 {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	gauges := make(map[string]int64)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			if !ok {
+				continue
+			}
+			for _, point := range gauge.DataPoints {
+				if name, _ := point.Attributes.Value(AttributePoolName); name.AsString() != pool {
+					continue
+				}
+				key := m.Name
+				if state, ok := point.Attributes.Value(attributeState); ok {
+					key += "." + state.AsString()
+				}
+				gauges[key] = point.Value
+			}
+		}
+	}
+	return gauges
+}
+
+func TestRegisterSQLPoolMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	SetProviders(nil, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { SetProviders(nil, nil) })
+
+	db, _ := sqltest.Open(nil)
+	defer db.Close()
+	db.SetMaxOpenConns(4)
+
+	// Hold a connection so it is reported as used
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	defer conn.Close()
+
+	registration, err := RegisterSQLPoolMetrics("main", db)
+	if err != nil {
+		t.Fatalf("RegisterSQLPoolMetrics() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		metric string
+		want   int64
+	}{
+		{name: "max connections", metric: "db.client.connections.max", want: 4},
+		{name: "used connections", metric: "db.client.connections.usage.used", want: 1},
+		{name: "idle connections", metric: "db.client.connections.usage.idle", want: 0},
+		{name: "wait count", metric: "db.client.connections.wait_count", want: 0},
+	}
+	gauges := collectGauges(t, reader, "main")
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, ok := gauges[tt.metric]
+				if !ok {
+					t.Fatalf("gauge %s was not reported", tt.metric)
+				}
+				if got != tt.want {
+					t.Errorf("gauge %s = %d, want %d", tt.metric, got, tt.want)
+				}
+			},
+		)
+	}
+
+	// Check that the gauges are no longer reported once unregistered
+	if err = registration.Unregister(); err != nil {
+		t.Fatalf("Unregister() error = %v", err)
+	}
+	if gauges = collectGauges(t, reader, "main"); len(gauges) != 0 {
+		t.Errorf("gauges after Unregister() = %v, want none", gauges)
+	}
+}
+
+func TestRegisterPoolMetricsNilPool(t *testing.T) {
+	tests := []struct {
+		name       string
+		registerFn func() error
+		wantErr    error
+	}{
+		{
+			name: "nil sql db",
+			registerFn: func() error {
+				_, err := RegisterSQLPoolMetrics("main", nil)
+				return err
+			},
+			wantErr: ErrNilSQLDB,
+		},
+		{
+			name: "nil pgx pool",
+			registerFn: func() error {
+				_, err := RegisterPgxPoolMetrics("main", nil)
+				return err
+			},
+			wantErr: ErrNilPgxPool,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if err := tt.registerFn(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
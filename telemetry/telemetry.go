@@ -0,0 +1,369 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// InstrumentationName is the name of the tracer and the meter used by this module
+	InstrumentationName = "github.com/ralvarezdev/go-databases"
+)
+
+const (
+	// AttributeDBSystem is the attribute key for the database system
+	AttributeDBSystem = attribute.Key("db.system")
+
+	// AttributeDBStatement is the attribute key for the database statement
+	AttributeDBStatement = attribute.Key("db.statement")
+
+	// AttributeDBOperation is the attribute key for the database operation
+	AttributeDBOperation = attribute.Key("db.operation")
+
+	// AttributeDBCollection is the attribute key for the MongoDB collection
+	AttributeDBCollection = attribute.Key("db.mongodb.collection")
+
+	// AttributeDBTable is the attribute key for the SQL table
+	AttributeDBTable = attribute.Key("db.sql.table")
+
+	// AttributeTransactionOutcome is the attribute key for the transaction outcome
+	AttributeTransactionOutcome = attribute.Key("db.transaction.outcome")
+
+	// AttributeTransactionNested is the attribute key for the flag of the nested transactions
+	AttributeTransactionNested = attribute.Key("db.transaction.nested")
+
+	// AttributePoolName is the attribute key for the connection pool name
+	AttributePoolName = attribute.Key("db.client.connections.pool.name")
+
+	// attributeState is the attribute key for the connection state
+	attributeState = attribute.Key("state")
+)
+
+const (
+	// SystemPostgreSQL is the database system of PostgreSQL
+	SystemPostgreSQL = "postgresql"
+
+	// SystemMySQL is the database system of MySQL
+	SystemMySQL = "mysql"
+
+	// SystemSQLite is the database system of SQLite
+	SystemSQLite = "sqlite"
+
+	// SystemMSSQL is the database system of Microsoft SQL Server
+	SystemMSSQL = "mssql"
+
+	// SystemMongoDB is the database system of MongoDB
+	SystemMongoDB = "mongodb"
+
+	// SystemOtherSQL is the database system of the unknown SQL databases
+	SystemOtherSQL = "other_sql"
+)
+
+const (
+	// OutcomeCommit is the outcome of the committed transactions
+	OutcomeCommit = "commit"
+
+	// OutcomeRollback is the outcome of the rolled back transactions
+	OutcomeRollback = "rollback"
+
+	// OutcomeRetry is the outcome of the transaction attempts rolled back to be retried
+	OutcomeRetry = "retry"
+)
+
+const (
+	// OperationTransaction is the operation name of the transactions
+	OperationTransaction = "transaction"
+
+	// OperationBatch is the operation name of the pgx batches
+	OperationBatch = "batch"
+
+	// OperationCopy is the operation name of the pgx COPY FROM bulk loads
+	OperationCopy = "copy"
+
+	// OperationCreateCollection is the operation name of the MongoDB collection creation
+	OperationCreateCollection = "create_collection"
+)
+
+type (
+	// instruments holds the configured providers and the instruments created from them
+	instruments struct {
+		tracer       trace.Tracer
+		meter        metric.Meter
+		transactions metric.Int64Counter
+	}
+)
+
+var (
+	// current is the current instruments
+	current = newInstruments(
+		tracenoop.NewTracerProvider(),
+		metricnoop.NewMeterProvider(),
+	)
+
+	// currentMutex guards the current instruments
+	currentMutex sync.RWMutex
+
+	// sqlSystems is the cache of the database systems by driver type
+	sqlSystems sync.Map
+)
+
+// newInstruments creates the instruments from the given providers
+//
+// Parameters:
+//
+//   - tracerProvider: the tracer provider
+//   - meterProvider: the meter provider
+//
+// Returns:
+//
+//   - *instruments: the instruments
+func newInstruments(
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+) *instruments {
+	meter := meterProvider.Meter(InstrumentationName)
+	transactions, err := meter.Int64Counter(
+		"db.client.transactions",
+		metric.WithDescription("The number of finished transactions by outcome"),
+	)
+	if err != nil {
+		transactions, _ = metricnoop.NewMeterProvider().
+			Meter(InstrumentationName).
+			Int64Counter("db.client.transactions")
+	}
+
+	return &instruments{
+		tracer:       tracerProvider.Tracer(InstrumentationName),
+		meter:        meter,
+		transactions: transactions,
+	}
+}
+
+// getInstruments returns the current instruments
+//
+// Returns:
+//
+//   - *instruments: the current instruments
+func getInstruments() *instruments {
+	currentMutex.RLock()
+	defer currentMutex.RUnlock()
+	return current
+}
+
+// SetProviders sets the tracer and meter providers used to instrument this module
+//
+// The instrumentation is disabled by default. Pass otel.GetTracerProvider() and otel.GetMeterProvider() to use
+// the global providers, or an in-memory span recorder provider in tests. A nil provider disables the
+// corresponding instrumentation.
+//
+// Parameters:
+//
+//   - tracerProvider: the tracer provider
+//   - meterProvider: the meter provider
+func SetProviders(
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+) {
+	if tracerProvider == nil {
+		tracerProvider = tracenoop.NewTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = metricnoop.NewMeterProvider()
+	}
+
+	instruments := newInstruments(tracerProvider, meterProvider)
+
+	currentMutex.Lock()
+	defer currentMutex.Unlock()
+	current = instruments
+}
+
+// Meter returns the meter used to instrument this module
+//
+// Returns:
+//
+//   - metric.Meter: the meter
+func Meter() metric.Meter {
+	return getInstruments().meter
+}
+
+// SQLSystem returns the database system of the given database/sql driver, based on its package path
+//
+// Parameters:
+//
+//   - drv: the driver
+//
+// Returns:
+//
+//   - string: the database system, or SystemOtherSQL if it is unknown
+func SQLSystem(drv driver.Driver) string {
+	if drv == nil {
+		return SystemOtherSQL
+	}
+
+	// Check if the system is already cached
+	driverType := reflect.TypeOf(drv)
+	if cached, ok := sqlSystems.Load(driverType); ok {
+		system, _ := cached.(string)
+		return system
+	}
+
+	// Get the system from the driver package path
+	if driverType.Kind() == reflect.Pointer {
+		driverType = driverType.Elem()
+	}
+	pkgPath := strings.ToLower(driverType.PkgPath())
+	system := SystemOtherSQL
+	switch {
+	case strings.Contains(pkgPath, "pgx"),
+		strings.Contains(pkgPath, "lib/pq"),
+		strings.Contains(pkgPath, "postgres"):
+		system = SystemPostgreSQL
+	case strings.Contains(pkgPath, "mysql"):
+		system = SystemMySQL
+	case strings.Contains(pkgPath, "sqlite"):
+		system = SystemSQLite
+	case strings.Contains(pkgPath, "mssql"),
+		strings.Contains(pkgPath, "sqlserver"):
+		system = SystemMSSQL
+	}
+
+	sqlSystems.Store(reflect.TypeOf(drv), system)
+	return system
+}
+
+// OperationName returns the operation name of the given statement, which is its first keyword
+//
+// Parameters:
+//
+//   - statement: the statement
+//
+// Returns:
+//
+//   - string: the operation name in upper case, such as SELECT, or an empty string if the statement is empty
+func OperationName(statement string) string {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// StartSpan starts a client span for a database operation
+//
+// Parameters:
+//
+//   - ctx: the parent context
+//   - system: the database system
+//   - operation: the operation name, such as SELECT or transaction
+//   - statement: the statement, it can be empty
+//   - attrs: the additional attributes
+//
+// Returns:
+//
+//   - context.Context: the context carrying the span
+//   - trace.Span: the span
+func StartSpan(
+	ctx context.Context,
+	system,
+	operation,
+	statement string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	spanAttrs := make([]attribute.KeyValue, 0, len(attrs)+3)
+	spanAttrs = append(
+		spanAttrs,
+		AttributeDBSystem.String(system),
+		AttributeDBOperation.String(operation),
+	)
+	if statement != "" {
+		spanAttrs = append(spanAttrs, AttributeDBStatement.String(statement))
+	}
+	spanAttrs = append(spanAttrs, attrs...)
+
+	return getInstruments().tracer.Start(
+		ctx,
+		system+" "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(spanAttrs...),
+	)
+}
+
+// EndSpan ends the span, recording the error if any
+//
+// Parameters:
+//
+//   - span: the span to end
+//   - err: the error returned by the operation, it can be nil
+//   - options: the span end options, such as trace.WithTimestamp to end the span at a given time
+func EndSpan(span trace.Span, err error, options ...trace.SpanEndOption) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(options...)
+}
+
+// RecordTransactionOutcome records the outcome of a transaction in the transactions counter
+//
+// The retried attempts are also recorded as an event of the span carried by the context.
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - system: the database system
+//   - outcome: the transaction outcome, such as OutcomeCommit
+func RecordTransactionOutcome(ctx context.Context, system, outcome string) {
+	outcomeAttr := AttributeTransactionOutcome.String(outcome)
+
+	// Record the retry in the span
+	if outcome == OutcomeRetry {
+		trace.SpanFromContext(ctx).AddEvent(
+			"transaction retry",
+			trace.WithAttributes(AttributeDBSystem.String(system)),
+		)
+	}
+
+	// Record the outcome in the counter
+	getInstruments().transactions.Add(
+		ctx,
+		1,
+		metric.WithAttributes(AttributeDBSystem.String(system), outcomeAttr),
+	)
+}
+
+// EndTransactionSpan ends the span of a transaction, recording its outcome and the error if any
+//
+// Parameters:
+//
+//   - ctx: the context to use
+//   - span: the transaction span
+//   - system: the database system
+//   - outcome: the transaction outcome, such as OutcomeCommit
+//   - err: the error returned by the transaction, it can be nil
+func EndTransactionSpan(
+	ctx context.Context,
+	span trace.Span,
+	system,
+	outcome string,
+	err error,
+) {
+	if span != nil {
+		span.SetAttributes(AttributeTransactionOutcome.String(outcome))
+	}
+	RecordTransactionOutcome(ctx, system, outcome)
+	EndSpan(span, err)
+}